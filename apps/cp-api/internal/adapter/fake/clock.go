@@ -0,0 +1,37 @@
+// Package fake: adapter deterministik untuk test (waktu, ID, token).
+package fake
+
+import (
+	"sync"
+	"time"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// Clock: waktu hanya berubah lewat Set/Advance.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+var _ contract.Clock = (*Clock)(nil)
+
+func NewClock(now time.Time) *Clock { return &Clock{now: now.UTC()} }
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now.UTC()
+}
+
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
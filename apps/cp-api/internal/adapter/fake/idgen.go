@@ -0,0 +1,28 @@
+package fake
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// IDGen: UUID berurutan 00000000-0000-4000-8000-000000000001, ...002, dst.
+type IDGen struct {
+	n atomic.Uint64
+}
+
+var _ contract.IDGen = (*IDGen)(nil)
+
+func (g *IDGen) New() uuid.UUID { return SeqID(g.n.Add(1)) }
+
+// SeqID: UUID ke-n yang dihasilkan IDGen.
+func SeqID(n uint64) uuid.UUID {
+	var id uuid.UUID
+	id[6] = 0x40 // version 4
+	binary.BigEndian.PutUint64(id[8:], n)
+	id[8] |= 0x80 // variant RFC 4122
+	return id
+}
@@ -0,0 +1,63 @@
+package fake
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+var ErrInvalidToken = errors.New("fake: invalid token")
+
+// TokenSigner: token berupa "fake-token-<n>"; claims disimpan di memori
+// supaya test bisa memeriksa apa yang ditandatangani.
+type TokenSigner struct {
+	TTL time.Duration // 0 = 15 menit
+
+	mu     sync.Mutex
+	claims map[string]contract.AccessClaims
+	issued []contract.AccessClaims
+}
+
+var (
+	_ contract.TokenSigner   = (*TokenSigner)(nil)
+	_ contract.TokenVerifier = (*TokenSigner)(nil)
+)
+
+func (s *TokenSigner) Sign(c contract.AccessClaims, now time.Time) (string, error) {
+	ttl := s.TTL
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+	c.IssuedAt = now
+	c.ExpiresAt = now.Add(ttl)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.claims == nil {
+		s.claims = map[string]contract.AccessClaims{}
+	}
+	s.issued = append(s.issued, c)
+	tok := fmt.Sprintf("fake-token-%d", len(s.issued))
+	s.claims[tok] = c
+	return tok, nil
+}
+
+func (s *TokenSigner) Verify(token string, now time.Time) (*contract.AccessClaims, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.claims[token]
+	if !ok || !now.Before(c.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	return &c, nil
+}
+
+// Issued: semua claims yang pernah ditandatangani, urut waktu.
+func (s *TokenSigner) Issued() []contract.AccessClaims {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]contract.AccessClaims(nil), s.issued...)
+}
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// FileEmailSender: menulis setiap email sebagai file .eml di Dir (outbox lokal).
+type FileEmailSender struct {
+	Dir string
+}
+
+var _ contract.EmailSender = FileEmailSender{}
+
+func (s FileEmailSender) SendEmail(_ context.Context, to, subject, body string) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000"), uuid.NewString())
+	msg := fmt.Sprintf("Date: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		now.Format(time.RFC1123Z), to, subject, body)
+	return os.WriteFile(filepath.Join(s.Dir, name), []byte(msg), 0o644)
+}
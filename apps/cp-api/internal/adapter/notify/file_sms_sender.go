@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// FileSMSSender: menulis setiap SMS sebagai file .sms di Dir (outbox lokal),
+// supaya alur login dengan kode SMS bisa dites tanpa provider.
+type FileSMSSender struct {
+	Dir string
+}
+
+var _ contract.SMSSender = FileSMSSender{}
+
+func (s FileSMSSender) SendSMS(_ context.Context, to, body string) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s.sms", now.Format("20060102T150405.000"), uuid.NewString())
+	msg := fmt.Sprintf("Date: %s\nTo: %s\n\n%s\n", now.Format(time.RFC1123Z), to, body)
+	return os.WriteFile(filepath.Join(s.Dir, name), []byte(msg), 0o644)
+}
@@ -0,0 +1,18 @@
+package notify
+
+import (
+	"context"
+	"log"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// LogEmailSender: hanya menulis email ke log (local dev).
+type LogEmailSender struct{}
+
+var _ contract.EmailSender = LogEmailSender{}
+
+func (LogEmailSender) SendEmail(_ context.Context, to, subject, body string) error {
+	log.Printf("[mail] to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
@@ -0,0 +1,18 @@
+package notify
+
+import (
+	"context"
+	"log"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// LogSMSSender: hanya menulis SMS ke log (local dev).
+type LogSMSSender struct{}
+
+var _ contract.SMSSender = LogSMSSender{}
+
+func (LogSMSSender) SendSMS(_ context.Context, to, body string) error {
+	log.Printf("[sms] to=%s\n%s", to, body)
+	return nil
+}
@@ -0,0 +1,138 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2Params: parameter Argon2id. Default mengikuti rekomendasi OWASP
+// (m=19 MiB, t=2, p=1).
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLen     uint32
+	KeyLen      uint32
+}
+
+// Batas parameter yang diterima dari hash tersimpan (dan dari konfigurasi),
+// supaya hash yang dimanipulasi tidak bisa menghabiskan memori / CPU.
+const (
+	MaxArgon2Memory      = 256 * 1024 // KiB
+	MaxArgon2Iterations  = 16
+	MaxArgon2Parallelism = 16
+	maxArgon2SaltLen     = 64
+	maxArgon2KeyLen      = 64
+)
+
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{Memory: 19 * 1024, Iterations: 2, Parallelism: 1, SaltLen: 16, KeyLen: 32}
+}
+
+// Argon2idHasher menyimpan hash dalam format PHC:
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash> (base64 tanpa padding)
+type Argon2idHasher struct {
+	Params Argon2Params
+}
+
+var _ contract.PasswordHasher = Argon2idHasher{}
+
+var errPHC = errors.New("malformed phc string")
+
+func (h Argon2idHasher) params() Argon2Params {
+	p, d := h.Params, DefaultArgon2Params()
+	if p.Memory == 0 {
+		p.Memory = d.Memory
+	}
+	if p.Iterations == 0 {
+		p.Iterations = d.Iterations
+	}
+	if p.Parallelism == 0 {
+		p.Parallelism = d.Parallelism
+	}
+	if p.SaltLen == 0 {
+		p.SaltLen = d.SaltLen
+	}
+	if p.KeyLen == 0 {
+		p.KeyLen = d.KeyLen
+	}
+	return p
+}
+
+func (h Argon2idHasher) Hash(plain string) (string, domain.PasswordAlg, time.Time, error) {
+	p := h.params()
+	salt := make([]byte, p.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", "", time.Time{}, err
+	}
+	key := argon2.IDKey([]byte(plain), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLen)
+	phc := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+	return phc, domain.AlgArgon2id, time.Now().UTC(), nil
+}
+
+func (h Argon2idHasher) Verify(alg domain.PasswordAlg, plain, hash string) bool {
+	if alg != "" && alg != domain.AlgArgon2id {
+		return false
+	}
+	p, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return false
+	}
+	got := argon2.IDKey([]byte(plain), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(got, key) == 1
+}
+
+// NeedsRehash: algoritma lain, atau parameter berbeda dari konfigurasi saat ini
+func (h Argon2idHasher) NeedsRehash(alg domain.PasswordAlg, hash string) bool {
+	if alg != domain.AlgArgon2id {
+		return true
+	}
+	p, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	want := h.params()
+	return p.Memory != want.Memory || p.Iterations != want.Iterations || p.Parallelism != want.Parallelism ||
+		uint32(len(salt)) != want.SaltLen || uint32(len(key)) != want.KeyLen
+}
+
+func parseArgon2id(phc string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+	parts := strings.Split(phc, "$")
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errPHC
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errPHC
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, errPHC
+	}
+	// batasi biaya supaya hash yang dimanipulasi tidak bisa menghabiskan memori
+	if p.Memory < 8 || p.Memory > MaxArgon2Memory || p.Iterations < 1 || p.Iterations > MaxArgon2Iterations ||
+		p.Parallelism < 1 || p.Parallelism > MaxArgon2Parallelism {
+		return p, nil, nil, errPHC
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(salt) > maxArgon2SaltLen {
+		return p, nil, nil, errPHC
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 || len(key) > maxArgon2KeyLen {
+		return p, nil, nil, errPHC
+	}
+	return p, salt, key, nil
+}
@@ -4,21 +4,43 @@ package security
 import (
 	"time"
 	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-type BcryptHasher struct{}
+type BcryptHasher struct {
+	Cost int // 0 = bcrypt.DefaultCost
+}
+
+var _ contract.PasswordHasher = BcryptHasher{}
+
+func (h BcryptHasher) cost() int {
+	if h.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return h.Cost
+}
 
-func (BcryptHasher) Hash(plain string) (string, domain.PasswordAlg, time.Time, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost) // cost 10-12 ok
+func (h BcryptHasher) Hash(plain string) (string, domain.PasswordAlg, time.Time, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(plain), h.cost()) // cost 10-12 ok
 	if err != nil {
 		return "", "", time.Time{}, err
 	}
-	return string(b), domain.PasswordAlg("bcrypt"), time.Now().UTC(), nil
+	return string(b), domain.AlgBcrypt, time.Now().UTC(), nil
 }
 
-// (opsional) verifikasi untuk login
-func (BcryptHasher) Verify(plain, hash string) bool {
+func (BcryptHasher) Verify(alg domain.PasswordAlg, plain, hash string) bool {
+	if alg != "" && alg != domain.AlgBcrypt {
+		return false
+	}
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain)) == nil
 }
+
+func (h BcryptHasher) NeedsRehash(alg domain.PasswordAlg, hash string) bool {
+	if alg != domain.AlgBcrypt {
+		return true
+	}
+	c, err := bcrypt.Cost([]byte(hash))
+	return err != nil || c != h.cost()
+}
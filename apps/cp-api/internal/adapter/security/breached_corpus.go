@@ -0,0 +1,115 @@
+package security
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// BreachedCorpus: daftar password bocor lokal berformat Pwned Passwords
+// ("SHA1HEX:COUNT" per baris, terurut berdasarkan hash). Sama seperti API
+// range k-anonymity, lookup hanya memakai 5 karakter prefix SHA-1 untuk
+// menemukan blok, lalu suffix dicocokkan di blok tsb; file tidak dimuat ke memori.
+type BreachedCorpus struct {
+	f *os.File
+	// offset awal & akhir blok per prefix 20-bit (5 hex)
+	start, end []int64
+	minCount   int
+}
+
+var _ contract.BreachedPasswordChecker = (*BreachedCorpus)(nil)
+
+const corpusPrefixes = 1 << 20
+
+// LoadBreachedCorpus mengindeks file corpus. minCount > 1 mengabaikan hash
+// yang jarang muncul (mengurangi false positive untuk password unik).
+func LoadBreachedCorpus(path string, minCount int) (*BreachedCorpus, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &BreachedCorpus{f: f, start: make([]int64, corpusPrefixes), end: make([]int64, corpusPrefixes), minCount: minCount}
+	if err := c.index(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("breached corpus %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *BreachedCorpus) index() error {
+	r := bufio.NewReaderSize(c.f, 1<<20)
+	var off int64
+	last := -1
+	for lineNo := 1; ; lineNo++ {
+		line, err := r.ReadSlice('\n')
+		if len(line) > 0 {
+			if len(line) < 40 {
+				if len(bytes.TrimSpace(line)) != 0 {
+					return fmt.Errorf("line %d: not a sha1 hash", lineNo)
+				}
+			} else {
+				p, perr := strconv.ParseUint(string(line[:5]), 16, 32)
+				if perr != nil {
+					return fmt.Errorf("line %d: not a sha1 hash", lineNo)
+				}
+				idx := int(p)
+				if idx < last {
+					return fmt.Errorf("line %d: file must be sorted by hash", lineNo)
+				}
+				if idx != last {
+					c.start[idx] = off
+					last = idx
+				}
+				c.end[idx] = off + int64(len(line))
+			}
+			off += int64(len(line))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err == bufio.ErrBufferFull {
+			return fmt.Errorf("line %d: too long", lineNo)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *BreachedCorpus) IsBreached(_ context.Context, plain string) (bool, error) {
+	sum := sha1.Sum([]byte(plain))
+	h := hex.EncodeToString(sum[:])
+	p, _ := strconv.ParseUint(h[:5], 16, 32)
+	start, end := c.start[p], c.end[p]
+	if end <= start {
+		return false, nil
+	}
+
+	block := make([]byte, end-start)
+	if _, err := c.f.ReadAt(block, start); err != nil {
+		return false, err
+	}
+	for _, line := range bytes.Split(block, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) < 40 || !bytes.EqualFold(line[5:40], []byte(h[5:])) {
+			continue
+		}
+		if c.minCount <= 1 {
+			return true, nil
+		}
+		_, cnt, _ := bytes.Cut(line, []byte(":"))
+		n, _ := strconv.Atoi(string(cnt))
+		return n >= c.minCount, nil
+	}
+	return false, nil
+}
+
+func (c *BreachedCorpus) Close() error { return c.f.Close() }
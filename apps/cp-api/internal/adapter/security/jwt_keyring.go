@@ -0,0 +1,190 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// KeyringSigner: signer JWT asimetris (RS256 / EdDSA) dengan header "kid".
+// Satu key aktif untuk sign, semua key di keyring dipakai untuk verify
+// sehingga token lama tetap valid selama masa rotasi.
+type KeyringSigner struct {
+	active *keyringKey
+	keys   map[string]*keyringKey
+	ttl    time.Duration
+}
+
+type keyringKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer // nil = verify-only (key lama yang sedang dipensiunkan)
+	public  crypto.PublicKey
+}
+
+var (
+	_ contract.TokenSigner   = (*KeyringSigner)(nil)
+	_ contract.TokenVerifier = (*KeyringSigner)(nil)
+	_ contract.JWKSProvider  = (*KeyringSigner)(nil)
+)
+
+// LoadKeyring membaca semua file *.pem di dir. Nama file (tanpa .pem) = kid.
+// File boleh berisi private key (PKCS#8 / PKCS#1 RSA) atau public key (PKIX, verify-only).
+// activeKID kosong: dipakai satu-satunya private key yang ada.
+func LoadKeyring(dir, activeKID string, ttl time.Duration) (*KeyringSigner, error) {
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	kr := &KeyringSigner{keys: map[string]*keyringKey{}, ttl: ttl}
+	var privates []*keyringKey
+	for _, f := range files {
+		kid := strings.TrimSuffix(filepath.Base(f), ".pem")
+		k, err := loadPEMKey(f, kid)
+		if err != nil {
+			return nil, fmt.Errorf("jwt keyring: %s: %w", f, err)
+		}
+		kr.keys[kid] = k
+		if k.private != nil {
+			privates = append(privates, k)
+		}
+	}
+
+	switch {
+	case activeKID != "":
+		k, ok := kr.keys[activeKID]
+		if !ok || k.private == nil {
+			return nil, fmt.Errorf("jwt keyring: no private key for active kid %q", activeKID)
+		}
+		kr.active = k
+	case len(privates) == 1:
+		kr.active = privates[0]
+	default:
+		return nil, fmt.Errorf("jwt keyring: %d private keys in %s, set the active kid", len(privates), dir)
+	}
+	return kr, nil
+}
+
+func loadPEMKey(path, kid string) (*keyringKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := &keyringKey{kid: kid}
+	switch v := key.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.private, k.public = jwt.SigningMethodRS256, v, &v.PublicKey
+	case *rsa.PublicKey:
+		k.method, k.public = jwt.SigningMethodRS256, v
+	case ed25519.PrivateKey:
+		k.method, k.private, k.public = jwt.SigningMethodEdDSA, v, v.Public()
+	case ed25519.PublicKey:
+		k.method, k.public = jwt.SigningMethodEdDSA, v
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (want RSA or Ed25519)", key)
+	}
+	if pub, ok := k.public.(*rsa.PublicKey); ok && pub.N.BitLen() < 2048 {
+		return nil, errors.New("RSA key must be at least 2048 bits")
+	}
+	return k, nil
+}
+
+func (s *KeyringSigner) Sign(c contract.AccessClaims, now time.Time) (string, error) {
+	tok := jwt.NewWithClaims(s.active.method, accessClaimsMap(c, now, s.ttl))
+	tok.Header["kid"] = s.active.kid
+	return tok.SignedString(s.active.private)
+}
+
+func (s *KeyringSigner) Verify(token string, now time.Time) (*contract.AccessClaims, error) {
+	claims := jwt.MapClaims{}
+	keyFunc := func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		k, ok := s.keys[kid]
+		if !ok || t.Method.Alg() != k.method.Alg() {
+			return nil, ErrInvalidToken
+		}
+		return k.public, nil
+	}
+	_, err := jwt.ParseWithClaims(token, claims, keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(jwtIssuer),
+		jwt.WithAudience(jwtAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claimsFromMap(claims)
+}
+
+// JWKS: public key semua key di keyring (RFC 7517), urut berdasarkan kid.
+func (s *KeyringSigner) JWKS() dto.JWKS {
+	kids := make([]string, 0, len(s.keys))
+	for kid := range s.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := dto.JWKS{Keys: make([]dto.JWK, 0, len(kids))}
+	for _, kid := range kids {
+		k := s.keys[kid]
+		jwk := dto.JWK{Kid: kid, Alg: k.method.Alg(), Use: "sig"}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = b64url(pub.N.Bytes())
+			jwk.E = b64url(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = b64url(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func b64url(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
@@ -0,0 +1,218 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var keyringNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// writeKey menyimpan key sebagai dir/kid.pem (private PKCS#8 atau public PKIX).
+func writeKey(t *testing.T, dir, kid string, key any) {
+	t.Helper()
+	var (
+		der   []byte
+		err   error
+		block = "PRIVATE KEY"
+	)
+	switch key.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		block = "PUBLIC KEY"
+		der, err = x509.MarshalPKIXPublicKey(key)
+	default:
+		der, err = x509.MarshalPKCS8PrivateKey(key)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: block, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pemBytes, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newEd25519(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, k, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newRSA(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func loadTestKeyring(t *testing.T, dir, active string) *KeyringSigner {
+	t.Helper()
+	kr, err := LoadKeyring(dir, active, time.Minute)
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	return kr
+}
+
+func testClaims() contract.AccessClaims {
+	return contract.AccessClaims{ID: uuid.New(), UserID: uuid.New(), Email: "budi@xeed.test", SessionID: uuid.New()}
+}
+
+func headerKid(t *testing.T, token string) string {
+	t.Helper()
+	tok, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := tok.Header["kid"].(string)
+	return kid
+}
+
+// Rotasi: key baru untuk sign, token dari key lama (kini public-only) tetap valid.
+func TestKeyringRotation(t *testing.T) {
+	old, next := newEd25519(t), newRSA(t, 2048)
+
+	before := t.TempDir()
+	writeKey(t, before, "2025-01", old)
+	oldToken, err := loadTestKeyring(t, before, "").Sign(testClaims(), keyringNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after := t.TempDir()
+	writeKey(t, after, "2025-01", old.Public())
+	writeKey(t, after, "2026-01", next)
+	kr := loadTestKeyring(t, after, "2026-01")
+
+	c := testClaims()
+	token, err := kr.Sign(c, keyringNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := headerKid(t, token); kid != "2026-01" {
+		t.Errorf("kid = %q; want active kid 2026-01", kid)
+	}
+	if got, err := kr.Verify(token, keyringNow); err != nil || got.UserID != c.UserID {
+		t.Errorf("Verify(active) = %+v, %v", got, err)
+	}
+	if _, err := kr.Verify(oldToken, keyringNow); err != nil {
+		t.Errorf("Verify(retired kid) = %v; want ok", err)
+	}
+}
+
+func TestKeyringRejectsForeignTokens(t *testing.T) {
+	rsaKey := newRSA(t, 2048)
+	dir := t.TempDir()
+	writeKey(t, dir, "main", rsaKey)
+	kr := loadTestKeyring(t, dir, "")
+
+	// kid tidak dikenal
+	other := t.TempDir()
+	writeKey(t, other, "other", newEd25519(t))
+	unknownKid, err := loadTestKeyring(t, other, "").Sign(testClaims(), keyringNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// kid benar tapi alg lain (EdDSA untuk kid RSA)
+	swapped := t.TempDir()
+	writeKey(t, swapped, "main", newEd25519(t))
+	wrongAlg, err := loadTestKeyring(t, swapped, "").Sign(testClaims(), keyringNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// alg confusion: HS256 dengan public key sebagai secret HMAC
+	pubDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaimsMap(testClaims(), keyringNow, time.Minute))
+	hs.Header["kid"] = "main"
+	hmacToken, err := hs.SignedString(pubDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"unknown kid":    unknownKid,
+		"mismatched alg": wrongAlg,
+		"HS256":          hmacToken,
+	} {
+		if _, err := kr.Verify(token, keyringNow); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v; want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+func TestLoadKeyringRejects(t *testing.T) {
+	small := t.TempDir()
+	writeKey(t, small, "small", newRSA(t, 1024))
+
+	twoPrivate := t.TempDir()
+	writeKey(t, twoPrivate, "a", newEd25519(t))
+	writeKey(t, twoPrivate, "b", newEd25519(t))
+
+	publicOnly := t.TempDir()
+	writeKey(t, publicOnly, "pub", newEd25519(t).Public())
+
+	cases := []struct {
+		name, dir, active string
+	}{
+		{"RSA under 2048 bits", small, ""},
+		{"two private keys without active kid", twoPrivate, ""},
+		{"active kid missing", twoPrivate, "c"},
+		{"active kid without private key", publicOnly, "pub"},
+	}
+	for _, tc := range cases {
+		if _, err := LoadKeyring(tc.dir, tc.active, time.Minute); err == nil {
+			t.Errorf("%s: LoadKeyring succeeded", tc.name)
+		}
+	}
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	rsaKey, edKey := newRSA(t, 2048), newEd25519(t)
+	dir := t.TempDir()
+	writeKey(t, dir, "b-rsa", rsaKey)
+	writeKey(t, dir, "a-ed", edKey.Public())
+	kr := loadTestKeyring(t, dir, "b-rsa")
+
+	raw, err := json.Marshal(kr.JWKS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		t.Fatalf("JWKS JSON %s: %v", raw, err)
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	want := []map[string]string{
+		{"kty": "OKP", "kid": "a-ed", "alg": "EdDSA", "use": "sig", "crv": "Ed25519", "x": b64(edKey.Public().(ed25519.PublicKey))},
+		{"kty": "RSA", "kid": "b-rsa", "alg": "RS256", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+	}
+	if !reflect.DeepEqual(set.Keys, want) {
+		t.Errorf("JWKS = %s\nwant %v", raw, want)
+	}
+	if len(set.Keys) == 2 && set.Keys[1]["e"] != "AQAB" {
+		t.Errorf("e = %q; want AQAB", set.Keys[1]["e"])
+	}
+}
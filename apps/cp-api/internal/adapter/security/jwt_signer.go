@@ -1,24 +1,139 @@
 package security
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
 	"time"
 
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+const (
+	jwtIssuer   = "cp-api"
+	jwtAudience = "cp-api"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
 type JWTSigner struct {
 	secret []byte
 	ttl    time.Duration
 }
 
+var (
+	_ contract.TokenSigner   = (*JWTSigner)(nil)
+	_ contract.TokenVerifier = (*JWTSigner)(nil)
+	_ contract.JWKSProvider  = (*JWTSigner)(nil)
+)
+
+// MinJWTSecretLen: panjang minimum secret HS256 (256 bit).
+const MinJWTSecretLen = 32
+
+// NewJWTSigner panic kalau secret kosong: golang-jwt tetap menandatangani
+// dengan key HMAC kosong, sehingga siapa pun bisa membuat token yang valid.
 func NewJWTSigner(secret string, ttl time.Duration) *JWTSigner {
+	if secret == "" {
+		panic("NewJWTSigner: secret is empty")
+	}
 	if ttl <= 0 {
 		ttl = 15 * time.Minute
 	}
 	return &JWTSigner{secret: []byte(secret), ttl: ttl}
 }
-func (s *JWTSigner) Sign(userID uuid.UUID, email string, now time.Time) (string, error) {
-	claims := jwt.MapClaims{"sub": userID.String(), "email": email, "iat": now.Unix(), "exp": now.Add(s.ttl).Unix(), "iss": "cp-api", "aud": "cp-api"}
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+func (s *JWTSigner) Sign(c contract.AccessClaims, now time.Time) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaimsMap(c, now, s.ttl)).SignedString(s.secret)
+}
+
+func (s *JWTSigner) Verify(token string, now time.Time) (*contract.AccessClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) { return s.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(jwtIssuer),
+		jwt.WithAudience(jwtAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claimsFromMap(claims)
+}
+
+// JWKS kosong: secret HS256 tidak boleh dipublikasikan
+func (s *JWTSigner) JWKS() dto.JWKS { return dto.JWKS{Keys: []dto.JWK{}} }
+
+func accessClaimsMap(c contract.AccessClaims, now time.Time, ttl time.Duration) jwt.MapClaims {
+	claims := jwt.MapClaims{"jti": c.ID.String(), "sub": c.UserID.String(), "email": c.Email, "sid": c.SessionID.String(), "iat": numericDate(now), "exp": now.Add(ttl).Unix(), "iss": jwtIssuer, "aud": jwtAudience}
+	if len(c.Scopes) > 0 {
+		claims["scope"] = strings.Join(c.Scopes, " ") // RFC 8693: space-delimited
+	}
+	return claims
+}
+
+func claimsFromMap(m jwt.MapClaims) (*contract.AccessClaims, error) {
+	var c contract.AccessClaims
+	var err error
+	if c.ID, err = uuidClaim(m, "jti"); err != nil {
+		return nil, err
+	}
+	if c.UserID, err = uuidClaim(m, "sub"); err != nil {
+		return nil, err
+	}
+	if c.SessionID, err = uuidClaim(m, "sid"); err != nil {
+		return nil, err
+	}
+	c.Email, _ = m["email"].(string)
+	if scope, _ := m["scope"].(string); scope != "" {
+		c.Scopes = strings.Fields(scope)
+	}
+	iat, ok := issuedAt(m)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	exp, err := m.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, ErrInvalidToken
+	}
+	c.IssuedAt, c.ExpiresAt = iat, exp.UTC()
+	return &c, nil
+}
+
+func uuidClaim(m jwt.MapClaims, key string) (uuid.UUID, error) {
+	s, _ := m[key].(string)
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+	return id, nil
+}
+
+// numericDate: iat dengan presisi mikrodetik (RFC 7519 mengizinkan pecahan)
+// supaya token yang diterbitkan sesaat setelah RevokeUser tidak ikut tercabut.
+func numericDate(t time.Time) float64 {
+	return float64(t.UnixMicro()) / 1e6
+}
+
+// issuedAt membaca iat tanpa dibulatkan ke detik (jwt.NumericDate memotong
+// ke jwt.TimePrecision).
+func issuedAt(m jwt.MapClaims) (time.Time, bool) {
+	var f float64
+	switch v := m["iat"].(type) {
+	case float64:
+		f = v
+	case json.Number:
+		var err error
+		if f, err = v.Float64(); err != nil {
+			return time.Time{}, false
+		}
+	default:
+		return time.Time{}, false
+	}
+	return time.UnixMicro(int64(math.Round(f * 1e6))).UTC(), true
 }
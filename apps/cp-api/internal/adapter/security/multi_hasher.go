@@ -0,0 +1,56 @@
+package security
+
+import (
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// MultiHasher: hash baru selalu memakai algoritma Primary; verifikasi
+// memilih hasher berdasarkan User.PasswordAlg sehingga hash lama (bcrypt,
+// scrypt, parameter Argon2id lama) tetap bisa login lalu di-upgrade.
+type MultiHasher struct {
+	primary    domain.PasswordAlg
+	algorithms map[domain.PasswordAlg]contract.PasswordHasher
+}
+
+var _ contract.PasswordHasher = (*MultiHasher)(nil)
+
+// NewMultiHasher: primary harus ada di daftar hasher.
+func NewMultiHasher(primary domain.PasswordAlg, hashers map[domain.PasswordAlg]contract.PasswordHasher) *MultiHasher {
+	if _, ok := hashers[primary]; !ok {
+		panic("NewMultiHasher: no hasher for primary algorithm " + string(primary))
+	}
+	return &MultiHasher{primary: primary, algorithms: hashers}
+}
+
+func (m *MultiHasher) Hash(plain string) (string, domain.PasswordAlg, time.Time, error) {
+	return m.algorithms[m.primary].Hash(plain)
+}
+
+// Verify: alg kosong = algoritma dideteksi dari format hash.
+func (m *MultiHasher) Verify(alg domain.PasswordAlg, plain, hash string) bool {
+	if alg == "" {
+		alg = detectAlg(hash)
+	}
+	h, ok := m.algorithms[alg]
+	return ok && h.Verify(alg, plain, hash)
+}
+
+func (m *MultiHasher) NeedsRehash(alg domain.PasswordAlg, hash string) bool {
+	return m.algorithms[m.primary].NeedsRehash(alg, hash)
+}
+
+func detectAlg(hash string) domain.PasswordAlg {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return domain.AlgArgon2id
+	case strings.HasPrefix(hash, "$scrypt$"):
+		return domain.AlgScrypt
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return domain.AlgBcrypt
+	}
+	return ""
+}
@@ -0,0 +1,29 @@
+package security
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// NumericCodes: kode OTP numerik acak (default 6 digit) untuk email/SMS.
+type NumericCodes struct {
+	Digits int
+}
+
+var _ contract.OneTimeCodeGen = NumericCodes{}
+
+func (g NumericCodes) Generate() (string, error) {
+	d := g.Digits
+	if d <= 0 {
+		d = 6
+	}
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", d, n), nil
+}
@@ -0,0 +1,30 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// OpaqueTokens: token random 256-bit (base64url), disimpan sebagai SHA-256 hex.
+// Entropinya cukup tinggi sehingga hash cepat tanpa salt sudah aman untuk lookup.
+type OpaqueTokens struct{}
+
+var _ contract.OpaqueTokenGen = OpaqueTokens{}
+
+func (o OpaqueTokens) New() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	plain := base64.RawURLEncoding.EncodeToString(b)
+	return plain, o.Hash(plain), nil
+}
+
+func (OpaqueTokens) Hash(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,162 @@
+package security
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPlain = "correct horse 42"
+
+// parameter kecil supaya test cepat; format & batasnya tetap sama
+var (
+	testArgon2  = Argon2idHasher{Params: Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}}
+	testScrypt  = ScryptHasher{LogN: 4}
+	testBcrypt  = BcryptHasher{Cost: bcrypt.MinCost}
+	testHashers = map[domain.PasswordAlg]contract.PasswordHasher{
+		domain.AlgArgon2id: testArgon2,
+		domain.AlgScrypt:   testScrypt,
+		domain.AlgBcrypt:   testBcrypt,
+	}
+)
+
+func mustHash(t *testing.T, h contract.PasswordHasher) (string, domain.PasswordAlg) {
+	t.Helper()
+	hash, alg, _, err := h.Hash(testPlain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash, alg
+}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	hash, alg := mustHash(t, testArgon2)
+	if alg != domain.AlgArgon2id || !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("Hash = %q, %s", hash, alg)
+	}
+	if !testArgon2.Verify(alg, testPlain, hash) || !testArgon2.Verify("", testPlain, hash) {
+		t.Error("Verify(correct) = false")
+	}
+	if testArgon2.Verify(alg, "wrong password", hash) {
+		t.Error("Verify(wrong) = true")
+	}
+	if testArgon2.Verify(domain.AlgBcrypt, testPlain, hash) {
+		t.Error("Verify(other alg) = true")
+	}
+}
+
+// Hash yang dimanipulasi dengan parameter di luar batas ditolak tanpa dihitung.
+func TestArgon2idRejectsOutOfBoundParams(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString(make([]byte, 16))
+	key := base64.RawStdEncoding.EncodeToString(make([]byte, 32))
+	for _, params := range []string{
+		fmt.Sprintf("m=%d,t=1,p=1", MaxArgon2Memory+1),
+		"m=4,t=1,p=1",
+		fmt.Sprintf("m=64,t=%d,p=1", MaxArgon2Iterations+1),
+		"m=64,t=0,p=1",
+		fmt.Sprintf("m=64,t=1,p=%d", MaxArgon2Parallelism+1),
+		"m=64,t=1,p=0",
+	} {
+		phc := "$argon2id$v=19$" + params + "$" + salt + "$" + key
+		if _, _, _, err := parseArgon2id(phc); err == nil {
+			t.Errorf("parseArgon2id(%s) accepted", params)
+		}
+		if testArgon2.Verify(domain.AlgArgon2id, testPlain, phc) {
+			t.Errorf("Verify(%s) = true", params)
+		}
+	}
+	for _, phc := range []string{
+		"",
+		"$argon2i$v=19$m=64,t=1,p=1$" + salt + "$" + key,
+		"$argon2id$v=16$m=64,t=1,p=1$" + salt + "$" + key,
+		"$argon2id$v=19$m=64,t=1,p=1$" + salt + "$",
+	} {
+		if _, _, _, err := parseArgon2id(phc); err == nil {
+			t.Errorf("parseArgon2id(%q) accepted", phc)
+		}
+	}
+}
+
+// Vektor RFC 7914 §12: scrypt("password", "NaCl", N=1024, r=8, p=16, 64).
+func TestScryptVerify(t *testing.T) {
+	key, _ := hex.DecodeString("fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+		"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640")
+	phc := "$scrypt$ln=10,r=8,p=16$" + base64.RawStdEncoding.EncodeToString([]byte("NaCl")) +
+		"$" + base64.RawStdEncoding.EncodeToString(key)
+	if !testScrypt.Verify(domain.AlgScrypt, "password", phc) {
+		t.Error("Verify(RFC 7914 vector) = false")
+	}
+	if testScrypt.Verify(domain.AlgScrypt, "Password", phc) {
+		t.Error("Verify(wrong) = true")
+	}
+
+	hash, alg := mustHash(t, testScrypt)
+	if alg != domain.AlgScrypt || !testScrypt.Verify("", testPlain, hash) {
+		t.Errorf("round trip failed for %q", hash)
+	}
+}
+
+func TestMultiHasherDispatch(t *testing.T) {
+	m := NewMultiHasher(domain.AlgArgon2id, testHashers)
+	for _, h := range testHashers {
+		hash, alg := mustHash(t, h)
+		if !m.Verify(alg, testPlain, hash) {
+			t.Errorf("Verify(%s) = false", alg)
+		}
+		if !m.Verify("", testPlain, hash) {
+			t.Errorf("Verify(%s, detected) = false", alg)
+		}
+		if m.Verify(alg, "wrong password", hash) {
+			t.Errorf("Verify(%s, wrong) = true", alg)
+		}
+	}
+	bcryptHash, _ := mustHash(t, testBcrypt)
+	if m.Verify(domain.AlgScrypt, testPlain, bcryptHash) {
+		t.Error("Verify(bcrypt hash as scrypt) = true")
+	}
+	if m.Verify("", testPlain, "plaintext") {
+		t.Error("Verify(unknown format) = true")
+	}
+	if _, alg := mustHash(t, m); alg != domain.AlgArgon2id {
+		t.Errorf("Hash alg = %s; want primary argon2id", alg)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	m := NewMultiHasher(domain.AlgArgon2id, testHashers)
+	current, _ := mustHash(t, testArgon2)
+	oldArgon, _ := mustHash(t, Argon2idHasher{Params: Argon2Params{Memory: 32, Iterations: 1, Parallelism: 1}})
+	bcryptHash, _ := mustHash(t, testBcrypt)
+	scryptHash, _ := mustHash(t, testScrypt)
+
+	cases := []struct {
+		name string
+		alg  domain.PasswordAlg
+		hash string
+		want bool
+	}{
+		{"current params", domain.AlgArgon2id, current, false},
+		{"old argon2id params", domain.AlgArgon2id, oldArgon, true},
+		{"bcrypt", domain.AlgBcrypt, bcryptHash, true},
+		{"scrypt", domain.AlgScrypt, scryptHash, true},
+		{"malformed", domain.AlgArgon2id, "$argon2id$", true},
+	}
+	for _, tc := range cases {
+		if got := m.NeedsRehash(tc.alg, tc.hash); got != tc.want {
+			t.Errorf("%s: NeedsRehash = %t; want %t", tc.name, got, tc.want)
+		}
+	}
+	if testBcrypt.NeedsRehash(domain.AlgBcrypt, bcryptHash) {
+		t.Error("bcrypt NeedsRehash(same cost) = true")
+	}
+	if !(BcryptHasher{Cost: bcrypt.MinCost + 1}).NeedsRehash(domain.AlgBcrypt, bcryptHash) {
+		t.Error("bcrypt NeedsRehash(other cost) = false")
+	}
+}
@@ -0,0 +1,66 @@
+package security
+
+import (
+	"crypto/rand"
+	"strings"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// RecoveryCodes: kode format "ssss-xxxx-xxxx" dari alfabet tanpa karakter yang
+// mirip (0/o, 1/l/i). Grup pertama adalah selector (disimpan apa adanya untuk
+// mencari baris), 8 karakter sisanya secret (~40 bit) yang di-hash.
+type RecoveryCodes struct{}
+
+const (
+	recoveryAlphabet    = "abcdefghjkmnpqrstuvwxyz23456789"
+	recoverySelectorLen = 4
+	recoverySecretLen   = 8
+)
+
+var _ contract.RecoveryCodeGen = RecoveryCodes{}
+
+// Generate: selector unik dalam satu set supaya lookup selalu ke satu baris.
+func (RecoveryCodes) Generate(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	seen := make(map[string]bool, n)
+	for len(codes) < n {
+		sel, err := randomRecovery(recoverySelectorLen)
+		if err != nil {
+			return nil, err
+		}
+		if seen[sel] {
+			continue
+		}
+		secret, err := randomRecovery(recoverySecretLen)
+		if err != nil {
+			return nil, err
+		}
+		seen[sel] = true
+		codes = append(codes, sel+"-"+secret[:4]+"-"+secret[4:])
+	}
+	return codes, nil
+}
+
+// Split menormalisasi kode (tanpa spasi/strip, lowercase) lalu memisahkan
+// selector dan secret; false kalau panjangnya tidak cocok.
+func (RecoveryCodes) Split(code string) (selector, secret string, ok bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	if len(code) != recoverySelectorLen+recoverySecretLen {
+		return "", "", false
+	}
+	return code[:recoverySelectorLen], code[recoverySelectorLen:], true
+}
+
+func randomRecovery(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, c := range buf {
+		// bias modulo kecil (256 % 31) tidak signifikan untuk kode sekali-pakai
+		buf[i] = recoveryAlphabet[int(c)%len(recoveryAlphabet)]
+	}
+	return string(buf), nil
+}
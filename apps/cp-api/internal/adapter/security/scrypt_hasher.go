@@ -0,0 +1,97 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ScryptHasher menyimpan hash dalam format PHC: $scrypt$ln=15,r=8,p=1$<salt>$<hash>
+// (N = 2^ln). Terutama untuk memverifikasi hash hasil migrasi sistem lama.
+type ScryptHasher struct {
+	LogN uint8 // default 15 (N=32768)
+	R, P int   // default 8, 1
+}
+
+var _ contract.PasswordHasher = ScryptHasher{}
+
+const scryptKeyLen = 32
+
+func (h ScryptHasher) params() (uint8, int, int) {
+	ln, r, p := h.LogN, h.R, h.P
+	if ln == 0 {
+		ln = 15
+	}
+	if r == 0 {
+		r = 8
+	}
+	if p == 0 {
+		p = 1
+	}
+	return ln, r, p
+}
+
+func (h ScryptHasher) Hash(plain string) (string, domain.PasswordAlg, time.Time, error) {
+	ln, r, p := h.params()
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", "", time.Time{}, err
+	}
+	key, err := scrypt.Key([]byte(plain), salt, 1<<ln, r, p, scryptKeyLen)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	phc := fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", ln, r, p,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+	return phc, domain.AlgScrypt, time.Now().UTC(), nil
+}
+
+func (h ScryptHasher) Verify(alg domain.PasswordAlg, plain, hash string) bool {
+	if alg != "" && alg != domain.AlgScrypt {
+		return false
+	}
+	ln, r, p, salt, key, err := parseScrypt(hash)
+	if err != nil {
+		return false
+	}
+	got, err := scrypt.Key([]byte(plain), salt, 1<<ln, r, p, len(key))
+	return err == nil && subtle.ConstantTimeCompare(got, key) == 1
+}
+
+func (h ScryptHasher) NeedsRehash(alg domain.PasswordAlg, hash string) bool {
+	if alg != domain.AlgScrypt {
+		return true
+	}
+	ln, r, p, _, _, err := parseScrypt(hash)
+	wantLN, wantR, wantP := h.params()
+	return err != nil || ln != wantLN || r != wantR || p != wantP
+}
+
+func parseScrypt(phc string) (ln uint8, r, p int, salt, key []byte, err error) {
+	parts := strings.Split(phc, "$")
+	if len(parts) != 5 || parts[1] != "scrypt" {
+		return 0, 0, 0, nil, nil, errPHC
+	}
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &ln, &r, &p); err != nil {
+		return 0, 0, 0, nil, nil, errPHC
+	}
+	// batasi biaya supaya hash yang dimanipulasi tidak bisa menghabiskan memori
+	if ln < 1 || ln > 20 || r < 1 || r > 32 || p < 1 || p > 16 {
+		return 0, 0, 0, nil, nil, errPHC
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[3]); err != nil {
+		return 0, 0, 0, nil, nil, errPHC
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil || len(key) == 0 {
+		return 0, 0, 0, nil, nil, errPHC
+	}
+	return ln, r, p, salt, key, nil
+}
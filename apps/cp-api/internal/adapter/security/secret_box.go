@@ -0,0 +1,49 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// SecretBox: enkripsi AES-256-GCM untuk secret yang harus bisa dibaca ulang
+// (ex: secret TOTP). Format: nonce || ciphertext.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+var _ contract.SecretBox = (*SecretBox)(nil)
+
+func NewSecretBox(key []byte) (*SecretBox, error) {
+	if len(key) != 32 {
+		return nil, errors.New("secret box: key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+func (b *SecretBox) Seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (b *SecretBox) Open(sealed []byte) ([]byte, error) {
+	n := b.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errors.New("secret box: ciphertext too short")
+	}
+	return b.aead.Open(nil, sealed[:n], sealed[n:], nil)
+}
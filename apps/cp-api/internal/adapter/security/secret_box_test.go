@@ -0,0 +1,68 @@
+package security
+
+import (
+	"bytes"
+	"testing"
+)
+
+func newTestBox(t *testing.T, fill byte) *SecretBox {
+	t.Helper()
+	box, err := NewSecretBox(bytes.Repeat([]byte{fill}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return box
+}
+
+func TestSecretBoxRoundTrip(t *testing.T) {
+	box := newTestBox(t, 1)
+	plain := []byte("totp secret 12345678")
+	sealed, err := box.Seal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, plain) {
+		t.Fatal("sealed output contains the plaintext")
+	}
+	got, err := box.Open(sealed)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("Open = %q, %v; want %q", got, err, plain)
+	}
+	// nonce acak: seal kedua berbeda
+	if again, _ := box.Seal(plain); bytes.Equal(again, sealed) {
+		t.Error("Seal returned identical ciphertext twice")
+	}
+}
+
+func TestSecretBoxRejects(t *testing.T) {
+	box := newTestBox(t, 1)
+	sealed, err := box.Seal([]byte("totp secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 0x01
+
+	cases := []struct {
+		name   string
+		box    *SecretBox
+		sealed []byte
+	}{
+		{"wrong key", newTestBox(t, 2), sealed},
+		{"tampered ciphertext", box, tampered},
+		{"truncated", box, sealed[:5]},
+	}
+	for _, tc := range cases {
+		if got, err := tc.box.Open(tc.sealed); err == nil {
+			t.Errorf("%s: Open = %q; want error", tc.name, got)
+		}
+	}
+}
+
+func TestNewSecretBoxKeyLength(t *testing.T) {
+	for _, n := range []int{0, 16, 31, 33} {
+		if _, err := NewSecretBox(make([]byte, n)); err == nil {
+			t.Errorf("NewSecretBox(%d-byte key) accepted", n)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package security
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// TempPasswords: password sementara acak yang selalu memuat huruf kecil, huruf
+// besar, angka dan simbol supaya lolos policy kelas karakter apa pun.
+type TempPasswords struct {
+	Length int // default 20
+}
+
+var _ contract.TempPasswordGen = TempPasswords{}
+
+var tempPasswordClasses = []string{
+	"abcdefghjkmnpqrstuvwxyz",
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"23456789",
+	"!#$%*+-=?@_",
+}
+
+func (g TempPasswords) Generate() (string, error) {
+	n := g.Length
+	if n < len(tempPasswordClasses) {
+		n = 20
+	}
+	var all string
+	for _, c := range tempPasswordClasses {
+		all += c
+	}
+	out := make([]byte, n)
+	for i := range out {
+		set := all
+		if i < len(tempPasswordClasses) {
+			set = tempPasswordClasses[i] // satu dari setiap kelas, lalu diacak
+		}
+		c, err := randIndex(len(set))
+		if err != nil {
+			return "", err
+		}
+		out[i] = set[c]
+	}
+	// Fisher-Yates supaya posisi kelas wajib tidak bisa ditebak
+	for i := len(out) - 1; i > 0; i-- {
+		j, err := randIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out), nil
+}
+
+func randIndex(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
@@ -0,0 +1,96 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+// Secret SHA1 dari RFC 6238 Appendix B ("12345678901234567890").
+var rfc6238Secret = []byte("12345678901234567890")
+
+// Vektor RFC 6238 Appendix B (SHA1). Kode 6 digit = nilai 8 digit mod 10^6.
+var rfc6238Vectors = []struct {
+	unix int64
+	code string // 8 digit dari RFC
+}{
+	{59, "94287082"},
+	{1111111109, "07081804"},
+	{1111111111, "14050471"},
+	{1234567890, "89005924"},
+	{2000000000, "69279037"},
+	{20000000000, "65353130"},
+}
+
+func TestTOTPRFC6238Vectors(t *testing.T) {
+	totp := TOTP{}
+	for _, v := range rfc6238Vectors {
+		want := v.code[2:]
+		if got := hotp(rfc6238Secret, v.unix/totpPeriod); got != want {
+			t.Errorf("hotp(T=%d) = %s; want %s", v.unix, got, want)
+		}
+		step, ok := totp.Verify(rfc6238Secret, want, time.Unix(v.unix, 0))
+		if !ok || step != v.unix/totpPeriod {
+			t.Errorf("Verify(T=%d) = %d, %t; want %d, true", v.unix, step, ok, v.unix/totpPeriod)
+		}
+	}
+}
+
+// Kode diterima dalam jendela skew dan Verify mengembalikan step aslinya,
+// jadi caller bisa menolak replay (step <= step terakhir yang dipakai).
+func TestTOTPSkewWindow(t *testing.T) {
+	const at = 1111111111 // step 37037037
+	code, step := "050471", int64(at/totpPeriod)
+	issued := time.Unix(at, 0)
+
+	cases := []struct {
+		name string
+		totp TOTP
+		now  time.Time
+		ok   bool
+	}{
+		{"same step", TOTP{}, issued, true},
+		{"one step later", TOTP{}, issued.Add(totpPeriod * time.Second), true},
+		{"one step earlier", TOTP{}, issued.Add(-totpPeriod * time.Second), true},
+		{"two steps later", TOTP{}, issued.Add(2 * totpPeriod * time.Second), false},
+		{"two steps later, skew 2", TOTP{Skew: 2}, issued.Add(2 * totpPeriod * time.Second), true},
+		{"three steps later, skew 2", TOTP{Skew: 2}, issued.Add(3 * totpPeriod * time.Second), false},
+	}
+	for _, tc := range cases {
+		got, ok := tc.totp.Verify(rfc6238Secret, code, tc.now)
+		if ok != tc.ok {
+			t.Errorf("%s: ok = %t; want %t", tc.name, ok, tc.ok)
+			continue
+		}
+		if ok && got != step {
+			t.Errorf("%s: step = %d; want %d (the step the code was issued for)", tc.name, got, step)
+		}
+	}
+}
+
+// Kode yang sama dipakai ulang di step berikutnya tetap memetakan ke step
+// yang sudah terpakai, sehingga anti-replay di caller menolaknya.
+func TestTOTPReplayMapsToUsedStep(t *testing.T) {
+	totp := TOTP{}
+	issued := time.Unix(1234567890, 0)
+	first, ok := totp.Verify(rfc6238Secret, "005924", issued)
+	if !ok {
+		t.Fatal("Verify(first use) failed")
+	}
+	again, ok := totp.Verify(rfc6238Secret, "005924", issued.Add(totpPeriod*time.Second))
+	if !ok || again > first {
+		t.Errorf("Verify(replay) = %d, %t; want step <= %d", again, ok, first)
+	}
+}
+
+func TestTOTPRejectsMalformedCodes(t *testing.T) {
+	totp := TOTP{}
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "abcdef", "94287082"} {
+		if _, ok := totp.Verify(rfc6238Secret, code, now); ok {
+			t.Errorf("Verify(%q) accepted", code)
+		}
+	}
+	if _, ok := totp.Verify(rfc6238Secret, " 287 082 ", now); !ok {
+		t.Error("Verify with spaces rejected")
+	}
+}
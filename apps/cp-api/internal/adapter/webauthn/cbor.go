@@ -0,0 +1,122 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Decoder CBOR (RFC 8949) minimal untuk attestationObject dan COSE key.
+// Authenticator memakai encoding CTAP2 canonical, jadi indefinite-length
+// dan float tidak didukung.
+
+var errCBOR = errors.New("webauthn: malformed cbor")
+
+const cborMaxDepth = 16
+
+// decodeCBOR mengembalikan satu item dan sisa byte setelahnya.
+// Tipe hasil: int64, []byte, string, []any, map[any]any, bool, nil.
+func decodeCBOR(b []byte) (any, []byte, error) {
+	return decodeItem(b, 0)
+}
+
+func decodeItem(b []byte, depth int) (any, []byte, error) {
+	if depth > cborMaxDepth {
+		return nil, nil, errCBOR
+	}
+	if len(b) == 0 {
+		return nil, nil, errCBOR
+	}
+	major, info := b[0]>>5, b[0]&0x1f
+	b = b[1:]
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, b, nil
+		case 21:
+			return true, b, nil
+		case 22, 23:
+			return nil, b, nil
+		}
+		return nil, nil, fmt.Errorf("%w: unsupported simple value %d", errCBOR, info)
+	}
+
+	n, b, err := readUint(info, b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0:
+		if n > 1<<63-1 {
+			return nil, nil, errCBOR
+		}
+		return int64(n), b, nil
+	case 1:
+		if n > 1<<63-1 {
+			return nil, nil, errCBOR
+		}
+		return -1 - int64(n), b, nil
+	case 2, 3:
+		if uint64(len(b)) < n {
+			return nil, nil, errCBOR
+		}
+		v := b[:n]
+		if major == 3 {
+			return string(v), b[n:], nil
+		}
+		return append([]byte(nil), v...), b[n:], nil
+	case 4:
+		if n > uint64(len(b)) { // tiap item minimal 1 byte
+			return nil, nil, errCBOR
+		}
+		arr := make([]any, 0, n)
+		for i := uint64(0); i < n; i++ {
+			var v any
+			if v, b, err = decodeItem(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, b, nil
+	case 5:
+		if n > uint64(len(b))/2 {
+			return nil, nil, errCBOR
+		}
+		m := make(map[any]any, n)
+		for i := uint64(0); i < n; i++ {
+			var k, v any
+			if k, b, err = decodeItem(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("%w: unsupported map key %T", errCBOR, k)
+			}
+			if v, b, err = decodeItem(b, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[k] = v
+		}
+		return m, b, nil
+	}
+	return nil, nil, fmt.Errorf("%w: unsupported major type %d", errCBOR, major)
+}
+
+func readUint(info byte, b []byte) (uint64, []byte, error) {
+	switch {
+	case info < 24:
+		return uint64(info), b, nil
+	case info == 24 && len(b) >= 1:
+		return uint64(b[0]), b[1:], nil
+	case info == 25 && len(b) >= 2:
+		return uint64(binary.BigEndian.Uint16(b)), b[2:], nil
+	case info == 26 && len(b) >= 4:
+		return uint64(binary.BigEndian.Uint32(b)), b[4:], nil
+	case info == 27 && len(b) >= 8:
+		return binary.BigEndian.Uint64(b), b[8:], nil
+	}
+	return 0, nil, errCBOR
+}
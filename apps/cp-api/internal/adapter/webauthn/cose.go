@@ -0,0 +1,106 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifier (RFC 9053) yang didukung
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+const (
+	coseKty    = 1
+	coseAlg    = 3
+	coseCrv    = -1 // EC2/OKP: crv, RSA: n
+	coseX      = -2 // EC2/OKP: x, RSA: e
+	coseY      = -3
+	ktyOKP     = 1
+	ktyEC2     = 2
+	ktyRSA     = 3
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+var errCOSEKey = errors.New("webauthn: unsupported or malformed COSE key")
+
+type coseKey struct {
+	alg int64
+	pub crypto.PublicKey
+}
+
+func parseCOSEKey(raw []byte) (*coseKey, error) {
+	v, rest, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errCOSEKey
+	}
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, errCOSEKey
+	}
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+		y, _ := m[int64(coseY)].([]byte)
+		if crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, errCOSEKey
+		}
+		// ecdh memvalidasi titik ada di kurva
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, errCOSEKey
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		return &coseKey{alg: alg, pub: pub}, nil
+
+	case kty == ktyOKP && alg == AlgEdDSA:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+		if crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, errCOSEKey
+		}
+		return &coseKey{alg: alg, pub: ed25519.PublicKey(x)}, nil
+
+	case kty == ktyRSA && alg == AlgRS256:
+		n, _ := m[int64(coseCrv)].([]byte)
+		e, _ := m[int64(coseX)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errCOSEKey
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return &coseKey{alg: alg, pub: pub}, nil
+	}
+	return nil, fmt.Errorf("%w (kty %d, alg %d)", errCOSEKey, kty, alg)
+}
+
+func (k *coseKey) verify(data, sig []byte) bool {
+	switch pub := k.pub.(type) {
+	case *ecdsa.PublicKey:
+		h := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(pub, h[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(pub, data, sig)
+	case *rsa.PublicKey:
+		h := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], sig) == nil
+	}
+	return false
+}
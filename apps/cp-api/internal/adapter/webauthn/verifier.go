@@ -0,0 +1,283 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// Verifier memeriksa respon ceremony WebAuthn Level 2 (registration & assertion)
+// untuk satu Relying Party. Attestation yang diterima: "none" dan "packed"
+// (signature dicek, rantai sertifikat tidak — kita tidak memakai attestation
+// untuk keputusan trust).
+type Verifier struct {
+	rpID    string
+	rpHash  [32]byte
+	origins map[string]struct{}
+}
+
+var _ contract.WebAuthnVerifier = (*Verifier)(nil)
+
+var (
+	ErrClientData   = errors.New("webauthn: invalid client data")
+	ErrAuthData     = errors.New("webauthn: invalid authenticator data")
+	ErrAttestation  = errors.New("webauthn: invalid attestation")
+	ErrSignature    = errors.New("webauthn: invalid signature")
+	ErrUserPresence = errors.New("webauthn: user presence required")
+	ErrVerifiedUser = errors.New("webauthn: user verification required")
+)
+
+const challengeSize = 32
+
+// NewVerifier: rpID = domain tanpa skema (ex: "xeed.id"), origins = origin
+// lengkap yang diizinkan (ex: "https://app.xeed.id").
+func NewVerifier(rpID string, origins []string) *Verifier {
+	if rpID == "" {
+		panic("webauthn.NewVerifier: rpID is empty")
+	}
+	v := &Verifier{rpID: rpID, rpHash: sha256.Sum256([]byte(rpID)), origins: map[string]struct{}{}}
+	for _, o := range origins {
+		if o = strings.TrimRight(strings.TrimSpace(o), "/"); o != "" {
+			v.origins[o] = struct{}{}
+		}
+	}
+	return v
+}
+
+func (v *Verifier) RPID() string { return v.rpID }
+
+func (v *Verifier) NewChallenge() ([]byte, error) {
+	c := make([]byte, challengeSize)
+	if _, err := rand.Read(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (v *Verifier) VerifyRegistration(challenge, clientDataJSON, attestationObject []byte, requireUV bool) (*contract.WebAuthnAttestation, error) {
+	if err := v.checkClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	obj, rest, err := decodeCBOR(attestationObject)
+	if err != nil || len(rest) != 0 {
+		return nil, ErrAttestation
+	}
+	m, ok := obj.(map[any]any)
+	if !ok {
+		return nil, ErrAttestation
+	}
+	format, _ := m["fmt"].(string)
+	rawAuth, _ := m["authData"].([]byte)
+	attStmt, _ := m["attStmt"].(map[any]any)
+
+	ad, err := v.parseAuthData(rawAuth, requireUV)
+	if err != nil {
+		return nil, err
+	}
+	if ad.flags&flagAT == 0 || ad.key == nil {
+		return nil, fmt.Errorf("%w: no attested credential", ErrAuthData)
+	}
+
+	switch format {
+	case "none":
+		if len(attStmt) != 0 {
+			return nil, ErrAttestation
+		}
+	case "packed":
+		if err := verifyPacked(attStmt, ad, rawAuth, clientDataJSON); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrAttestation, format)
+	}
+
+	return &contract.WebAuthnAttestation{
+		CredentialID:   ad.credID,
+		PublicKey:      ad.rawKey,
+		AAGUID:         ad.aaguid,
+		SignCount:      ad.signCount,
+		UserVerified:   ad.flags&flagUV != 0,
+		BackupEligible: ad.flags&flagBE != 0,
+	}, nil
+}
+
+func (v *Verifier) VerifyAssertion(challenge, clientDataJSON, authenticatorData, signature, publicKey []byte, requireUV bool) (uint32, error) {
+	if err := v.checkClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+	ad, err := v.parseAuthData(authenticatorData, requireUV)
+	if err != nil {
+		return 0, err
+	}
+	key, err := parseCOSEKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+	if !key.verify(signedData(authenticatorData, clientDataJSON), signature) {
+		return 0, ErrSignature
+	}
+	return ad.signCount, nil
+}
+
+// signedData = authenticatorData || SHA-256(clientDataJSON)
+func signedData(authData, clientDataJSON []byte) []byte {
+	h := sha256.Sum256(clientDataJSON)
+	return append(append([]byte(nil), authData...), h[:]...)
+}
+
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+func (v *Verifier) checkClientData(raw []byte, typ string, challenge []byte) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return ErrClientData
+	}
+	if cd.Type != typ || cd.CrossOrigin {
+		return ErrClientData
+	}
+	got, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(cd.Challenge, "="))
+	if err != nil || len(challenge) == 0 || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return fmt.Errorf("%w: challenge mismatch", ErrClientData)
+	}
+	if _, ok := v.origins[cd.Origin]; !ok {
+		return fmt.Errorf("%w: origin %q not allowed", ErrClientData, cd.Origin)
+	}
+	return nil
+}
+
+// flag authenticator data (WebAuthn §6.1)
+const (
+	flagUP = 1 << 0
+	flagUV = 1 << 2
+	flagBE = 1 << 3
+	flagAT = 1 << 6
+	flagED = 1 << 7
+)
+
+type authData struct {
+	flags     byte
+	signCount uint32
+	aaguid    []byte
+	credID    []byte
+	rawKey    []byte // COSE_Key apa adanya, disimpan untuk verifikasi assertion
+	key       *coseKey
+}
+
+func (v *Verifier) parseAuthData(b []byte, requireUV bool) (*authData, error) {
+	if len(b) < 37 {
+		return nil, ErrAuthData
+	}
+	if subtle.ConstantTimeCompare(b[:32], v.rpHash[:]) != 1 {
+		return nil, fmt.Errorf("%w: rpId hash mismatch", ErrAuthData)
+	}
+	ad := &authData{flags: b[32], signCount: binary.BigEndian.Uint32(b[33:37])}
+	if ad.flags&flagUP == 0 {
+		return nil, ErrUserPresence
+	}
+	if requireUV && ad.flags&flagUV == 0 {
+		return nil, ErrVerifiedUser
+	}
+	rest := b[37:]
+
+	if ad.flags&flagAT != 0 {
+		if len(rest) < 18 {
+			return nil, ErrAuthData
+		}
+		ad.aaguid = append([]byte(nil), rest[:16]...)
+		n := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if n == 0 || n > 1023 || len(rest) < n {
+			return nil, ErrAuthData
+		}
+		ad.credID = append([]byte(nil), rest[:n]...)
+		rest = rest[n:]
+
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrAuthData
+		}
+		ad.rawKey = append([]byte(nil), rest[:len(rest)-len(after)]...)
+		if ad.key, err = parseCOSEKey(ad.rawKey); err != nil {
+			return nil, err
+		}
+		rest = after
+	}
+	if ad.flags&flagED != 0 {
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrAuthData
+		}
+		rest = after
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: trailing bytes", ErrAuthData)
+	}
+	return ad, nil
+}
+
+// verifyPacked: attestation "packed" (WebAuthn §8.2), self attestation atau x5c.
+func verifyPacked(stmt map[any]any, ad *authData, rawAuth, clientDataJSON []byte) error {
+	alg, _ := stmt["alg"].(int64)
+	sig, _ := stmt["sig"].([]byte)
+	if len(sig) == 0 {
+		return ErrAttestation
+	}
+	data := signedData(rawAuth, clientDataJSON)
+
+	x5c, _ := stmt["x5c"].([]any)
+	if len(x5c) == 0 {
+		if alg != ad.key.alg || !ad.key.verify(data, sig) {
+			return ErrAttestation
+		}
+		return nil
+	}
+
+	der, _ := x5c[0].([]byte)
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return ErrAttestation
+	}
+	var sigAlg x509.SignatureAlgorithm
+	switch alg {
+	case AlgES256:
+		sigAlg = x509.ECDSAWithSHA256
+	case AlgEdDSA:
+		sigAlg = x509.PureEd25519
+	case AlgRS256:
+		sigAlg = x509.SHA256WithRSA
+	default:
+		return ErrAttestation
+	}
+	if err := cert.CheckSignature(sigAlg, data, sig); err != nil {
+		return ErrAttestation
+	}
+	// sertifikat attestation tidak boleh berupa CA; AAGUID di ekstensi (kalau ada) harus sama
+	if cert.IsCA {
+		return ErrAttestation
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.String() == "1.3.6.1.4.1.45724.1.1.4" {
+			// OCTET STRING berisi 16 byte AAGUID
+			if len(ext.Value) != 18 || !bytes.Equal(ext.Value[2:], ad.aaguid) {
+				return ErrAttestation
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"xeed/apps/cp-api/internal/domain"
+)
+
+const (
+	testRPID   = "xeed.test"
+	testOrigin = "https://app.xeed.test"
+)
+
+// softAuthenticator: authenticator software untuk menjalankan ceremony
+// WebAuthn tanpa hardware.
+type softAuthenticator struct {
+	rpID    string
+	origin  string
+	alg     int64
+	signer  crypto.Signer
+	credID  []byte
+	counter uint32
+	uv      bool
+}
+
+func newSoftAuthenticator(t *testing.T, alg int64) *softAuthenticator {
+	t.Helper()
+	a := &softAuthenticator{rpID: testRPID, origin: testOrigin, alg: alg, credID: randBytes(t, 32), uv: true}
+	switch alg {
+	case AlgES256:
+		k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.signer = k
+	case AlgEdDSA:
+		_, k, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.signer = k
+	default:
+		t.Fatalf("unsupported alg %d", alg)
+	}
+	return a
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	switch pub := a.signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		x, y := make([]byte, 32), make([]byte, 32)
+		pub.X.FillBytes(x)
+		pub.Y.FillBytes(y)
+		return cborEncode(cborMap{
+			{int64(coseKty), int64(ktyEC2)}, {int64(coseAlg), AlgES256},
+			{int64(coseCrv), int64(crvP256)}, {int64(coseX), x}, {int64(coseY), y},
+		})
+	case ed25519.PublicKey:
+		return cborEncode(cborMap{
+			{int64(coseKty), int64(ktyOKP)}, {int64(coseAlg), AlgEdDSA},
+			{int64(coseCrv), int64(crvEd25519)}, {int64(coseX), []byte(pub)},
+		})
+	}
+	panic("unreachable")
+}
+
+func (a *softAuthenticator) authData(attested bool) []byte {
+	rpHash := sha256.Sum256([]byte(a.rpID))
+	flags := byte(flagUP)
+	if a.uv {
+		flags |= flagUV
+	}
+	if attested {
+		flags |= flagAT
+	}
+	b := append([]byte(nil), rpHash[:]...)
+	b = append(b, flags)
+	b = binary.BigEndian.AppendUint32(b, a.counter)
+	if attested {
+		b = append(b, make([]byte, 16)...) // AAGUID kosong
+		b = binary.BigEndian.AppendUint16(b, uint16(len(a.credID)))
+		b = append(b, a.credID...)
+		b = append(b, a.coseKey()...)
+	}
+	return b
+}
+
+func (a *softAuthenticator) clientData(typ string, challenge []byte) []byte {
+	raw, _ := json.Marshal(map[string]any{
+		"type":      typ,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    a.origin,
+	})
+	return raw
+}
+
+func (a *softAuthenticator) sign(data []byte) []byte {
+	var (
+		sig []byte
+		err error
+	)
+	if a.alg == AlgES256 {
+		h := sha256.Sum256(data)
+		sig, err = a.signer.Sign(rand.Reader, h[:], crypto.SHA256)
+	} else {
+		sig, err = a.signer.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	if err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+// create: navigator.credentials.create(), format "none" atau "packed" (self attestation)
+func (a *softAuthenticator) create(challenge []byte, format string) (clientDataJSON, attestationObject []byte) {
+	clientDataJSON = a.clientData("webauthn.create", challenge)
+	ad := a.authData(true)
+	stmt := cborMap{}
+	if format == "packed" {
+		stmt = cborMap{{"alg", a.alg}, {"sig", a.sign(signedData(ad, clientDataJSON))}}
+	}
+	attestationObject = cborEncode(cborMap{{"fmt", format}, {"attStmt", stmt}, {"authData", ad}})
+	return clientDataJSON, attestationObject
+}
+
+// get: navigator.credentials.get(); counter naik setiap assertion
+func (a *softAuthenticator) get(challenge []byte) (clientDataJSON, authData, sig []byte) {
+	a.counter++
+	clientDataJSON = a.clientData("webauthn.get", challenge)
+	authData = a.authData(false)
+	return clientDataJSON, authData, a.sign(signedData(authData, clientDataJSON))
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		alg    int64
+		format string
+	}{
+		{"ES256/none", AlgES256, "none"},
+		{"EdDSA/none", AlgEdDSA, "none"},
+		{"ES256/packed", AlgES256, "packed"},
+		{"EdDSA/packed", AlgEdDSA, "packed"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v := NewVerifier(testRPID, []string{testOrigin})
+			auth := newSoftAuthenticator(t, tc.alg)
+
+			challenge, err := v.NewChallenge()
+			if err != nil {
+				t.Fatal(err)
+			}
+			cd, att := auth.create(challenge, tc.format)
+			res, err := v.VerifyRegistration(challenge, cd, att, true)
+			if err != nil {
+				t.Fatalf("VerifyRegistration: %v", err)
+			}
+			if string(res.CredentialID) != string(auth.credID) || !res.UserVerified {
+				t.Fatalf("unexpected attestation result: %+v", res)
+			}
+
+			for want := uint32(1); want <= 2; want++ {
+				challenge, _ = v.NewChallenge()
+				cd, ad, sig := auth.get(challenge)
+				got, err := v.VerifyAssertion(challenge, cd, ad, sig, res.PublicKey, true)
+				if err != nil {
+					t.Fatalf("VerifyAssertion: %v", err)
+				}
+				if got != want {
+					t.Fatalf("sign count = %d, want %d", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAssertionRejected(t *testing.T) {
+	v := NewVerifier(testRPID, []string{testOrigin})
+	auth := newSoftAuthenticator(t, AlgES256)
+	challenge, _ := v.NewChallenge()
+	cd, att := auth.create(challenge, "none")
+	reg, err := v.VerifyRegistration(challenge, cd, att, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := newSoftAuthenticator(t, AlgES256)
+
+	for _, tc := range []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{"wrong challenge", func() error {
+			c, _ := v.NewChallenge()
+			cd, ad, sig := auth.get(c)
+			_, err := v.VerifyAssertion(challenge, cd, ad, sig, reg.PublicKey, false)
+			return err
+		}, ErrClientData},
+		{"wrong origin", func() error {
+			auth.origin = "https://evil.test"
+			defer func() { auth.origin = testOrigin }()
+			cd, ad, sig := auth.get(challenge)
+			_, err := v.VerifyAssertion(challenge, cd, ad, sig, reg.PublicKey, false)
+			return err
+		}, ErrClientData},
+		{"wrong rp id", func() error {
+			auth.rpID = "evil.test"
+			defer func() { auth.rpID = testRPID }()
+			cd, ad, sig := auth.get(challenge)
+			_, err := v.VerifyAssertion(challenge, cd, ad, sig, reg.PublicKey, false)
+			return err
+		}, ErrAuthData},
+		{"create response replayed as get", func() error {
+			cd, _ := auth.create(challenge, "none")
+			_, ad, sig := auth.get(challenge)
+			_, err := v.VerifyAssertion(challenge, cd, ad, sig, reg.PublicKey, false)
+			return err
+		}, ErrClientData},
+		{"signed by another key", func() error {
+			cd, ad, sig := other.get(challenge)
+			_, err := v.VerifyAssertion(challenge, cd, ad, sig, reg.PublicKey, false)
+			return err
+		}, ErrSignature},
+		{"tampered authenticator data", func() error {
+			cd, ad, sig := auth.get(challenge)
+			ad[36]++ // counter
+			_, err := v.VerifyAssertion(challenge, cd, ad, sig, reg.PublicKey, false)
+			return err
+		}, ErrSignature},
+		{"user verification required", func() error {
+			auth.uv = false
+			defer func() { auth.uv = true }()
+			cd, ad, sig := auth.get(challenge)
+			_, err := v.VerifyAssertion(challenge, cd, ad, sig, reg.PublicKey, true)
+			return err
+		}, ErrVerifiedUser},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.run(); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegistrationRejectsUnsupportedFormat(t *testing.T) {
+	v := NewVerifier(testRPID, []string{testOrigin})
+	auth := newSoftAuthenticator(t, AlgEdDSA)
+	challenge, _ := v.NewChallenge()
+	cd, _ := auth.create(challenge, "none")
+	att := cborEncode(cborMap{{"fmt", "fido-u2f"}, {"attStmt", cborMap{}}, {"authData", auth.authData(true)}})
+	if _, err := v.VerifyRegistration(challenge, cd, att, false); !errors.Is(err, ErrAttestation) {
+		t.Fatalf("err = %v, want ErrAttestation", err)
+	}
+}
+
+// Authenticator kloning: salinan key dengan counter lama tertinggal dari aslinya.
+func TestSignCountCloneDetection(t *testing.T) {
+	v := NewVerifier(testRPID, []string{testOrigin})
+	auth := newSoftAuthenticator(t, AlgES256)
+	challenge, _ := v.NewChallenge()
+	cd, att := auth.create(challenge, "none")
+	reg, err := v.VerifyRegistration(challenge, cd, att, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred := domain.WebAuthnCredential{PublicKey: reg.PublicKey, SignCount: reg.SignCount}
+	clone := *auth
+
+	for range 3 {
+		challenge, _ = v.NewChallenge()
+		cd, ad, sig := auth.get(challenge)
+		n, err := v.VerifyAssertion(challenge, cd, ad, sig, cred.PublicKey, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cred.SignCountOK(n) {
+			t.Fatalf("genuine authenticator rejected at count %d (stored %d)", n, cred.SignCount)
+		}
+		cred.SignCount = n
+	}
+
+	challenge, _ = v.NewChallenge()
+	cd, ad, sig := clone.get(challenge)
+	n, err := v.VerifyAssertion(challenge, cd, ad, sig, cred.PublicKey, false)
+	if err != nil {
+		t.Fatal(err) // signature tetap valid, hanya counter yang membongkar
+	}
+	if cred.SignCountOK(n) {
+		t.Fatalf("cloned authenticator accepted: count %d, stored %d", n, cred.SignCount)
+	}
+
+	// authenticator tanpa counter (selalu 0) tetap diterima
+	if !(domain.WebAuthnCredential{}).SignCountOK(0) {
+		t.Fatal("zero counter rejected")
+	}
+}
+
+func TestDecodeCBORMalformed(t *testing.T) {
+	for _, b := range [][]byte{
+		{},
+		{0x5f},       // byte string indefinite-length
+		{0x45, 1, 2}, // byte string terpotong
+		{0xa1, 0x01}, // map tanpa value
+		{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, // array raksasa
+	} {
+		if _, _, err := decodeCBOR(b); err == nil {
+			t.Errorf("decodeCBOR(%x) succeeded", b)
+		}
+	}
+}
+
+// --- encoder CBOR minimal untuk software authenticator ---
+
+type cborMap []struct {
+	k, v any
+}
+
+func cborEncode(v any) []byte {
+	switch x := v.(type) {
+	case int64:
+		if x >= 0 {
+			return cborHead(0, uint64(x))
+		}
+		return cborHead(1, uint64(-1-x))
+	case []byte:
+		return append(cborHead(2, uint64(len(x))), x...)
+	case string:
+		return append(cborHead(3, uint64(len(x))), x...)
+	case cborMap:
+		b := cborHead(5, uint64(len(x)))
+		for _, kv := range x {
+			b = append(b, cborEncode(kv.k)...)
+			b = append(b, cborEncode(kv.v)...)
+		}
+		return b
+	}
+	panic("cborEncode: unsupported type")
+}
+
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	}
+	return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+}
+
+func randBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
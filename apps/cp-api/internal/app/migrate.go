@@ -0,0 +1,82 @@
+// apps/cp-api/internal/app/migrate.go
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"xeed/apps/cp-api/internal/config"
+	"xeed/apps/cp-api/internal/repo/pg/migrations"
+)
+
+const migrateUsage = "usage: cp-api migrate up | down [steps] | status"
+
+// Migrate: subcommand "cp-api migrate ...".
+func Migrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	cfg := config.FromEnv()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	m, err := migrations.New(pool)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := m.Up(ctx)
+		for _, mig := range done {
+			fmt.Printf("applied  %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return err
+	case "down":
+		steps := 1 // default: satu migration terakhir saja
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return errors.New("migrate down: steps must be a positive number")
+			}
+		}
+		done, err := m.Down(ctx, steps)
+		for _, mig := range done {
+			fmt.Printf("reverted %04d_%s\n", mig.Version, mig.Name)
+		}
+		return err
+	case "status":
+		st, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+		for _, s := range st {
+			at := "-"
+			if s.AppliedAt != nil {
+				at = s.AppliedAt.Local().Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, s.State, at)
+		}
+		return tw.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
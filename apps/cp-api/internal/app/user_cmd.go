@@ -0,0 +1,389 @@
+// apps/cp-api/internal/app/user_cmd.go
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"xeed/apps/cp-api/internal/config"
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase"
+
+	"github.com/google/uuid"
+)
+
+const userUsage = `usage: cp-api user <command> [flags]
+
+commands:
+  create --email EMAIL [--name NAME] [--password-stdin] [--must-change] [--verified] [--role NAME]
+  list [--status STATUS] [--query TEXT] [--email-prefix TEXT] [--service-account=BOOL]
+       [--verified=BOOL] [--created-from DATE] [--created-to DATE]
+       [--sort createdAt|-createdAt] [--limit N] [--cursor CURSOR]
+  lock <email|user-id>
+  unlock <email|user-id>
+  suspend <email|user-id>
+  reset-password <email|user-id> [--password-stdin] [--must-change]
+  set-must-change <email|user-id> [--value=false]
+  roles [<email|user-id>]
+  grant-role <email|user-id> <role>
+  revoke-role <email|user-id> <role>
+
+Tanpa --password-stdin dibuatkan password sementara yang dicetak sekali
+dan wajib diganti saat login.`
+
+// User: subcommand "cp-api user ..." untuk operator (bootstrap admin pertama,
+// kasus support) tanpa SQL manual. Perubahan dicatat tanpa actor (UpdatedBy NULL).
+func User(args []string) error {
+	if len(args) == 0 {
+		return errors.New(userUsage)
+	}
+	cfg := config.FromEnv()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	svc, cleanup, err := buildServices(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "create":
+		return userCreate(ctx, svc, args)
+	case "list":
+		return userList(ctx, svc, args)
+	case "lock":
+		return userStatus(ctx, svc, args, svc.admin.Lock)
+	case "unlock":
+		return userStatus(ctx, svc, args, svc.admin.Reactivate)
+	case "suspend":
+		return userStatus(ctx, svc, args, svc.admin.Suspend)
+	case "reset-password":
+		return userResetPassword(ctx, svc, args)
+	case "set-must-change":
+		return userSetMustChange(ctx, svc, args)
+	case "roles":
+		return userRoles(ctx, svc, args)
+	case "grant-role":
+		return userRoleChange(ctx, svc, args, svc.roles.Assign, "granted")
+	case "revoke-role":
+		return userRoleChange(ctx, svc, args, svc.roles.Unassign, "revoked")
+	default:
+		return errors.New(userUsage)
+	}
+}
+
+func userCreate(ctx context.Context, svc *services, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := fs.String("email", "", "email user")
+	name := fs.String("name", "", "display name")
+	fromStdin := fs.Bool("password-stdin", false, "baca password dari stdin")
+	mustChange := fs.Bool("must-change", false, "wajib ganti password saat login")
+	verified := fs.Bool("verified", false, "tandai email sudah terverifikasi")
+	roleName := fs.String("role", "", "role awal (ex: admin untuk bootstrap admin pertama)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	in := dto.AdminCreateUserRequest{
+		Email:              *email,
+		MustChangePassword: *mustChange,
+		EmailVerified:      *verified,
+	}
+	if *name != "" {
+		in.DisplayName = name
+	}
+	if *fromStdin {
+		pw, err := readPassword(os.Stdin)
+		if err != nil {
+			return err
+		}
+		in.Password = pw
+	}
+
+	// role dicek dulu supaya user tidak terlanjur dibuat dengan nama role yang salah
+	var role *domain.Role
+	if *roleName != "" {
+		var err error
+		if role, err = svc.roles.GetByName(ctx, *roleName); err != nil {
+			return cliError(err)
+		}
+	}
+
+	resp, err := svc.admin.CreateUser(ctx, nil, in)
+	if err != nil {
+		return cliError(err)
+	}
+	fmt.Printf("created %s %s (%s)\n", resp.User.UserID, resp.User.Email, resp.User.Status)
+	if role != nil {
+		if err := svc.roles.Assign(ctx, nil, resp.User.UserID, role.RoleID); err != nil {
+			return cliError(err)
+		}
+		fmt.Printf("role: %s\n", role.Name)
+	}
+	if resp.TemporaryPassword != "" {
+		fmt.Printf("temporary password: %s\n", resp.TemporaryPassword)
+	}
+	return nil
+}
+
+func userList(ctx context.Context, svc *services, args []string) error {
+	fs := flag.NewFlagSet("user list", flag.ContinueOnError)
+	var in dto.AdminListUsersRequest
+	var createdFrom, createdTo string
+	fs.StringVar(&in.Status, "status", "", "PENDING | ACTIVE | LOCKED | SUSPENDED")
+	fs.StringVar(&in.Query, "query", "", "potongan email / display name")
+	fs.StringVar(&in.EmailPrefix, "email-prefix", "", "awal email")
+	fs.Var(optBool{&in.ServiceAccount}, "service-account", "true | false")
+	fs.Var(optBool{&in.EmailVerified}, "verified", "true | false")
+	fs.StringVar(&createdFrom, "created-from", "", "dibuat sejak (YYYY-MM-DD atau RFC 3339)")
+	fs.StringVar(&createdTo, "created-to", "", "dibuat sebelum (YYYY-MM-DD atau RFC 3339)")
+	fs.StringVar(&in.Sort, "sort", "createdAt", "createdAt | -createdAt")
+	fs.IntVar(&in.Limit, "limit", 50, "jumlah maksimum per halaman")
+	fs.StringVar(&in.Cursor, "cursor", "", "next cursor dari halaman sebelumnya")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var err error
+	if in.CreatedFrom, err = parseCLITime(createdFrom); err != nil {
+		return err
+	}
+	if in.CreatedTo, err = parseCLITime(createdTo); err != nil {
+		return err
+	}
+	resp, err := svc.admin.List(ctx, in)
+	if err != nil {
+		return cliError(err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "USER ID\tEMAIL\tSTATUS\tMFA\tMUST CHANGE\tCREATED AT")
+	for _, u := range resp.Items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%t\t%s\n",
+			u.UserID, u.Email, u.Status, u.MFAEnrolled, u.MustChangePassword, u.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if resp.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "next page: --cursor %s\n", resp.NextCursor)
+	}
+	return nil
+}
+
+// optBool: flag bool opsional; tidak diset = nil (tidak difilter).
+type optBool struct{ p **bool }
+
+func (o optBool) String() string {
+	if o.p == nil || *o.p == nil {
+		return ""
+	}
+	return strconv.FormatBool(**o.p)
+}
+
+func (o optBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*o.p = &v
+	return nil
+}
+
+func (o optBool) IsBoolFlag() bool { return true }
+
+func parseCLITime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time %q (want YYYY-MM-DD or RFC 3339)", s)
+}
+
+func userStatus(ctx context.Context, svc *services, args []string, fn func(context.Context, *uuid.UUID, uuid.UUID) (*domain.User, error)) error {
+	if len(args) != 1 {
+		return errors.New(userUsage)
+	}
+	u, err := resolveUser(ctx, svc, args[0])
+	if err != nil {
+		return err
+	}
+	updated, err := fn(ctx, nil, u.UserID)
+	if err != nil {
+		return cliError(err)
+	}
+	fmt.Printf("%s %s: %s\n", updated.UserID, updated.Email, updated.Status)
+	return nil
+}
+
+func userResetPassword(ctx context.Context, svc *services, args []string) error {
+	ref, args, err := userRef(args)
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	fromStdin := fs.Bool("password-stdin", false, "baca password dari stdin")
+	mustChange := fs.Bool("must-change", false, "wajib ganti password saat login")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	u, err := resolveUser(ctx, svc, ref)
+	if err != nil {
+		return err
+	}
+	in := dto.AdminResetPasswordRequest{MustChangePassword: *mustChange}
+	if *fromStdin {
+		if in.Password, err = readPassword(os.Stdin); err != nil {
+			return err
+		}
+	}
+
+	resp, err := svc.admin.ResetPassword(ctx, nil, u.UserID, in)
+	if err != nil {
+		return cliError(err)
+	}
+	fmt.Printf("password reset for %s %s; all sessions revoked\n", u.UserID, u.Email)
+	if resp.TemporaryPassword != "" {
+		fmt.Printf("temporary password: %s\n", resp.TemporaryPassword)
+	}
+	return nil
+}
+
+func userSetMustChange(ctx context.Context, svc *services, args []string) error {
+	ref, args, err := userRef(args)
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("user set-must-change", flag.ContinueOnError)
+	value := fs.Bool("value", true, "nilai flag MustChangePassword")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	u, err := resolveUser(ctx, svc, ref)
+	if err != nil {
+		return err
+	}
+	updated, err := svc.admin.SetMustChangePassword(ctx, nil, u.UserID, *value)
+	if err != nil {
+		return cliError(err)
+	}
+	fmt.Printf("%s %s: mustChangePassword=%t\n", updated.UserID, updated.Email, updated.MustChangePassword)
+	return nil
+}
+
+// userRoles: tanpa argumen = semua role; dengan <email|user-id> = role milik user.
+func userRoles(ctx context.Context, svc *services, args []string) error {
+	var (
+		roles []domain.Role
+		err   error
+	)
+	switch len(args) {
+	case 0:
+		roles, err = svc.roles.List(ctx)
+	case 1:
+		u, rerr := resolveUser(ctx, svc, args[0])
+		if rerr != nil {
+			return rerr
+		}
+		roles, err = svc.roles.UserRoles(ctx, u.UserID)
+	default:
+		return errors.New(userUsage)
+	}
+	if err != nil {
+		return cliError(err)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROLE ID\tNAME\tSYSTEM\tPERMISSIONS")
+	for _, r := range roles {
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%s\n", r.RoleID, r.Name, r.IsSystem, strings.Join(r.Permissions, ","))
+	}
+	return tw.Flush()
+}
+
+func userRoleChange(ctx context.Context, svc *services, args []string, fn func(context.Context, *uuid.UUID, uuid.UUID, uuid.UUID) error, verb string) error {
+	if len(args) != 2 {
+		return errors.New(userUsage)
+	}
+	u, err := resolveUser(ctx, svc, args[0])
+	if err != nil {
+		return err
+	}
+	role, err := svc.roles.GetByName(ctx, args[1])
+	if err != nil {
+		return cliError(err)
+	}
+	if err := fn(ctx, nil, u.UserID, role.RoleID); err != nil {
+		return cliError(err)
+	}
+	fmt.Printf("%s %s: role %s %s\n", u.UserID, u.Email, role.Name, verb)
+	return nil
+}
+
+// userRef: argumen pertama <email|user-id>, sisanya flag.
+func userRef(args []string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", nil, errors.New(userUsage)
+	}
+	return args[0], args[1:], nil
+}
+
+func resolveUser(ctx context.Context, svc *services, ref string) (*domain.User, error) {
+	var (
+		u   *domain.User
+		err error
+	)
+	if id, perr := uuid.Parse(ref); perr == nil {
+		u, err = svc.users.GetByID(ctx, id)
+	} else {
+		u, err = svc.users.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(ref)))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, fmt.Errorf("user %q not found", ref)
+	}
+	return u, nil
+}
+
+// readPassword: baris pertama stdin (supaya password tidak muncul di argv / history shell).
+func readPassword(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	pw := strings.TrimRight(line, "\r\n")
+	if pw == "" {
+		return "", errors.New("empty password on stdin")
+	}
+	return pw, nil
+}
+
+// cliError: pelanggaran policy password ditampilkan satu per baris.
+func cliError(err error) error {
+	if pe, ok := usecase.IsPasswordPolicyError(err); ok {
+		msgs := make([]string, len(pe.Violations))
+		for i, v := range pe.Violations {
+			msgs[i] = "  - " + v.Message
+		}
+		return fmt.Errorf("%s:\n%s", usecase.ErrWeakPassword.Message, strings.Join(msgs, "\n"))
+	}
+	return err
+}
@@ -3,27 +3,85 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 
+	chimw "github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"xeed/apps/cp-api/internal/adapter/notify"
 	"xeed/apps/cp-api/internal/adapter/security"
 	"xeed/apps/cp-api/internal/adapter/system"
+	"xeed/apps/cp-api/internal/adapter/webauthn"
 	"xeed/apps/cp-api/internal/config"
+	"xeed/apps/cp-api/internal/domain"
 	"xeed/apps/cp-api/internal/http/handlers"
+	"xeed/apps/cp-api/internal/http/middleware"
 	"xeed/apps/cp-api/internal/repo/pg"
 	"xeed/apps/cp-api/internal/routers"
 	"xeed/apps/cp-api/internal/usecase"
+	"xeed/apps/cp-api/internal/usecase/contract"
 )
 
-func buildHTTP(ctx context.Context, cfg config.Config) (http.Handler, func(), error) {
-	// DB pool
+func openDB(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
 	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
 	if err != nil {
-		return nil, func() {}, err
+		return nil, err
 	}
 	if err := pool.Ping(ctx); err != nil {
 		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// services: dependency graph bersama untuk server HTTP dan subcommand CLI.
+type services struct {
+	users     contract.UserRepository
+	signer    accessTokenSigner
+	sessions  contract.SessionService
+	verify    contract.VerificationService
+	passwords contract.PasswordService
+	webauthn  contract.WebAuthnService
+	mfa       contract.MFAService
+	user      contract.UserService
+	admin     contract.AdminUserService
+	roles     contract.RoleService
+}
+
+func buildHTTP(ctx context.Context, cfg config.Config) (http.Handler, func(), error) {
+	svc, cleanup, err := buildServices(ctx, cfg)
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	// handlers
+	userH := handlers.NewUserHandler(svc.user)
+	authH := handlers.NewAuthHandler(svc.sessions, svc.verify, svc.passwords)
+	jwksH := handlers.NewJWKSHandler(svc.signer)
+	mfaH := handlers.NewMFAHandler(svc.mfa)
+	webauthnH := handlers.NewWebAuthnHandler(svc.webauthn)
+	adminUserH := handlers.NewAdminUserHandler(svc.admin)
+	adminRoleH := handlers.NewAdminRoleHandler(svc.roles)
+
+	// routers
+	var handler http.Handler = routers.InitRouter(userH, authH, jwksH, mfaH, webauthnH, adminUserH, adminRoleH,
+		middleware.NewAuthenticator(svc.sessions), middleware.NewAuthorization(usecase.NewAuthorizer()))
+	if cfg.TrustProxy {
+		// hanya aman kalau cp-api berada di belakang reverse proxy yang menimpa header ini
+		handler = chimw.RealIP(handler)
+	}
+	return handler, cleanup, nil
+}
+
+func buildServices(ctx context.Context, cfg config.Config) (*services, func(), error) {
+	// DB pool
+	pool, err := openDB(ctx, cfg)
+	if err != nil {
 		return nil, func() {}, err
 	}
 
@@ -31,20 +89,193 @@ func buildHTTP(ctx context.Context, cfg config.Config) (http.Handler, func(), er
 
 	// repos
 	userRepo := pg.NewUserRepositoryPG(pool)
+	refreshRepo := pg.NewRefreshTokenRepositoryPG(pool)
+	revocations := pg.NewRevocationStorePG(pool)
+	actionTokens := pg.NewActionTokenRepositoryPG(pool)
+	loginAttempts := pg.NewLoginAttemptStorePG(pool)
+	totpRepo := pg.NewTOTPRepositoryPG(pool)
+	mfaChallenges := pg.NewMFAChallengeRepositoryPG(pool)
+	recoveryCodes := pg.NewRecoveryCodeRepositoryPG(pool)
+	webauthnCreds := pg.NewWebAuthnCredentialRepositoryPG(pool)
+	webauthnChallenges := pg.NewWebAuthnChallengeRepositoryPG(pool)
+	otpCodes := pg.NewOTPCodeRepositoryPG(pool)
+	otpMethods := pg.NewOTPMethodRepositoryPG(pool)
+	passwordHistory := pg.NewPasswordHistoryRepositoryPG(pool)
+	roleRepo := pg.NewRoleRepositoryPG(pool)
 
 	// adapters
 	clock := system.Clock{}
 	idgen := system.IDGen{}
-	hasher := security.BcryptHasher{}
-	signer := security.NewJWTSigner(cfg.JWTSecret, cfg.JWTTTL)
+	hasher, err := buildHasher(cfg)
+	if err != nil {
+		pool.Close()
+		return nil, func() {}, err
+	}
+	signer, err := buildSigner(cfg)
+	if err != nil {
+		pool.Close()
+		return nil, func() {}, err
+	}
+	tokens := security.OpaqueTokens{}
+	var mailer contract.EmailSender = notify.LogEmailSender{}
+	if cfg.MailOutboxDir != "" {
+		mailer = notify.FileEmailSender{Dir: cfg.MailOutboxDir}
+	}
+	var sms contract.SMSSender = notify.LogSMSSender{}
+	if cfg.SMSOutboxDir != "" {
+		sms = notify.FileSMSSender{Dir: cfg.SMSOutboxDir}
+	}
+
+	box, err := buildSecretBox(cfg)
+	if err != nil {
+		pool.Close()
+		return nil, func() {}, err
+	}
+	totp := security.TOTP{Issuer: cfg.MFAIssuer}
+	passkeys := webauthn.NewVerifier(cfg.WebAuthnRPID, cfg.WebAuthnOrigins)
+
+	policy, closeCorpus, err := buildPasswordPolicy(cfg, hasher, passwordHistory)
+	if err != nil {
+		pool.Close()
+		return nil, func() {}, err
+	}
+	cleanup = func() {
+		closeCorpus()
+		pool.Close()
+	}
 
 	// usecases
-	userSvc := usecase.NewUserService(userRepo, clock, idgen, hasher, signer)
+	sessionSvc := usecase.NewSessionService(userRepo, refreshRepo, revocations, clock, idgen, signer, signer, tokens, cfg.RefreshTTL, roleRepo)
+	verifySvc := usecase.NewVerificationService(userRepo, actionTokens, mailer, clock, idgen, tokens, cfg.EmailVerifyTTL, cfg.EmailVerifyURL)
+	passwordSvc := usecase.NewPasswordService(userRepo, actionTokens, mailer, hasher, policy, sessionSvc, clock, idgen, tokens, cfg.PasswordResetTTL, cfg.PasswordResetURL)
+	var registerVerify contract.VerificationService
+	if cfg.RequireEmailVerification {
+		registerVerify = verifySvc
+	}
+	guard := usecase.NewLoginGuard(loginAttempts, userRepo, clock, usecase.LoginGuardConfig{
+		Window:        cfg.LoginFailureWindow,
+		DelayAfter:    cfg.LoginDelayAfter,
+		LockThreshold: cfg.LoginLockThreshold,
+		LockDuration:  cfg.LoginLockDuration,
+		IPMaxFailures: cfg.LoginIPMaxFailures,
+	})
+	otpSvc := usecase.NewOTPService(otpCodes, otpMethods, loginAttempts, mailer, sms, security.NumericCodes{}, clock, idgen, tokens, cfg.MFAIssuer)
+	webauthnSvc := usecase.NewWebAuthnService(userRepo, webauthnCreds, webauthnChallenges, passkeys, sessionSvc, clock, idgen, cfg.WebAuthnRPName,
+		totpRepo, otpSvc)
+	mfaSvc := usecase.NewMFAService(userRepo, totpRepo, mfaChallenges, sessionSvc, totp, box, clock, idgen, tokens,
+		recoveryCodes, security.RecoveryCodes{}, hasher, webauthnSvc, otpSvc, guard)
+	userSvc := usecase.NewUserService(userRepo, clock, idgen, hasher, policy, sessionSvc, registerVerify, guard, mfaSvc)
+	adminSvc := usecase.NewAdminUserService(userRepo, roleRepo, clock, idgen, hasher, policy, sessionSvc, security.TempPasswords{}, guard)
+	roleSvc := usecase.NewRoleService(roleRepo, userRepo, clock, idgen, sessionSvc)
 
-	// handlers
-	userH := handlers.NewUserHandler(userSvc)
+	return &services{
+		users:     userRepo,
+		signer:    signer,
+		sessions:  sessionSvc,
+		verify:    verifySvc,
+		passwords: passwordSvc,
+		webauthn:  webauthnSvc,
+		mfa:       mfaSvc,
+		user:      userSvc,
+		admin:     adminSvc,
+		roles:     roleSvc,
+	}, cleanup, nil
+}
 
-	// routers
-	handler := routers.InitRouter(userH)
-	return handler, cleanup, nil
+type accessTokenSigner interface {
+	contract.TokenSigner
+	contract.TokenVerifier
+	contract.JWKSProvider
+}
+
+// buildSigner: keyring asimetris kalau JWT_KEYS_DIR di-set, selain itu HS256.
+func buildSigner(cfg config.Config) (accessTokenSigner, error) {
+	if cfg.JWTKeysDir != "" {
+		return security.LoadKeyring(cfg.JWTKeysDir, cfg.JWTActiveKID, cfg.JWTTTL)
+	}
+	if len(cfg.JWTSecret) < security.MinJWTSecretLen {
+		return nil, fmt.Errorf("JWT_SECRET must be at least %d bytes (or set JWT_KEYS_DIR)", security.MinJWTSecretLen)
+	}
+	return security.NewJWTSigner(cfg.JWTSecret, cfg.JWTTTL), nil
+}
+
+// buildHasher: hash baru pakai PASSWORD_HASH_ALG, hash lama dengan algoritma
+// lain tetap bisa diverifikasi dan di-upgrade saat login.
+func buildHasher(cfg config.Config) (contract.PasswordHasher, error) {
+	// hash dengan parameter di luar batas ini ditolak saat verifikasi
+	if cfg.Argon2Memory < 8 || cfg.Argon2Memory > security.MaxArgon2Memory {
+		return nil, fmt.Errorf("ARGON2_MEMORY_KIB must be between 8 and %d", security.MaxArgon2Memory)
+	}
+	if cfg.Argon2Iterations < 1 || cfg.Argon2Iterations > security.MaxArgon2Iterations {
+		return nil, fmt.Errorf("ARGON2_ITERATIONS must be between 1 and %d", security.MaxArgon2Iterations)
+	}
+	if cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > security.MaxArgon2Parallelism {
+		return nil, fmt.Errorf("ARGON2_PARALLELISM must be between 1 and %d", security.MaxArgon2Parallelism)
+	}
+	alg := domain.PasswordAlg(cfg.PasswordHashAlg)
+	hashers := map[domain.PasswordAlg]contract.PasswordHasher{
+		domain.AlgArgon2id: security.Argon2idHasher{Params: security.Argon2Params{
+			Memory:      uint32(cfg.Argon2Memory),
+			Iterations:  uint32(cfg.Argon2Iterations),
+			Parallelism: uint8(cfg.Argon2Parallelism),
+		}},
+		domain.AlgBcrypt: security.BcryptHasher{Cost: cfg.BcryptCost},
+		domain.AlgScrypt: security.ScryptHasher{},
+	}
+	if _, ok := hashers[alg]; !ok {
+		return nil, fmt.Errorf("PASSWORD_HASH_ALG: unsupported algorithm %q", alg)
+	}
+	return security.NewMultiHasher(alg, hashers), nil
+}
+
+// buildPasswordPolicy: batas 72 byte otomatis kalau hash baru pakai bcrypt
+// (bcrypt mengabaikan byte setelahnya).
+func buildPasswordPolicy(cfg config.Config, hasher contract.PasswordHasher, history contract.PasswordHistoryRepository) (contract.PasswordPolicy, func(), error) {
+	maxBytes := cfg.PasswordMaxBytes
+	if domain.PasswordAlg(cfg.PasswordHashAlg) == domain.AlgBcrypt && (maxBytes == 0 || maxBytes > 72) {
+		maxBytes = 72
+	}
+	var (
+		breached contract.BreachedPasswordChecker
+		closeFn  = func() {}
+	)
+	if cfg.PasswordBreachedCorpus != "" {
+		corpus, err := security.LoadBreachedCorpus(cfg.PasswordBreachedCorpus, cfg.PasswordBreachedMinCount)
+		if err != nil {
+			return nil, nil, err
+		}
+		breached = corpus
+		closeFn = func() { _ = corpus.Close() }
+	}
+	policy := usecase.NewPasswordPolicy(usecase.PasswordPolicyConfig{
+		MinLength:            cfg.PasswordMinLength,
+		MaxLength:            cfg.PasswordMaxLength,
+		MaxBytes:             maxBytes,
+		MinCharClasses:       cfg.PasswordMinClasses,
+		DisallowPersonalInfo: cfg.PasswordDisallowPersonal,
+		HistorySize:          cfg.PasswordHistory,
+	}, hasher, history, breached)
+	return policy, closeFn, nil
+}
+
+// buildSecretBox: key dari MFA_ENCRYPTION_KEY (base64, 32 byte), wajib diisi.
+// Key acak sementara hanya kalau MFA_EPHEMERAL_KEY=true (dev): secret TOTP tidak
+// terbaca lagi setelah restart dan tidak bisa dibagi antar replica.
+func buildSecretBox(cfg config.Config) (*security.SecretBox, error) {
+	if cfg.MFAEncryptionKey == "" {
+		if !cfg.MFAEphemeralKey {
+			return nil, errors.New("MFA_ENCRYPTION_KEY is required (set MFA_EPHEMERAL_KEY=true to use a throwaway key in development)")
+		}
+		log.Println("[cp-api] WARNING: MFA_ENCRYPTION_KEY not set, using an ephemeral key (MFA_EPHEMERAL_KEY=true, dev only)")
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		return security.NewSecretBox(key)
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.MFAEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("MFA_ENCRYPTION_KEY: %w", err)
+	}
+	return security.NewSecretBox(key)
 }
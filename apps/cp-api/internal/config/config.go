@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,6 +13,48 @@ type Config struct {
 	ShutdownTimeout time.Duration // ex: 10s
 	JWTSecret       string        // ← baru
 	JWTTTL          time.Duration // ← baru
+	RefreshTTL      time.Duration // ex: 720h
+	JWTKeysDir      string        // kosong = HS256 pakai JWTSecret
+	JWTActiveKID    string        // kid untuk sign; kosong kalau hanya ada satu private key
+
+	PasswordHashAlg   string // hash baru: argon2id (default), bcrypt, scrypt
+	Argon2Memory      int    // KiB
+	Argon2Iterations  int
+	Argon2Parallelism int
+	BcryptCost        int
+
+	PasswordMinLength        int
+	PasswordMaxLength        int
+	PasswordMaxBytes         int    // 0 = otomatis (72 kalau hash baru pakai bcrypt)
+	PasswordMinClasses       int    // 0-4: huruf kecil, huruf besar, angka, simbol
+	PasswordDisallowPersonal bool   // tolak password yang memuat email/nama
+	PasswordHistory          int    // tolak N password terakhir
+	PasswordBreachedCorpus   string // file Pwned Passwords (SHA1:COUNT, terurut); kosong = nonaktif
+	PasswordBreachedMinCount int
+
+	RequireEmailVerification bool          // user baru PENDING sampai email diverifikasi
+	EmailVerifyTTL           time.Duration // ex: 24h
+	EmailVerifyURL           string        // link di email, token ditambahkan sebagai ?token=
+	MailOutboxDir            string        // kosong = email hanya ditulis ke log
+	SMSOutboxDir             string        // kosong = SMS hanya ditulis ke log
+
+	PasswordResetTTL time.Duration // ex: 30m
+	PasswordResetURL string        // link di email, token ditambahkan sebagai ?token=
+
+	TrustProxy         bool          // pakai X-Forwarded-For / X-Real-IP untuk IP client
+	LoginFailureWindow time.Duration // ex: 15m
+	LoginDelayAfter    int           // delay progresif setelah N gagal
+	LoginLockThreshold int           // akun dikunci setelah N gagal
+	LoginLockDuration  time.Duration // auto-unlock, ex: 15m
+	LoginIPMaxFailures int           // gagal per IP dalam window
+
+	MFAEncryptionKey string // base64, 32 byte (AES-256) untuk secret TOTP; wajib kecuali MFAEphemeralKey
+	MFAEphemeralKey  bool   // dev only: tanpa MFAEncryptionKey pakai key acak per proses
+	MFAIssuer        string // nama di aplikasi authenticator
+
+	WebAuthnRPID    string   // domain tanpa skema, ex: xeed.id
+	WebAuthnRPName  string   // nama yang ditampilkan browser
+	WebAuthnOrigins []string // origin frontend yang diizinkan, ex: https://app.xeed.id
 }
 
 func FromEnv() Config {
@@ -22,12 +66,64 @@ func FromEnv() Config {
 	}
 
 	ttl, _ := time.ParseDuration(getenv("JWT_TTL", "15m"))
+	refreshTTL, _ := time.ParseDuration(getenv("REFRESH_TTL", "720h"))
+	verifyTTL, _ := time.ParseDuration(getenv("EMAIL_VERIFY_TTL", "24h"))
+	resetTTL, _ := time.ParseDuration(getenv("PASSWORD_RESET_TTL", "30m"))
+	trustProxy, _ := strconv.ParseBool(getenv("TRUST_PROXY", "false"))
+	failWindow, _ := time.ParseDuration(getenv("LOGIN_FAILURE_WINDOW", "15m"))
+	lockDuration, _ := time.ParseDuration(getenv("LOGIN_LOCK_DURATION", "15m"))
+	requireVerify, _ := strconv.ParseBool(getenv("REQUIRE_EMAIL_VERIFICATION", "false"))
+	disallowPersonal, _ := strconv.ParseBool(getenv("PASSWORD_DISALLOW_PERSONAL", "true"))
+	mfaEphemeral, _ := strconv.ParseBool(getenv("MFA_EPHEMERAL_KEY", "false"))
 
 	return Config{
 		Addr:            ":" + port,
 		DatabaseURL:     dsn,
 		ShutdownTimeout: 10 * time.Second,
+		JWTSecret:       os.Getenv("JWT_SECRET"),
 		JWTTTL:          ttl,
+		RefreshTTL:      refreshTTL,
+		JWTKeysDir:      os.Getenv("JWT_KEYS_DIR"),
+		JWTActiveKID:    os.Getenv("JWT_ACTIVE_KID"),
+
+		PasswordHashAlg:   getenv("PASSWORD_HASH_ALG", "argon2id"),
+		Argon2Memory:      getenvInt("ARGON2_MEMORY_KIB", 19*1024),
+		Argon2Iterations:  getenvInt("ARGON2_ITERATIONS", 2),
+		Argon2Parallelism: getenvInt("ARGON2_PARALLELISM", 1),
+		BcryptCost:        getenvInt("BCRYPT_COST", 10),
+
+		PasswordMinLength:        getenvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxLength:        getenvInt("PASSWORD_MAX_LENGTH", 128),
+		PasswordMaxBytes:         getenvInt("PASSWORD_MAX_BYTES", 0),
+		PasswordMinClasses:       getenvInt("PASSWORD_MIN_CLASSES", 0),
+		PasswordDisallowPersonal: disallowPersonal,
+		PasswordHistory:          getenvInt("PASSWORD_HISTORY", 5),
+		PasswordBreachedCorpus:   os.Getenv("PASSWORD_BREACHED_CORPUS"),
+		PasswordBreachedMinCount: getenvInt("PASSWORD_BREACHED_MIN_COUNT", 1),
+
+		RequireEmailVerification: requireVerify,
+		EmailVerifyTTL:           verifyTTL,
+		EmailVerifyURL:           os.Getenv("EMAIL_VERIFY_URL"),
+		MailOutboxDir:            os.Getenv("MAIL_OUTBOX_DIR"),
+		SMSOutboxDir:             os.Getenv("SMS_OUTBOX_DIR"),
+
+		PasswordResetTTL: resetTTL,
+		PasswordResetURL: os.Getenv("PASSWORD_RESET_URL"),
+
+		TrustProxy:         trustProxy,
+		LoginFailureWindow: failWindow,
+		LoginDelayAfter:    getenvInt("LOGIN_DELAY_AFTER", 3),
+		LoginLockThreshold: getenvInt("LOGIN_LOCK_THRESHOLD", 10),
+		LoginLockDuration:  lockDuration,
+		LoginIPMaxFailures: getenvInt("LOGIN_IP_MAX_FAILURES", 100),
+
+		MFAEncryptionKey: os.Getenv("MFA_ENCRYPTION_KEY"),
+		MFAEphemeralKey:  mfaEphemeral,
+		MFAIssuer:        getenv("MFA_ISSUER", "Xeed"),
+
+		WebAuthnRPID:    getenv("WEBAUTHN_RP_ID", "localhost"),
+		WebAuthnRPName:  getenv("WEBAUTHN_RP_NAME", "Xeed"),
+		WebAuthnOrigins: strings.Split(getenv("WEBAUTHN_ORIGINS", "http://localhost:3000"), ","),
 	}
 }
 
@@ -37,3 +133,10 @@ func getenv(k, def string) string {
 	}
 	return def
 }
+
+func getenvInt(k string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(k)); err == nil {
+		return v
+	}
+	return def
+}
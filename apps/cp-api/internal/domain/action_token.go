@@ -0,0 +1,27 @@
+// apps/cp-api/internal/domain/action_token.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenPurpose membedakan token sekali-pakai yang dikirim ke user (email, dsb)
+type TokenPurpose string
+
+const (
+	PurposeEmailVerify   TokenPurpose = "email_verify"
+	PurposePasswordReset TokenPurpose = "password_reset"
+)
+
+// ActionToken: token opaque sekali-pakai dengan masa berlaku; hanya hash yang disimpan.
+type ActionToken struct {
+	TokenID   uuid.UUID
+	UserID    uuid.UUID
+	Purpose   TokenPurpose
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
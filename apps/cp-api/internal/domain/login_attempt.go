@@ -0,0 +1,17 @@
+// apps/cp-api/internal/domain/login_attempt.go
+package domain
+
+import "time"
+
+// LoginAttempts: counter gagal login per key (akun / IP).
+type LoginAttempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time // diisi saat akun dikunci otomatis
+}
+
+// Active: counter masih dalam window (kalau tidak, dianggap nol)
+func (a LoginAttempts) Active(now time.Time, window time.Duration) bool {
+	return a.Failures > 0 && now.Sub(a.LastFailureAt) < window
+}
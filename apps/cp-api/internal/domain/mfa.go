@@ -0,0 +1,63 @@
+// apps/cp-api/internal/domain/mfa.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TOTPCredential: secret TOTP user (terenkripsi). Aktif setelah ConfirmedAt diisi.
+type TOTPCredential struct {
+	UserID       uuid.UUID
+	SecretEnc    []byte
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 // anti-replay: kode dengan step <= ini ditolak
+	CreatedAt    time.Time
+}
+
+func (c TOTPCredential) IsConfirmed() bool { return c.ConfirmedAt != nil }
+
+// MFAChallenge: langkah kedua login. Token opaque dikembalikan ke client
+// sebagai pengganti access token; hanya hash yang disimpan.
+type MFAChallenge struct {
+	ChallengeID uuid.UUID
+	UserID      uuid.UUID
+	TokenHash   string
+	Attempts    int
+	ExpiresAt   time.Time
+	ConsumedAt  *time.Time
+	CreatedAt   time.Time
+}
+
+// RecoveryCode: kode cadangan sekali-pakai pengganti kode TOTP. Selector untuk
+// mencari baris; bagian secret hanya disimpan hash-nya.
+type RecoveryCode struct {
+	CodeID    uuid.UUID
+	UserID    uuid.UUID
+	Selector  string
+	CodeHash  string
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type OTPPurpose string
+
+const (
+	OTPEnroll OTPPurpose = "enroll" // verifikasi tujuan (email/HP) saat mengaktifkan metode
+	OTPLogin  OTPPurpose = "login"  // langkah kedua login
+)
+
+// OTPCode: kode numerik sekali pakai yang dikirim via email/SMS (hanya hash yang disimpan).
+type OTPCode struct {
+	CodeID      uuid.UUID
+	UserID      uuid.UUID
+	ChallengeID *uuid.UUID // MFAChallenge terkait (purpose login)
+	Method      MFAMethod
+	Purpose     OTPPurpose
+	CodeHash    string
+	Attempts    int
+	ExpiresAt   time.Time
+	ConsumedAt  *time.Time
+	CreatedAt   time.Time
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordHistoryEntry: hash password yang pernah dipakai user (untuk larangan reuse).
+type PasswordHistoryEntry struct {
+	UserID       uuid.UUID
+	PasswordHash string
+	PasswordAlg  PasswordAlg
+	CreatedAt    time.Time
+}
@@ -0,0 +1,28 @@
+// apps/cp-api/internal/domain/principal.go
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope khusus token terbatas: hanya boleh ganti password (User.MustChangePassword)
+const ScopePasswordChangeOnly = "password:change-only"
+
+// Principal: identitas pemanggil yang sudah terautentikasi (dari access token).
+type Principal struct {
+	UserID    uuid.UUID
+	Email     string
+	Scopes    []string
+	SessionID uuid.UUID
+	TokenID   uuid.UUID // jti
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+func (p Principal) HasScope(scope string) bool { return slices.Contains(p.Scopes, scope) }
+
+// IsRestricted: token terbatas yang hanya boleh dipakai untuk ganti password
+func (p Principal) IsRestricted() bool { return p.HasScope(ScopePasswordChangeOnly) }
@@ -0,0 +1,27 @@
+// apps/cp-api/internal/domain/refresh_token.go
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken: token opaque yang disimpan server-side (hanya hash-nya).
+// Satu "family" = satu sesi login; setiap rotasi menghasilkan token baru
+// dengan FamilyID yang sama.
+type RefreshToken struct {
+	TokenID    uuid.UUID
+	FamilyID   uuid.UUID
+	UserID     uuid.UUID
+	TokenHash  string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	UsedAt     *time.Time // diisi saat token dirotasi
+	RevokedAt  *time.Time
+	ReplacedBy *uuid.UUID
+}
+
+func (t RefreshToken) IsExpired(now time.Time) bool { return !now.Before(t.ExpiresAt) }
+func (t RefreshToken) IsUsed() bool                 { return t.UsedAt != nil }
+func (t RefreshToken) IsRevoked() bool              { return t.RevokedAt != nil }
@@ -0,0 +1,40 @@
+// apps/cp-api/internal/domain/role.go
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permission "<resource>:<action>", dibawa sebagai scope di access token.
+// Tambah permission baru = konstanta di sini + baris seed di tabel "Permission".
+const (
+	PermUsersRead  = "users:read"
+	PermUsersWrite = "users:write"
+	PermRolesRead  = "roles:read"
+	PermRolesWrite = "roles:write"
+)
+
+// Permissions: semua permission yang dikenal sistem, urut.
+var Permissions = []string{PermRolesRead, PermRolesWrite, PermUsersRead, PermUsersWrite}
+
+func IsPermission(s string) bool { return slices.Contains(Permissions, s) }
+
+// RoleAdmin: role bawaan dengan semua permission.
+const RoleAdmin = "admin"
+
+type Role struct {
+	RoleID      uuid.UUID
+	Name        string
+	Description *string
+	Permissions []string // urut, tanpa duplikat
+	IsSystem    bool     // role bawaan: tidak bisa diubah/dihapus
+	CreatedAt   time.Time
+	CreatedBy   *uuid.UUID
+	UpdatedAt   time.Time
+	UpdatedBy   *uuid.UUID
+}
+
+func (r Role) Grants(permission string) bool { return slices.Contains(r.Permissions, permission) }
@@ -39,6 +39,11 @@ const (
 	AlgNone     PasswordAlg = "none"
 )
 
+var (
+	ErrInvalidEmail = errors.New("invalid email")
+	ErrInvalidPhone = errors.New("invalid phone (E.164)")
+)
+
 var rxEmail = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
 var rxE164 = regexp.MustCompile(`^\+\d{6,15}$`)
 
@@ -84,7 +89,7 @@ type User struct {
 
 func NewUser(email, displayName string) (User, error) {
 	if !rxEmail.MatchString(email) {
-		return User{}, errors.New("invalid email")
+		return User{}, ErrInvalidEmail
 	}
 	u := User{
 		UserID:      uuid.New(),
@@ -103,7 +108,7 @@ func NewUser(email, displayName string) (User, error) {
 
 func (u *User) ChangeEmail(newEmail string) error {
 	if !rxEmail.MatchString(newEmail) {
-		return errors.New("invalid email")
+		return ErrInvalidEmail
 	}
 	u.Email = newEmail
 	u.EmailVerifiedAt = nil
@@ -114,7 +119,7 @@ func (u *User) VerifyEmail(at time.Time) { u.EmailVerifiedAt = &at }
 
 func (u *User) SetPhoneE164(e164 string) error {
 	if !rxE164.MatchString(e164) {
-		return errors.New("invalid phone (E.164)")
+		return ErrInvalidPhone
 	}
 	u.PhoneE164 = &e164
 	u.PhoneVerifiedAt = nil
@@ -129,6 +134,13 @@ func (u *User) SetPasswordHash(hash string, at time.Time, mustChange bool) {
 	u.MustChangePassword = mustChange
 }
 
+// RehashPassword mengganti hash untuk password yang sama (upgrade algoritma),
+// jadi PasswordUpdatedAt tidak berubah.
+func (u *User) RehashPassword(hash string, alg PasswordAlg) {
+	u.PasswordHash = &hash
+	u.PasswordAlg = alg
+}
+
 func (u *User) RequirePasswordChange() { u.MustChangePassword = true }
 
 // Status transitions (kontrol sesuai enum DB)
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebAuthnCredential: passkey / security key milik user (WebAuthn Level 2).
+type WebAuthnCredential struct {
+	CredentialID []byte
+	UserID       uuid.UUID
+	Name         string
+	PublicKey    []byte // COSE_Key dari authenticator
+	SignCount    uint32
+	AAGUID       []byte
+	Transports   []string
+	CreatedAt    time.Time
+	LastUsedAt   *time.Time
+}
+
+// SignCountOK: counter harus naik setiap assertion. Counter yang tidak naik
+// menandakan kemungkinan authenticator dikloning. Authenticator yang tidak
+// punya counter (selalu 0, ex: passkey tersinkron) dikecualikan.
+func (c WebAuthnCredential) SignCountOK(next uint32) bool {
+	if next == 0 && c.SignCount == 0 {
+		return true
+	}
+	return next > c.SignCount
+}
+
+type WebAuthnPurpose string
+
+const (
+	WebAuthnRegister WebAuthnPurpose = "register"
+	WebAuthnLogin    WebAuthnPurpose = "login" // passkey tanpa password
+	WebAuthnMFA      WebAuthnPurpose = "mfa"   // faktor kedua setelah password
+)
+
+// WebAuthnChallenge: challenge ceremony yang sedang berjalan (sekali pakai).
+type WebAuthnChallenge struct {
+	ChallengeID uuid.UUID
+	UserID      *uuid.UUID // nil untuk login passkey (user belum diketahui)
+	Purpose     WebAuthnPurpose
+	Challenge   []byte
+	ExpiresAt   time.Time
+	ConsumedAt  *time.Time
+	CreatedAt   time.Time
+}
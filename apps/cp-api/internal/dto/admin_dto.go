@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Password kosong = dibuatkan password sementara (dikembalikan sekali di respon)
+// dan user wajib menggantinya saat login pertama.
+
+type AdminCreateUserRequest struct {
+	Email              string  `json:"email"`
+	Password           string  `json:"password,omitempty"`
+	DisplayName        *string `json:"displayName,omitempty"`
+	MustChangePassword bool    `json:"mustChangePassword,omitempty"`
+	EmailVerified      bool    `json:"emailVerified,omitempty"` // operator sudah memastikan email milik user
+}
+
+type AdminCreateUserResponse struct {
+	User              AdminUserResponse `json:"user"`
+	TemporaryPassword string            `json:"temporaryPassword,omitempty"`
+}
+
+type AdminResetPasswordRequest struct {
+	Password           string `json:"password,omitempty"`
+	MustChangePassword bool   `json:"mustChangePassword,omitempty"` // selalu true untuk password sementara
+}
+
+type AdminResetPasswordResponse struct {
+	TemporaryPassword string `json:"temporaryPassword,omitempty"`
+}
+
+// Field nil = tidak diubah. Ganti email mereset status verifikasi kecuali EmailVerified=true.
+type AdminUpdateUserRequest struct {
+	UpdateProfileRequest
+	Email         *string `json:"email,omitempty"`
+	EmailVerified *bool   `json:"emailVerified,omitempty"`
+}
+
+// Tampilan user untuk admin: UserResponse + status akun & audit
+type AdminUserResponse struct {
+	UserResponse
+	EmailVerified      bool       `json:"emailVerified"`
+	PhoneVerified      bool       `json:"phoneVerified"`
+	MustChangePassword bool       `json:"mustChangePassword"`
+	MFAEnrolled        bool       `json:"mfaEnrolled"`
+	IsServiceAccount   bool       `json:"isServiceAccount"`
+	LastLoginAt        *time.Time `json:"lastLoginAt,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	CreatedBy          *uuid.UUID `json:"createdBy,omitempty"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+	UpdatedBy          *uuid.UUID `json:"updatedBy,omitempty"`
+}
+
+// AdminListUsersRequest: query GET /admin/users. Filter harus sama di setiap
+// halaman; Cursor = nextCursor dari respons sebelumnya.
+type AdminListUsersRequest struct {
+	Status         string
+	ServiceAccount *bool
+	EmailVerified  *bool
+	EmailPrefix    string
+	Query          string
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	Sort           string // createdAt | -createdAt
+	Limit          int
+	Cursor         string
+}
+
+type AdminUserListResponse struct {
+	Items      []AdminUserResponse `json:"items"`
+	NextCursor string              `json:"nextCursor,omitempty"` // kosong = halaman terakhir
+}
+
+func ToAdminUserResponse(u domain.User) AdminUserResponse {
+	return AdminUserResponse{
+		UserResponse:       ToUserResponse(u),
+		EmailVerified:      u.EmailVerifiedAt != nil,
+		PhoneVerified:      u.PhoneVerifiedAt != nil,
+		MustChangePassword: u.MustChangePassword,
+		MFAEnrolled:        u.MFAEnrolled,
+		IsServiceAccount:   u.IsServiceAccount,
+		LastLoginAt:        u.LastLoginAt,
+		CreatedAt:          u.CreatedAt,
+		CreatedBy:          u.CreatedBy,
+		UpdatedAt:          u.UpdatedAt,
+		UpdatedBy:          u.UpdatedBy,
+	}
+}
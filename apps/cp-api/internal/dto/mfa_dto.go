@@ -0,0 +1,53 @@
+package dto
+
+type TOTPEnrollResponse struct {
+	Secret     string `json:"secret"`     // base32, untuk input manual
+	OTPAuthURI string `json:"otpauthUri"` // untuk QR code
+}
+
+type TOTPConfirmRequest struct {
+	Code string `json:"code"`
+}
+
+// Isi salah satu: code (+ method "totp"/"sms"/"email", default totp),
+// recoveryCode, atau webauthn (assertion security key)
+type MFAVerifyRequest struct {
+	MFAToken     string             `json:"mfaToken"`
+	Method       string             `json:"method,omitempty"`
+	Code         string             `json:"code,omitempty"`
+	RecoveryCode string             `json:"recoveryCode,omitempty"`
+	WebAuthn     *WebAuthnAssertion `json:"webauthn,omitempty"`
+	ClientIP     string             `json:"-"` // diisi handler, untuk LoginGuard
+}
+
+// Recovery code hanya ditampilkan sekali; server hanya menyimpan hash-nya.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// Minta options WebAuthn untuk langkah MFA (mfaToken dari /auth/login)
+type MFAWebAuthnBeginRequest struct {
+	MFAToken string `json:"mfaToken"`
+}
+
+// Aktifkan MFA via kode email/SMS: method "email" atau "sms"
+type MFAOTPEnrollRequest struct {
+	Method string `json:"method"`
+}
+
+type MFAOTPConfirmRequest struct {
+	Method string `json:"method"`
+	Code   string `json:"code"`
+}
+
+// Minta kode OTP dikirim saat langkah MFA login
+type MFAOTPSendRequest struct {
+	MFAToken string `json:"mfaToken"`
+	Method   string `json:"method"`
+}
+
+type OTPSentResponse struct {
+	Method      string `json:"method"`
+	Destination string `json:"destination"` // disamarkan, ex: "+62******789"
+	ExpiresIn   int    `json:"expiresIn"`   // detik
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name"`
+	Description *string  `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// Field nil = tidak diubah; Permissions mengganti seluruh set.
+type UpdateRoleRequest struct {
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"` // "" = hapus
+	Permissions *[]string `json:"permissions,omitempty"`
+}
+
+type RoleResponse struct {
+	RoleID      uuid.UUID `json:"roleId"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	Permissions []string  `json:"permissions"`
+	IsSystem    bool      `json:"isSystem"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type RoleListResponse struct {
+	Items []RoleResponse `json:"items"`
+}
+
+type PermissionListResponse struct {
+	Items []string `json:"items"`
+}
+
+func ToRoleResponse(r domain.Role) RoleResponse {
+	perms := r.Permissions
+	if perms == nil {
+		perms = []string{}
+	}
+	return RoleResponse{
+		RoleID:      r.RoleID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: perms,
+		IsSystem:    r.IsSystem,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+func ToRoleListResponse(roles []domain.Role) RoleListResponse {
+	out := RoleListResponse{Items: make([]RoleResponse, len(roles))}
+	for i, r := range roles {
+		out.Items[i] = ToRoleResponse(r)
+	}
+	return out
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"xeed/apps/cp-api/internal/domain"
+
+	"github.com/google/uuid"
+)
 
 // DTO murni untuk transport layer (HTTP JSON, gRPC, dsb).
 // Tidak bawa logic bisnis, hanya data binding.
@@ -19,6 +23,7 @@ type RegisterUserRequest struct {
 type UserResponse struct {
 	UserID      uuid.UUID `json:"userId"`
 	DisplayName *string   `json:"displayName,omitempty"`
+	AvatarURL   *string   `json:"avatarUrl,omitempty"`
 	PhoneE164   *string   `json:"phoneE164,omitempty"`
 	Email       string    `json:"email"`
 	Status      string    `json:"status"`
@@ -26,12 +31,58 @@ type UserResponse struct {
 	Timezone    string    `json:"timezone"`
 }
 
+// Respon GET /me: profil + status keamanan akun
+type MeResponse struct {
+	UserResponse
+	EmailVerified          bool    `json:"emailVerified"`
+	MFAEnrolled            bool    `json:"mfaEnrolled"`
+	MFADefaultMethod       *string `json:"mfaDefaultMethod,omitempty"`
+	RecoveryCodesRemaining *int    `json:"recoveryCodesRemaining,omitempty"` // hanya kalau MFA aktif
+}
+
+func ToUserResponse(u domain.User) UserResponse {
+	return UserResponse{
+		UserID:      u.UserID,
+		Email:       u.Email,
+		DisplayName: u.DisplayName,
+		AvatarURL:   u.AvatarURL,
+		PhoneE164:   u.PhoneE164,
+		Locale:      u.Locale,
+		Timezone:    u.Timezone,
+		Status:      string(u.Status),
+	}
+}
+
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	ClientIP string `json:"-"` // diisi handler dari koneksi, bukan dari body
 }
 
 type LoginResponse struct {
-	AccessToken string       `json:"accessToken"`
-	User        UserResponse `json:"user"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"` // kosong untuk token terbatas
+	// true: accessToken hanya bisa dipakai untuk POST /api/v1/me/password
+	MustChangePassword bool `json:"mustChangePassword,omitempty"`
+
+	// Langkah kedua login: kirim mfaToken + kode ke POST /api/v1/auth/mfa/verify
+	MFARequired bool     `json:"mfaRequired,omitempty"`
+	MFAToken    string   `json:"mfaToken,omitempty"`
+	MFAMethods  []string `json:"mfaMethods,omitempty"`
+
+	User *UserResponse `json:"user,omitempty"` // kosong selama MFA belum selesai
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// Partial update: field nil = tidak diubah, string kosong = dihapus (nullable field).
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"displayName,omitempty"`
+	AvatarURL   *string `json:"avatarUrl,omitempty"`
+	PhoneE164   *string `json:"phoneE164,omitempty"`
+	Locale      *string `json:"locale,omitempty"`
+	Timezone    *string `json:"timezone,omitempty"`
 }
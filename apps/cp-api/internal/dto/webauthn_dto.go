@@ -0,0 +1,84 @@
+package dto
+
+import "time"
+
+// Semua field biner di-encode base64url tanpa padding, sesuai JSON WebAuthn di browser.
+
+type WebAuthnRP struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUser struct {
+	ID          string `json:"id"` // user handle
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type WebAuthnCredParam struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type WebAuthnCredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// PublicKeyCredentialCreationOptions untuk navigator.credentials.create()
+type WebAuthnCreationOptions struct {
+	ChallengeID string `json:"challengeId"`
+	PublicKey   struct {
+		Challenge              string                         `json:"challenge"`
+		RP                     WebAuthnRP                     `json:"rp"`
+		User                   WebAuthnUser                   `json:"user"`
+		PubKeyCredParams       []WebAuthnCredParam            `json:"pubKeyCredParams"`
+		Timeout                int64                          `json:"timeout"` // ms
+		ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+		AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+		Attestation            string                         `json:"attestation"`
+	} `json:"publicKey"`
+}
+
+// PublicKeyCredentialRequestOptions untuk navigator.credentials.get()
+type WebAuthnRequestOptions struct {
+	ChallengeID string `json:"challengeId"`
+	PublicKey   struct {
+		Challenge        string                         `json:"challenge"`
+		RPID             string                         `json:"rpId"`
+		Timeout          int64                          `json:"timeout"` // ms
+		AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+		UserVerification string                         `json:"userVerification"`
+	} `json:"publicKey"`
+}
+
+type WebAuthnRegistrationRequest struct {
+	ChallengeID       string   `json:"challengeId"`
+	Name              string   `json:"name"` // label dari user, ex: "YubiKey kantor"
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject"`
+	Transports        []string `json:"transports"`
+}
+
+type WebAuthnAssertion struct {
+	ChallengeID       string `json:"challengeId"`
+	CredentialID      string `json:"id"`
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle,omitempty"`
+}
+
+type WebAuthnCredentialResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/http/httperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// AdminRoleHandler: /api/v1/admin/roles dan assignment role user.
+// Permission dicek router (roles:read / roles:write).
+type AdminRoleHandler struct {
+	svc contract.RoleService
+}
+
+func NewAdminRoleHandler(svc contract.RoleService) *AdminRoleHandler {
+	return &AdminRoleHandler{svc: svc}
+}
+
+// Permissions: semua permission yang bisa dimasukkan ke role.
+func (h *AdminRoleHandler) Permissions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, dto.PermissionListResponse{Items: slices.Clone(domain.Permissions)})
+}
+
+func (h *AdminRoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.svc.List(r.Context())
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.ToRoleListResponse(roles))
+}
+
+func (h *AdminRoleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFrom(w, r)
+	if !ok {
+		return
+	}
+	var req dto.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	role, err := h.svc.Create(r.Context(), actor, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, dto.ToRoleResponse(*role))
+}
+
+func (h *AdminRoleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := uuidParam(w, r, "id", errInvalidRoleID)
+	if !ok {
+		return
+	}
+	role, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.ToRoleResponse(*role))
+}
+
+func (h *AdminRoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFrom(w, r)
+	if !ok {
+		return
+	}
+	id, ok := uuidParam(w, r, "id", errInvalidRoleID)
+	if !ok {
+		return
+	}
+	var req dto.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	role, err := h.svc.Update(r.Context(), actor, id, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.ToRoleResponse(*role))
+}
+
+func (h *AdminRoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFrom(w, r)
+	if !ok {
+		return
+	}
+	id, ok := uuidParam(w, r, "id", errInvalidRoleID)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), actor, id); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UserRoles: GET /admin/users/{id}/roles
+func (h *AdminRoleHandler) UserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	roles, err := h.svc.UserRoles(r.Context(), userID)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.ToRoleListResponse(roles))
+}
+
+// Assign: PUT /admin/users/{id}/roles/{roleId} (idempoten)
+func (h *AdminRoleHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	h.assignment(w, r, h.svc.Assign)
+}
+
+// Unassign: DELETE /admin/users/{id}/roles/{roleId}
+func (h *AdminRoleHandler) Unassign(w http.ResponseWriter, r *http.Request) {
+	h.assignment(w, r, h.svc.Unassign)
+}
+
+func (h *AdminRoleHandler) assignment(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, actor *uuid.UUID, userID, roleID uuid.UUID) error) {
+	actor, ok := actorFrom(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	roleID, ok := uuidParam(w, r, "roleId", errInvalidRoleID)
+	if !ok {
+		return
+	}
+	if err := fn(r.Context(), actor, userID, roleID); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/http/httperr"
+	"xeed/apps/cp-api/internal/http/middleware"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	errInvalidUserID = apperr.Validation("invalid_user_id", "user id must be a uuid", apperr.Field("id", "invalid", "must be a uuid"))
+	errInvalidRoleID = apperr.Validation("invalid_role_id", "role id must be a uuid", apperr.Field("roleId", "invalid", "must be a uuid"))
+)
+
+func invalidQuery(field, msg string) error {
+	return apperr.Validation("invalid_query", "invalid query parameter", apperr.Field(field, "invalid", msg))
+}
+
+// AdminUserHandler: /api/v1/admin/users, hanya untuk principal dengan scope admin
+// (dijaga router). Actor perubahan = principal pemanggil.
+type AdminUserHandler struct {
+	svc contract.AdminUserService
+}
+
+func NewAdminUserHandler(svc contract.AdminUserService) *AdminUserHandler {
+	return &AdminUserHandler{svc: svc}
+}
+
+func (h *AdminUserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFrom(w, r)
+	if !ok {
+		return
+	}
+	var req dto.AdminCreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.svc.CreateUser(r.Context(), actor, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// List: ?status=ACTIVE&serviceAccount=false&emailVerified=true&emailPrefix=budi
+// &q=santoso&createdFrom=2024-01-01T00:00:00Z&createdTo=...&sort=-createdAt&limit=50&cursor=...
+func (h *AdminUserHandler) List(w http.ResponseWriter, r *http.Request) {
+	in, err := listUsersRequestFrom(r)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	resp, err := h.svc.List(r.Context(), in)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *AdminUserHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.svc.Get(r.Context(), id)
+	h.respond(w, r, u, err)
+}
+
+func (h *AdminUserHandler) Update(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFrom(w, r)
+	if !ok {
+		return
+	}
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req dto.AdminUpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	u, err := h.svc.Update(r.Context(), actor, id, req)
+	h.respond(w, r, u, err)
+}
+
+func (h *AdminUserHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFrom(w, r)
+	if !ok {
+		return
+	}
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), actor, id); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminUserHandler) Lock(w http.ResponseWriter, r *http.Request) {
+	h.action(w, r, h.svc.Lock)
+}
+
+func (h *AdminUserHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	h.action(w, r, h.svc.Suspend)
+}
+
+func (h *AdminUserHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	h.action(w, r, h.svc.Reactivate)
+}
+
+// ForcePasswordChange: user wajib ganti password di login/refresh berikutnya
+func (h *AdminUserHandler) ForcePasswordChange(w http.ResponseWriter, r *http.Request) {
+	h.action(w, r, func(ctx context.Context, actor *uuid.UUID, id uuid.UUID) (*domain.User, error) {
+		return h.svc.SetMustChangePassword(ctx, actor, id, true)
+	})
+}
+
+func (h *AdminUserHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	actor, ok := actorFrom(w, r)
+	if !ok {
+		return
+	}
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	var req dto.AdminResetPasswordRequest
+	if r.ContentLength != 0 { // body opsional: kosong = password sementara
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httperr.Write(w, r, httperr.ErrInvalidJSON)
+			return
+		}
+	}
+	resp, err := h.svc.ResetPassword(r.Context(), actor, id, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *AdminUserHandler) action(w http.ResponseWriter, r *http.Request, fn func(context.Context, *uuid.UUID, uuid.UUID) (*domain.User, error)) {
+	actor, ok := actorFrom(w, r)
+	if !ok {
+		return
+	}
+	id, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+	u, err := fn(r.Context(), actor, id)
+	h.respond(w, r, u, err)
+}
+
+func (h *AdminUserHandler) respond(w http.ResponseWriter, r *http.Request, u *domain.User, err error) {
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.ToAdminUserResponse(*u))
+}
+
+func actorFrom(w http.ResponseWriter, r *http.Request) (*uuid.UUID, bool) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return nil, false
+	}
+	return &p.UserID, true
+}
+
+func userIDParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	return uuidParam(w, r, "id", errInvalidUserID)
+}
+
+func uuidParam(w http.ResponseWriter, r *http.Request, name string, invalid error) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		httperr.Write(w, r, invalid)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// listUsersRequestFrom: hanya parsing tipe; validasi nilai di service.
+func listUsersRequestFrom(r *http.Request) (dto.AdminListUsersRequest, error) {
+	q := r.URL.Query()
+	in := dto.AdminListUsersRequest{
+		Status:      q.Get("status"),
+		EmailPrefix: q.Get("emailPrefix"),
+		Query:       q.Get("q"),
+		Sort:        q.Get("sort"),
+		Cursor:      q.Get("cursor"),
+	}
+	var err error
+	if in.ServiceAccount, err = boolParam(q.Get("serviceAccount"), "serviceAccount"); err != nil {
+		return in, err
+	}
+	if in.EmailVerified, err = boolParam(q.Get("emailVerified"), "emailVerified"); err != nil {
+		return in, err
+	}
+	if in.CreatedFrom, err = timeParam(q.Get("createdFrom"), "createdFrom"); err != nil {
+		return in, err
+	}
+	if in.CreatedTo, err = timeParam(q.Get("createdTo"), "createdTo"); err != nil {
+		return in, err
+	}
+	if s := q.Get("limit"); s != "" {
+		if in.Limit, err = strconv.Atoi(s); err != nil {
+			return in, invalidQuery("limit", "must be an integer")
+		}
+	}
+	return in, nil
+}
+
+func boolParam(s, name string) (*bool, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, invalidQuery(name, "must be true or false")
+	}
+	return &v, nil
+}
+
+func timeParam(s, name string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, invalidQuery(name, "must be an RFC 3339 timestamp")
+	}
+	return &t, nil
+}
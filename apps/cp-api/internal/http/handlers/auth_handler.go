@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/http/httperr"
+	"xeed/apps/cp-api/internal/http/middleware"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+type AuthHandler struct {
+	sessions      contract.SessionService
+	verifications contract.VerificationService
+	passwords     contract.PasswordService
+}
+
+func NewAuthHandler(
+	sessions contract.SessionService,
+	verifications contract.VerificationService,
+	passwords contract.PasswordService,
+) *AuthHandler {
+	return &AuthHandler{sessions: sessions, verifications: verifications, passwords: passwords}
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.sessions.Refresh(r.Context(), req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	h.logout(w, r, h.sessions.Logout)
+}
+
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	h.logout(w, r, h.sessions.LogoutAll)
+}
+
+func (h *AuthHandler) logout(w http.ResponseWriter, r *http.Request, fn func(context.Context, domain.Principal) error) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	if err := fn(r.Context(), p); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	user, err := h.verifications.VerifyEmail(r.Context(), req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(dto.ToUserResponse(*user))
+}
+
+// ResendVerification selalu 202 supaya tidak bisa dipakai enumerasi email.
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	if err := h.verifications.ResendEmailVerification(r.Context(), req); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ForgotPassword selalu 202 supaya tidak bisa dipakai enumerasi email.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	if err := h.passwords.ForgotPassword(r.Context(), req); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	if err := h.passwords.ResetPassword(r.Context(), req); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	var req dto.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.passwords.ChangePassword(r.Context(), p, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
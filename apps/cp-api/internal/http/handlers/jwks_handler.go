@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+type JWKSHandler struct {
+	keys contract.JWKSProvider
+}
+
+func NewJWKSHandler(keys contract.JWKSProvider) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+func (h *JWKSHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	// cache pendek supaya key baru cepat terlihat saat rotasi
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = json.NewEncoder(w).Encode(h.keys.JWKS())
+}
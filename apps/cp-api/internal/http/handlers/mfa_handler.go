@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/http/httperr"
+	"xeed/apps/cp-api/internal/http/middleware"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+type MFAHandler struct {
+	svc contract.MFAService
+}
+
+func NewMFAHandler(svc contract.MFAService) *MFAHandler {
+	return &MFAHandler{svc: svc}
+}
+
+func (h *MFAHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	resp, err := h.svc.EnrollTOTP(r.Context(), p)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *MFAHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	var req dto.TOTPConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.svc.ConfirmTOTP(r.Context(), p, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// RegenerateRecoveryCodes: set lama langsung tidak berlaku
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	resp, err := h.svc.RegenerateRecoveryCodes(r.Context(), p)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Verify: langkah kedua login (public, pakai mfaToken dari /auth/login)
+func (h *MFAHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	req.ClientIP = clientIP(r)
+	resp, err := h.svc.Verify(r.Context(), req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// BeginWebAuthn: options security key untuk langkah MFA (public, pakai mfaToken)
+func (h *MFAHandler) BeginWebAuthn(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFAWebAuthnBeginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.svc.BeginWebAuthn(r.Context(), req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *MFAHandler) EnrollOTP(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	var req dto.MFAOTPEnrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.svc.EnrollOTP(r.Context(), p, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+func (h *MFAHandler) ConfirmOTP(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	var req dto.MFAOTPConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.svc.ConfirmOTP(r.Context(), p, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// SendOTP: kirim kode email/SMS untuk langkah MFA login (public, pakai mfaToken)
+func (h *MFAHandler) SendOTP(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFAOTPSendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.svc.SendOTP(r.Context(), req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
@@ -2,11 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
+	"net"
 	"net/http"
 
 	"xeed/apps/cp-api/internal/dto"
-	"xeed/apps/cp-api/internal/usecase"
+	"xeed/apps/cp-api/internal/http/httperr"
+	"xeed/apps/cp-api/internal/http/middleware"
 	"xeed/apps/cp-api/internal/usecase/contract"
 
 	"github.com/google/uuid"
@@ -23,7 +24,7 @@ func NewUserHandler(svc contract.UserService) *UserHandler {
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req dto.RegisterUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
 		return
 	}
 
@@ -34,17 +35,11 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.svc.RegisterUser(r.Context(), req) // langsung pass DTO ke service
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	resp := dto.UserResponse{
-		UserID:   user.UserID,
-		Email:    user.Email,
-		Status:   string(user.Status),
-		Locale:   user.Locale,
-		Timezone: user.Timezone,
-	}
+	resp := dto.ToUserResponse(*user)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(resp)
@@ -53,18 +48,59 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req dto.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
 		return
 	}
+	req.ClientIP = clientIP(r)
 	resp, err := h.svc.Login(r.Context(), req)
 	if err != nil {
-		status := http.StatusBadRequest
-		if errors.Is(err, usecase.ErrInvalidCredential) {
-			status = http.StatusUnauthorized
-		}
-		http.Error(w, err.Error(), status)
+		httperr.Write(w, r, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
+
+func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	me, err := h.svc.GetMe(r.Context(), p.UserID)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(me)
+}
+
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	var req dto.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	user, err := h.svc.UpdateProfile(r.Context(), p.UserID, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(dto.ToUserResponse(*user))
+}
+
+// clientIP dari RemoteAddr (sudah diganti chi RealIP kalau TRUST_PROXY aktif)
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
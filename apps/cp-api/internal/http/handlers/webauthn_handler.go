@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/http/httperr"
+	"xeed/apps/cp-api/internal/http/middleware"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WebAuthnHandler struct {
+	svc contract.WebAuthnService
+}
+
+func NewWebAuthnHandler(svc contract.WebAuthnService) *WebAuthnHandler {
+	return &WebAuthnHandler{svc: svc}
+}
+
+func (h *WebAuthnHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	resp, err := h.svc.BeginRegistration(r.Context(), p)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *WebAuthnHandler) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	var req dto.WebAuthnRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.svc.FinishRegistration(r.Context(), p, req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (h *WebAuthnHandler) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	resp, err := h.svc.ListCredentials(r.Context(), p)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *WebAuthnHandler) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	p, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		httperr.Write(w, r, httperr.ErrUnauthenticated)
+		return
+	}
+	if err := h.svc.DeleteCredential(r.Context(), p, chi.URLParam(r, "id")); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BeginLogin: public, untuk login passkey tanpa password
+func (h *WebAuthnHandler) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.svc.BeginLogin(r.Context())
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *WebAuthnHandler) FinishLogin(w http.ResponseWriter, r *http.Request) {
+	var req dto.WebAuthnAssertion
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperr.Write(w, r, httperr.ErrInvalidJSON)
+		return
+	}
+	resp, err := h.svc.FinishLogin(r.Context(), req)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
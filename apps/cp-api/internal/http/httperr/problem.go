@@ -0,0 +1,88 @@
+// Package httperr: satu-satunya renderer error HTTP. Semua error ditulis
+// sebagai RFC 7807 application/problem+json dengan kode yang stabil.
+package httperr
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"xeed/apps/cp-api/internal/usecase/apperr"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem: body RFC 7807 + extension "code", "requestId", "errors".
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"requestId,omitempty"`
+	Errors    []apperr.FieldError `json:"errors,omitempty"`
+}
+
+var (
+	ErrInvalidJSON     = apperr.Validation("invalid_json", "request body is not valid json")
+	ErrUnauthenticated = apperr.Unauthorized("unauthenticated", "authentication required")
+)
+
+// Write memetakan err ke status HTTP. Error yang tidak dikenal menjadi 500
+// tanpa membocorkan pesan aslinya; pesan asli hanya masuk log.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	e := apperr.From(err)
+	status := Status(e.Kind)
+	reqID := chimw.GetReqID(r.Context())
+
+	if e.Kind == apperr.KindInternal {
+		log.Printf("[http] %s %s [%s]: %v", r.Method, r.URL.Path, reqID, err)
+	}
+	if e.Kind == apperr.KindTooManyRequests && e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds()))))
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    e.Message,
+		Instance:  r.URL.Path,
+		Code:      e.Code,
+		RequestID: reqID,
+		Errors:    e.Fields,
+	})
+}
+
+func Status(k apperr.Kind) int {
+	switch k {
+	case apperr.KindValidation:
+		return http.StatusBadRequest
+	case apperr.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperr.KindForbidden:
+		return http.StatusForbidden
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindConflict:
+		return http.StatusConflict
+	case apperr.KindLocked:
+		return http.StatusLocked
+	case apperr.KindGone:
+		return http.StatusGone
+	case apperr.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	case apperr.KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
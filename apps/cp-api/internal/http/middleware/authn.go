@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/http/httperr"
+	"xeed/apps/cp-api/internal/usecase"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// ErrPasswordChangeRequired: token terbatas dipakai di luar endpoint ganti password.
+var ErrPasswordChangeRequired = apperr.Forbidden("password_change_required", "password change required")
+
+type principalKey struct{}
+
+// WithPrincipal menyimpan principal ke context (dipakai middleware & test).
+func WithPrincipal(ctx context.Context, p domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom mengambil principal dari context; false kalau request tidak terautentikasi.
+func PrincipalFrom(ctx context.Context) (domain.Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(domain.Principal)
+	return p, ok
+}
+
+// Authenticator memvalidasi header "Authorization: Bearer <token>" dan
+// menaruh domain.Principal ke context request.
+type Authenticator struct {
+	sessions contract.SessionService
+}
+
+func NewAuthenticator(sessions contract.SessionService) *Authenticator {
+	return &Authenticator{sessions: sessions}
+}
+
+// Required: token penuh wajib; token terbatas (MustChangePassword) ditolak 403.
+func (a *Authenticator) Required(next http.Handler) http.Handler {
+	return a.handler(next, false)
+}
+
+// AllowRestricted: juga menerima token terbatas (khusus endpoint ganti password).
+func (a *Authenticator) AllowRestricted(next http.Handler) http.Handler {
+	return a.handler(next, true)
+}
+
+func (a *Authenticator) handler(next http.Handler, allowRestricted bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.sessions.Authenticate(r.Context(), BearerToken(r))
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidAccessToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+			httperr.Write(w, r, err)
+			return
+		}
+		if p.IsRestricted() && !allowRestricted {
+			httperr.Write(w, r, ErrPasswordChangeRequired)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), *p)))
+	})
+}
+
+func BearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
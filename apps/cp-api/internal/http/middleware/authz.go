@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"xeed/apps/cp-api/internal/http/httperr"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+var ErrInsufficientScope = apperr.Forbidden("insufficient_scope", "access token does not grant this operation")
+
+// Authorization memeriksa permission principal lewat contract.Authorizer.
+// Dipasang setelah Authenticator.Required.
+type Authorization struct {
+	authz contract.Authorizer
+}
+
+func NewAuthorization(authz contract.Authorizer) *Authorization {
+	if authz == nil {
+		panic("NewAuthorization: authorizer is nil")
+	}
+	return &Authorization{authz: authz}
+}
+
+// RequirePermission: principal tanpa permission ditolak 403 (resource = koleksi).
+//
+//	r.With(authz.RequirePermission(domain.PermUsersWrite)).Post("/admin/users", h.Create)
+func (a *Authorization) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFrom(r.Context())
+			if !ok {
+				httperr.Write(w, r, httperr.ErrUnauthenticated)
+				return
+			}
+			allowed, err := a.authz.Can(r.Context(), p, permission, "")
+			if err != nil {
+				httperr.Write(w, r, err)
+				return
+			}
+			if !allowed {
+				// RFC 6750 §3.1
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, permission))
+				httperr.Write(w, r, ErrInsufficientScope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
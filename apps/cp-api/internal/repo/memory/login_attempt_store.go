@@ -0,0 +1,60 @@
+// apps/cp-api/internal/repo/memory/login_attempt_store.go
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// LoginAttemptStore: implementasi in-memory (untuk test / single instance).
+type LoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]domain.LoginAttempts
+}
+
+var _ contract.LoginAttemptStore = (*LoginAttemptStore)(nil)
+
+func NewLoginAttemptStore() *LoginAttemptStore {
+	return &LoginAttemptStore{attempts: map[string]domain.LoginAttempts{}}
+}
+
+func (s *LoginAttemptStore) Get(_ context.Context, key string) (domain.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts[key], nil
+}
+
+func (s *LoginAttemptStore) RecordFailure(_ context.Context, key string, now time.Time, window time.Duration) (domain.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.attempts[key]
+	a.Key = key
+	if !a.Active(now, window) {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailureAt = now
+	s.attempts[key] = a
+	return a, nil
+}
+
+func (s *LoginAttemptStore) SetLockedUntil(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.attempts[key]
+	a.Key = key
+	a.LockedUntil = &until
+	s.attempts[key] = a
+	return nil
+}
+
+func (s *LoginAttemptStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
@@ -0,0 +1,71 @@
+// apps/cp-api/internal/repo/memory/revocation_store.go
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// RevocationStore: implementasi in-memory (untuk test / single instance).
+type RevocationStore struct {
+	mu     sync.RWMutex
+	clock  contract.Clock
+	tokens map[uuid.UUID]time.Time // jti -> expiresAt
+	users  map[uuid.UUID]time.Time // userID -> token dengan iat < nilai ini dicabut
+}
+
+var _ contract.RevocationStore = (*RevocationStore)(nil)
+
+func NewRevocationStore(clock contract.Clock) *RevocationStore {
+	if clock == nil {
+		panic("NewRevocationStore: clock is nil")
+	}
+	return &RevocationStore{
+		clock:  clock,
+		tokens: map[uuid.UUID]time.Time{},
+		users:  map[uuid.UUID]time.Time{},
+	}
+}
+
+func (s *RevocationStore) RevokeToken(_ context.Context, jti, _ uuid.UUID, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purge(s.clock.Now())
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+func (s *RevocationStore) RevokeUser(_ context.Context, userID uuid.UUID, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at = at.Truncate(time.Microsecond) // presisi iat di JWT dan timestamptz
+	if cur, ok := s.users[userID]; !ok || at.After(cur) {
+		s.users[userID] = at
+	}
+	return nil
+}
+
+func (s *RevocationStore) IsRevoked(_ context.Context, c contract.AccessClaims) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.tokens[c.ID]; ok {
+		return true, nil
+	}
+	if before, ok := s.users[c.UserID]; ok && c.IssuedAt.Truncate(time.Microsecond).Before(before) {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *RevocationStore) purge(now time.Time) {
+	for jti, exp := range s.tokens {
+		if exp.Before(now) {
+			delete(s.tokens, jti)
+		}
+	}
+}
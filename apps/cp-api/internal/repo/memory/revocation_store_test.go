@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/adapter/fake"
+
+	"github.com/google/uuid"
+)
+
+// Entry yang lewat exp dibuang saat revokasi berikutnya, menurut clock store.
+func TestRevocationStorePurgesExpired(t *testing.T) {
+	ctx := context.Background()
+	clock := fake.NewClock(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	s := NewRevocationStore(clock)
+	old, fresh := uuid.New(), uuid.New()
+
+	if err := s.RevokeToken(ctx, old, uuid.New(), clock.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(2 * time.Minute)
+	if err := s.RevokeToken(ctx, fresh, uuid.New(), clock.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.tokens[old]; ok {
+		t.Error("expired jti still stored")
+	}
+	if _, ok := s.tokens[fresh]; !ok {
+		t.Error("fresh jti purged")
+	}
+}
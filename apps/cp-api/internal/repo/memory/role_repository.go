@@ -0,0 +1,208 @@
+// apps/cp-api/internal/repo/memory/role_repository.go
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// RoleRepository: implementasi in-memory (untuk test / dev tanpa database).
+// Tidak memeriksa keberadaan user; itu tugas service.
+type RoleRepository struct {
+	mu     sync.RWMutex
+	admins sync.Mutex // LockAdmins
+	roles  map[uuid.UUID]domain.Role
+	users  map[uuid.UUID]map[uuid.UUID]struct{} // userID -> set roleID
+}
+
+var _ contract.RoleRepository = (*RoleRepository)(nil)
+
+func NewRoleRepository() *RoleRepository {
+	return &RoleRepository{
+		roles: map[uuid.UUID]domain.Role{},
+		users: map[uuid.UUID]map[uuid.UUID]struct{}{},
+	}
+}
+
+func (r *RoleRepository) GetByID(_ context.Context, id uuid.UUID) (*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	role, ok := r.roles[id]
+	if !ok {
+		return nil, nil
+	}
+	return cloneRole(role), nil
+}
+
+func (r *RoleRepository) GetByName(_ context.Context, name string) (*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, role := range r.roles {
+		if role.Name == name {
+			return cloneRole(role), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *RoleRepository) List(_ context.Context) ([]domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sorted(func(domain.Role) bool { return true }), nil
+}
+
+func (r *RoleRepository) ListByUser(_ context.Context, userID uuid.UUID) ([]domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	held := r.users[userID]
+	return r.sorted(func(role domain.Role) bool { _, ok := held[role.RoleID]; return ok }), nil
+}
+
+func (r *RoleRepository) Create(_ context.Context, role domain.Role) (*domain.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.roles[role.RoleID]; ok {
+		return nil, fmt.Errorf("memory: duplicate role id %s", role.RoleID)
+	}
+	if r.nameTaken(role.Name, role.RoleID) {
+		return nil, contract.ErrRoleNameTaken
+	}
+	role.Permissions = normalizePermissions(role.Permissions)
+	r.roles[role.RoleID] = *cloneRole(role)
+	return cloneRole(role), nil
+}
+
+func (r *RoleRepository) Update(_ context.Context, role domain.Role) (*domain.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.roles[role.RoleID]
+	if !ok {
+		return nil, nil
+	}
+	if r.nameTaken(role.Name, role.RoleID) {
+		return nil, contract.ErrRoleNameTaken
+	}
+	role.IsSystem, role.CreatedAt, role.CreatedBy = old.IsSystem, old.CreatedAt, old.CreatedBy
+	role.Permissions = normalizePermissions(role.Permissions)
+	r.roles[role.RoleID] = *cloneRole(role)
+	return cloneRole(role), nil
+}
+
+func (r *RoleRepository) Delete(_ context.Context, id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.roles[id]; !ok {
+		return false, nil
+	}
+	delete(r.roles, id)
+	for _, held := range r.users {
+		delete(held, id)
+	}
+	return true, nil
+}
+
+func (r *RoleRepository) UserIDs(_ context.Context, roleID uuid.UUID) ([]uuid.UUID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []uuid.UUID
+	for userID, held := range r.users {
+		if _, ok := held[roleID]; ok {
+			out = append(out, userID)
+		}
+	}
+	slices.SortFunc(out, func(a, b uuid.UUID) int { return cmp.Compare(a.String(), b.String()) })
+	return out, nil
+}
+
+func (r *RoleRepository) Assign(_ context.Context, userID, roleID uuid.UUID, _ *uuid.UUID, _ time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.roles[roleID]; !ok {
+		return false, fmt.Errorf("memory: unknown role %s", roleID)
+	}
+	held := r.users[userID]
+	if held == nil {
+		held = map[uuid.UUID]struct{}{}
+		r.users[userID] = held
+	}
+	if _, ok := held[roleID]; ok {
+		return false, nil
+	}
+	held[roleID] = struct{}{}
+	return true, nil
+}
+
+func (r *RoleRepository) Unassign(_ context.Context, userID, roleID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[userID][roleID]; !ok {
+		return false, nil
+	}
+	delete(r.users[userID], roleID)
+	return true, nil
+}
+
+func (r *RoleRepository) LockAdmins(context.Context) (func(), error) {
+	r.admins.Lock()
+	return r.admins.Unlock, nil
+}
+
+func (r *RoleRepository) PermissionsFor(_ context.Context, userID uuid.UUID) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var perms []string
+	for roleID := range r.users[userID] {
+		perms = append(perms, r.roles[roleID].Permissions...)
+	}
+	return normalizePermissions(perms), nil
+}
+
+// sorted: role yang lolos filter, urut Name. Caller memegang lock.
+func (r *RoleRepository) sorted(keep func(domain.Role) bool) []domain.Role {
+	var out []domain.Role
+	for _, role := range r.roles {
+		if keep(role) {
+			out = append(out, *cloneRole(role))
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Role) int { return cmp.Compare(a.Name, b.Name) })
+	return out
+}
+
+func (r *RoleRepository) nameTaken(name string, except uuid.UUID) bool {
+	for id, role := range r.roles {
+		if id != except && role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizePermissions: urut dan tanpa duplikat, sama seperti hasil query pg.
+func normalizePermissions(perms []string) []string {
+	out := slices.Clone(perms)
+	slices.Sort(out)
+	out = slices.Compact(out)
+	if len(out) == 0 {
+		return []string{}
+	}
+	return out
+}
+
+func cloneRole(role domain.Role) *domain.Role {
+	c := role
+	c.Description = clonePtr(role.Description)
+	c.CreatedBy = clonePtr(role.CreatedBy)
+	c.UpdatedBy = clonePtr(role.UpdatedBy)
+	c.Permissions = slices.Clone(role.Permissions)
+	return &c
+}
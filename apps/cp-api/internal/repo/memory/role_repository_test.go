@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"xeed/apps/cp-api/internal/repo/repotest"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+func TestRoleRepository(t *testing.T) {
+	repotest.RoleRepository(t, func(*testing.T) (contract.RoleRepository, contract.UserRepository) {
+		return NewRoleRepository(), NewUserRepository()
+	})
+}
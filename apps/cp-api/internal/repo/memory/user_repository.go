@@ -0,0 +1,210 @@
+// apps/cp-api/internal/repo/memory/user_repository.go
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// UserRepository: implementasi in-memory (untuk test / dev tanpa database).
+// Semantiknya sama dengan repo pg; dijaga oleh suite di repo/repotest.
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]domain.User
+}
+
+var _ contract.UserRepository = (*UserRepository)(nil)
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: map[uuid.UUID]domain.User{}}
+}
+
+func (r *UserRepository) GetByEmail(_ context.Context, email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if u, ok := r.byEmail(email); ok {
+		return cloneUser(u), nil
+	}
+	return nil, nil
+}
+
+func (r *UserRepository) GetByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	u, ok := r.users[id]
+	if !ok || u.IsDeleted {
+		return nil, nil
+	}
+	return cloneUser(u), nil
+}
+
+func (r *UserRepository) Create(_ context.Context, u domain.User) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[u.UserID]; ok {
+		return nil, fmt.Errorf("memory: user %s already exists", u.UserID)
+	}
+	if !u.IsDeleted {
+		if _, ok := r.byEmail(u.Email); ok {
+			return nil, contract.ErrEmailTaken
+		}
+	}
+	stored := *cloneUser(u)
+	if stored.Preferences == nil {
+		stored.Preferences = domain.Preferences{} // default kolom JSONB '{}'
+	}
+	r.users[u.UserID] = stored
+	return cloneUser(stored), nil
+}
+
+// Update tidak mengubah CreatedAt/CreatedBy (sama dengan pg).
+func (r *UserRepository) Update(_ context.Context, u domain.User) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur, ok := r.users[u.UserID]
+	if !ok || cur.IsDeleted {
+		return nil, nil
+	}
+	if !u.IsDeleted {
+		if other, ok := r.byEmail(u.Email); ok && other.UserID != u.UserID {
+			return nil, contract.ErrEmailTaken
+		}
+	}
+	stored := *cloneUser(u)
+	stored.CreatedAt, stored.CreatedBy = cur.CreatedAt, cur.CreatedBy
+	if stored.Preferences == nil {
+		stored.Preferences = domain.Preferences{}
+	}
+	r.users[u.UserID] = stored
+	return cloneUser(stored), nil
+}
+
+func (r *UserRepository) SetStatus(_ context.Context, id uuid.UUID, from, to domain.UserStatus, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok || u.IsDeleted || u.Status != from {
+		return false, nil
+	}
+	u.Status, u.UpdatedAt = to, at
+	r.users[id] = u
+	return true, nil
+}
+
+func (r *UserRepository) List(_ context.Context, f contract.UserFilter) (*contract.UserPage, error) {
+	limit := f.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	desc := f.Sort == contract.UserSortCreatedDesc
+	q := strings.ToLower(f.Query)
+	prefix := strings.ToLower(f.EmailPrefix)
+
+	r.mu.RLock()
+	var out []domain.User
+	for _, u := range r.users {
+		if u.IsDeleted || (f.Status != "" && u.Status != f.Status) {
+			continue
+		}
+		if f.ServiceAccount != nil && u.IsServiceAccount != *f.ServiceAccount {
+			continue
+		}
+		if f.EmailVerified != nil && (u.EmailVerifiedAt != nil) != *f.EmailVerified {
+			continue
+		}
+		if prefix != "" && !strings.HasPrefix(strings.ToLower(u.Email), prefix) {
+			continue
+		}
+		if q != "" && !strings.Contains(strings.ToLower(u.Email), q) &&
+			(u.DisplayName == nil || !strings.Contains(strings.ToLower(*u.DisplayName), q)) {
+			continue
+		}
+		if (f.CreatedFrom != nil && u.CreatedAt.Before(*f.CreatedFrom)) ||
+			(f.CreatedTo != nil && !u.CreatedAt.Before(*f.CreatedTo)) {
+			continue
+		}
+		if f.After != nil {
+			c := compareKey(u, *f.After)
+			if (!desc && c <= 0) || (desc && c >= 0) {
+				continue
+			}
+		}
+		out = append(out, *cloneUser(u))
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(out, func(a, b domain.User) int {
+		c := compareKey(a, contract.UserCursor{CreatedAt: b.CreatedAt, UserID: b.UserID})
+		if desc {
+			return -c
+		}
+		return c
+	})
+	page := &contract.UserPage{Users: out[:min(limit, len(out))]}
+	if len(out) > limit {
+		last := page.Users[limit-1]
+		page.Next = &contract.UserCursor{CreatedAt: last.CreatedAt, UserID: last.UserID}
+	}
+	return page, nil
+}
+
+// compareKey: urutan keyset (CreatedAt, UserID); UserID dibandingkan per byte seperti uuid di Postgres.
+func compareKey(u domain.User, c contract.UserCursor) int {
+	if d := u.CreatedAt.Compare(c.CreatedAt); d != 0 {
+		return d
+	}
+	return bytes.Compare(u.UserID[:], c.UserID[:])
+}
+
+// byEmail: user aktif (belum dihapus) dengan email case-insensitive. Caller memegang lock.
+func (r *UserRepository) byEmail(email string) (domain.User, bool) {
+	for _, u := range r.users {
+		if !u.IsDeleted && strings.EqualFold(u.Email, email) {
+			return u, true
+		}
+	}
+	return domain.User{}, false
+}
+
+// cloneUser: deep copy supaya caller tidak bisa mengubah data tersimpan lewat pointer/map.
+func cloneUser(u domain.User) *domain.User {
+	c := u
+	c.EmailVerifiedAt = clonePtr(u.EmailVerifiedAt)
+	c.PhoneE164 = clonePtr(u.PhoneE164)
+	c.PhoneVerifiedAt = clonePtr(u.PhoneVerifiedAt)
+	c.PasswordHash = clonePtr(u.PasswordHash)
+	c.PasswordUpdatedAt = clonePtr(u.PasswordUpdatedAt)
+	c.DisplayName = clonePtr(u.DisplayName)
+	c.AvatarURL = clonePtr(u.AvatarURL)
+	c.MFADefaultMethod = clonePtr(u.MFADefaultMethod)
+	c.LastLoginAt = clonePtr(u.LastLoginAt)
+	c.CreatedBy = clonePtr(u.CreatedBy)
+	c.UpdatedBy = clonePtr(u.UpdatedBy)
+	if u.LastLoginIP != nil {
+		ip := slices.Clone(*u.LastLoginIP)
+		c.LastLoginIP = &ip
+	}
+	if u.Preferences != nil {
+		c.Preferences = maps.Clone(u.Preferences)
+	}
+	return &c
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
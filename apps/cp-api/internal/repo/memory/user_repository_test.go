@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"xeed/apps/cp-api/internal/repo/repotest"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+func TestUserRepository(t *testing.T) {
+	repotest.UserRepository(t, func(*testing.T) contract.UserRepository {
+		return NewUserRepository()
+	})
+}
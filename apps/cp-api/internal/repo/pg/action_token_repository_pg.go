@@ -0,0 +1,80 @@
+// apps/cp-api/internal/repo/pg/action_token_repository_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type actionTokenRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewActionTokenRepositoryPG(db *pgxpool.Pool) contract.ActionTokenRepository {
+	return &actionTokenRepoPG{db: db}
+}
+
+func (r *actionTokenRepoPG) Create(ctx context.Context, t domain.ActionToken) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "UserActionToken" (
+			"TokenID","UserID","Purpose","TokenHash","ExpiresAt","CreatedAt"
+		) VALUES ($1,$2,$3,$4,$5,$6)
+	`, t.TokenID, t.UserID, t.Purpose, t.TokenHash, t.ExpiresAt, t.CreatedAt)
+	return err
+}
+
+func (r *actionTokenRepoPG) GetActive(ctx context.Context, purpose domain.TokenPurpose, hash string, now time.Time) (*domain.ActionToken, error) {
+	const q = `
+		SELECT "TokenID","UserID","Purpose","TokenHash","ExpiresAt","CreatedAt","UsedAt"
+		FROM "UserActionToken"
+		WHERE "TokenHash" = $1 AND "Purpose" = $2
+			AND "UsedAt" IS NULL AND "ExpiresAt" > $3
+	`
+	var t domain.ActionToken
+	if err := r.db.QueryRow(ctx, q, hash, purpose, now).Scan(
+		&t.TokenID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &t.CreatedAt, &t.UsedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *actionTokenRepoPG) Consume(ctx context.Context, purpose domain.TokenPurpose, hash string, now time.Time) (*domain.ActionToken, error) {
+	const q = `
+		UPDATE "UserActionToken"
+		SET "UsedAt" = $3
+		WHERE "TokenHash" = $1 AND "Purpose" = $2
+			AND "UsedAt" IS NULL AND "ExpiresAt" > $3
+		RETURNING "TokenID","UserID","Purpose","TokenHash","ExpiresAt","CreatedAt","UsedAt"
+	`
+	var t domain.ActionToken
+	if err := r.db.QueryRow(ctx, q, hash, purpose, now).Scan(
+		&t.TokenID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &t.CreatedAt, &t.UsedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *actionTokenRepoPG) InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose domain.TokenPurpose, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE "UserActionToken"
+		SET "UsedAt" = $3
+		WHERE "UserID" = $1 AND "Purpose" = $2 AND "UsedAt" IS NULL
+	`, userID, purpose, at)
+	return err
+}
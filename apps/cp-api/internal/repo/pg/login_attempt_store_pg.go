@@ -0,0 +1,64 @@
+// apps/cp-api/internal/repo/pg/login_attempt_store_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type loginAttemptStorePG struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginAttemptStorePG(db *pgxpool.Pool) contract.LoginAttemptStore {
+	return &loginAttemptStorePG{db: db}
+}
+
+func (r *loginAttemptStorePG) Get(ctx context.Context, key string) (domain.LoginAttempts, error) {
+	const q = `
+		SELECT "Key","Failures","LastFailureAt","LockedUntil"
+		FROM "LoginAttempt"
+		WHERE "Key" = $1
+	`
+	var a domain.LoginAttempts
+	err := r.db.QueryRow(ctx, q, key).Scan(&a.Key, &a.Failures, &a.LastFailureAt, &a.LockedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.LoginAttempts{}, nil
+	}
+	return a, err
+}
+
+func (r *loginAttemptStorePG) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (domain.LoginAttempts, error) {
+	// upsert atomik supaya aman dipakai banyak replica
+	const q = `
+		INSERT INTO "LoginAttempt" ("Key","Failures","LastFailureAt")
+		VALUES ($1, 1, $2)
+		ON CONFLICT ("Key") DO UPDATE SET
+			"Failures" = CASE
+				WHEN "LoginAttempt"."LastFailureAt" <= $3 THEN 1
+				ELSE "LoginAttempt"."Failures" + 1
+			END,
+			"LastFailureAt" = EXCLUDED."LastFailureAt"
+		RETURNING "Key","Failures","LastFailureAt","LockedUntil"
+	`
+	var a domain.LoginAttempts
+	err := r.db.QueryRow(ctx, q, key, now, now.Add(-window)).Scan(&a.Key, &a.Failures, &a.LastFailureAt, &a.LockedUntil)
+	return a, err
+}
+
+func (r *loginAttemptStorePG) SetLockedUntil(ctx context.Context, key string, until time.Time) error {
+	_, err := r.db.Exec(ctx, `UPDATE "LoginAttempt" SET "LockedUntil" = $2 WHERE "Key" = $1`, key, until)
+	return err
+}
+
+func (r *loginAttemptStorePG) Reset(ctx context.Context, key string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM "LoginAttempt" WHERE "Key" = $1`, key)
+	return err
+}
@@ -0,0 +1,9 @@
+package pg
+
+import (
+	"testing"
+
+	"xeed/apps/cp-api/internal/repo/pg/pgtest"
+)
+
+func TestMain(m *testing.M) { pgtest.Main(m) }
@@ -0,0 +1,73 @@
+// apps/cp-api/internal/repo/pg/mfa_challenge_repository_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type mfaChallengeRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewMFAChallengeRepositoryPG(db *pgxpool.Pool) contract.MFAChallengeRepository {
+	return &mfaChallengeRepoPG{db: db}
+}
+
+func (r *mfaChallengeRepoPG) Create(ctx context.Context, c domain.MFAChallenge) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "MFAChallenge" (
+			"ChallengeID","UserID","TokenHash","Attempts","ExpiresAt","CreatedAt"
+		) VALUES ($1,$2,$3,$4,$5,$6)
+	`, c.ChallengeID, c.UserID, c.TokenHash, c.Attempts, c.ExpiresAt, c.CreatedAt)
+	return err
+}
+
+func (r *mfaChallengeRepoPG) GetActive(ctx context.Context, hash string, now time.Time) (*domain.MFAChallenge, error) {
+	const q = `
+		SELECT "ChallengeID","UserID","TokenHash","Attempts","ExpiresAt","ConsumedAt","CreatedAt"
+		FROM "MFAChallenge"
+		WHERE "TokenHash" = $1 AND "ConsumedAt" IS NULL AND "ExpiresAt" > $2
+	`
+	var c domain.MFAChallenge
+	if err := r.db.QueryRow(ctx, q, hash, now).Scan(
+		&c.ChallengeID, &c.UserID, &c.TokenHash, &c.Attempts, &c.ExpiresAt, &c.ConsumedAt, &c.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *mfaChallengeRepoPG) IncrementAttempts(ctx context.Context, id uuid.UUID, max int) (bool, error) {
+	// cek dan tambah dalam satu statement: request paralel tidak bisa melewati max
+	tag, err := r.db.Exec(ctx, `
+		UPDATE "MFAChallenge" SET "Attempts" = "Attempts" + 1
+		WHERE "ChallengeID" = $1 AND "Attempts" < $2
+	`, id, max)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *mfaChallengeRepoPG) Consume(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE "MFAChallenge" SET "ConsumedAt" = $2
+		WHERE "ChallengeID" = $1 AND "ConsumedAt" IS NULL
+	`, id, at)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,322 @@
+// Package migrations: migration SQL yang ter-embed di binary beserta runner-nya.
+//
+// File di sql/ bernama <versi>_<nama>.up.sql dan <versi>_<nama>.down.sql.
+// Versi yang sudah diterapkan dicatat di tabel "SchemaMigration" bersama
+// checksum file up-nya; file yang diubah setelah diterapkan ditolak.
+// Up/Down memegang advisory lock supaya beberapa replica tidak migrate bersamaan.
+package migrations
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// lockKey: kunci pg_advisory_lock khusus migration cp-api
+const lockKey int64 = 0x78656564_6d696772 // "xeedmigr"
+
+var (
+	ErrChecksumMismatch = errors.New("migration file changed after it was applied")
+	ErrUnknownVersion   = errors.New("database has a migration unknown to this binary")
+)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 hex dari Up
+}
+
+type State string
+
+const (
+	StateApplied  State = "applied"
+	StatePending  State = "pending"
+	StateModified State = "modified" // checksum di DB beda dengan file
+	StateUnknown  State = "unknown"  // tercatat di DB tapi tidak ada di binary
+)
+
+type Status struct {
+	Version   int64
+	Name      string
+	State     State
+	AppliedAt *time.Time
+}
+
+type applied struct {
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration // urut naik berdasarkan Version
+}
+
+// New memakai migration yang ter-embed di binary.
+func New(db *pgxpool.Pool) (*Migrator, error) {
+	if db == nil {
+		panic("migrations.New: db is nil")
+	}
+	sub, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+	ms, err := load(sub)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: ms}, nil
+}
+
+var rxFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// load membaca pasangan up/down dari root fsys, diurutkan berdasarkan versi.
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		m := rxFile.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migrations: bad file name %q (want <version>_<name>.up|down.sql)", e.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migrations: version %d used by %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+			sum := sha256.Sum256(body)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migrations: version %d (%s) needs both up and down files", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	slices.SortFunc(out, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+	return out, nil
+}
+
+// Up menerapkan semua migration yang belum diterapkan, masing-masing dalam transaksi sendiri.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		state, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := state[mig.Version]; ok {
+				continue
+			}
+			if err := apply(ctx, conn, mig.Up, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, `
+					INSERT INTO "SchemaMigration" ("Version","Name","Checksum","AppliedAt")
+					VALUES ($1,$2,$3,now())
+				`, mig.Version, mig.Name, mig.Checksum)
+				return err
+			}); err != nil {
+				return fmt.Errorf("migrations: up %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down membatalkan steps migration terakhir (urut turun).
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		state, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := state[mig.Version]; !ok {
+				continue
+			}
+			if err := apply(ctx, conn, mig.Down, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, `DELETE FROM "SchemaMigration" WHERE "Version" = $1`, mig.Version)
+				return err
+			}); err != nil {
+				return fmt.Errorf("migrations: down %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status: read-only, tidak membuat tabel dan tidak mengambil lock.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT to_regclass('"SchemaMigration"') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	state := map[int64]applied{}
+	if exists {
+		if state, err = readApplied(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name, State: StatePending}
+		if a, ok := state[mig.Version]; ok {
+			at := a.AppliedAt
+			s.AppliedAt = &at
+			s.State = StateApplied
+			if a.Checksum != mig.Checksum {
+				s.State = StateModified
+			}
+			delete(state, mig.Version)
+		}
+		out = append(out, s)
+	}
+	for v, a := range state {
+		at := a.AppliedAt
+		out = append(out, Status{Version: v, Name: a.Name, State: StateUnknown, AppliedAt: &at})
+	}
+	slices.SortFunc(out, func(a, b Status) int { return cmp.Compare(a.Version, b.Version) })
+	return out, nil
+}
+
+// locked menjalankan fn di satu koneksi yang memegang advisory lock (session-level).
+func (m *Migrator) locked(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return err
+	}
+	defer func() {
+		// ctx bisa sudah dibatalkan; lock tetap harus dilepas
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+	}()
+
+	if _, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS "SchemaMigration" (
+			"Version"   bigint      PRIMARY KEY,
+			"Name"      text        NOT NULL,
+			"Checksum"  text        NOT NULL,
+			"AppliedAt" timestamptz NOT NULL
+		)
+	`); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// verify: semua versi di DB harus ada di binary dengan checksum yang sama.
+func (m *Migrator) verify(ctx context.Context, conn *pgxpool.Conn) (map[int64]applied, error) {
+	state, err := readApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+	for v, a := range state {
+		mig, ok := known[v]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d_%s", ErrUnknownVersion, v, a.Name)
+		}
+		if a.Checksum != mig.Checksum {
+			return nil, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, v, mig.Name)
+		}
+	}
+	return state, nil
+}
+
+func readApplied(ctx context.Context, conn *pgxpool.Conn) (map[int64]applied, error) {
+	rows, err := conn.Query(ctx, `SELECT "Version","Name","Checksum","AppliedAt" FROM "SchemaMigration"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[int64]applied{}
+	for rows.Next() {
+		var (
+			v int64
+			a applied
+		)
+		if err := rows.Scan(&v, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		out[v] = a
+	}
+	return out, rows.Err()
+}
+
+// apply: SQL migration + pencatatan di "SchemaMigration" dalam satu transaksi.
+// Tanpa argumen pgx memakai simple protocol, jadi satu file boleh berisi banyak statement.
+func apply(ctx context.Context, conn *pgxpool.Conn, sql string, record func(pgx.Tx) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return err
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,30 @@
+-- IF NOT EXISTS: database lama yang tabelnya dibuat manual bisa langsung
+-- diadopsi; migration berikutnya tetap berjalan normal.
+CREATE TABLE IF NOT EXISTS "User" (
+    "UserID"             uuid        PRIMARY KEY,
+    "Email"              text        NOT NULL,
+    "EmailVerifiedAt"    timestamptz NULL,
+    "PhoneE164"          text        NULL,
+    "PhoneVerifiedAt"    timestamptz NULL,
+    "PasswordHash"       text        NULL,
+    "PasswordAlg"        text        NOT NULL DEFAULT 'none',
+    "PasswordUpdatedAt"  timestamptz NULL,
+    "MustChangePassword" boolean     NOT NULL DEFAULT FALSE,
+    "Status"             text        NOT NULL DEFAULT 'PENDING'
+        CHECK ("Status" IN ('PENDING','ACTIVE','LOCKED','SUSPENDED','DELETED')),
+    "IsServiceAccount"   boolean     NOT NULL DEFAULT FALSE,
+    "DisplayName"        text        NULL,
+    "AvatarURL"          text        NULL,
+    "Locale"             text        NOT NULL DEFAULT 'en',
+    "Timezone"           text        NOT NULL DEFAULT 'UTC',
+    "Preferences"        jsonb       NOT NULL DEFAULT '{}'::jsonb,
+    "MFAEnrolled"        boolean     NOT NULL DEFAULT FALSE,
+    "MFADefaultMethod"   text        NULL,
+    "LastLoginAt"        timestamptz NULL,
+    "LastLoginIP"        inet        NULL,
+    "CreatedAt"          timestamptz NOT NULL DEFAULT now(),
+    "CreatedBy"          uuid        NULL,
+    "UpdatedAt"          timestamptz NOT NULL DEFAULT now(),
+    "UpdatedBy"          uuid        NULL,
+    "IsDeleted"          boolean     NOT NULL DEFAULT FALSE
+);
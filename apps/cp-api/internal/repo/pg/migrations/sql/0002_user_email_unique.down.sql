@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "UX_User_Email";
@@ -0,0 +1,11 @@
+-- Email unik case-insensitive untuk user yang belum dihapus.
+-- Nama index dipakai userWriteErr (repo/pg/user_repository_pg.go) untuk
+-- memetakan SQLSTATE 23505 ke contract.ErrEmailTaken.
+--
+-- Database lama dengan email yang hanya beda huruf besar/kecil harus
+-- dibereskan dulu, kalau tidak migration ini gagal:
+--   SELECT lower("Email"), count(*) FROM "User"
+--   WHERE "IsDeleted" = FALSE GROUP BY 1 HAVING count(*) > 1;
+CREATE UNIQUE INDEX IF NOT EXISTS "UX_User_Email"
+    ON "User" (lower("Email"))
+    WHERE "IsDeleted" = FALSE;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "UserTokenRevocation";
+DROP TABLE IF EXISTS "RevokedAccessToken";
+DROP TABLE IF EXISTS "RefreshToken";
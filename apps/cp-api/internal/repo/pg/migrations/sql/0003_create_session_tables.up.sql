@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS "RefreshToken" (
+    "TokenID"    uuid        PRIMARY KEY,
+    "FamilyID"   uuid        NOT NULL,
+    "UserID"     uuid        NOT NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "TokenHash"  text        NOT NULL,
+    "ExpiresAt"  timestamptz NOT NULL,
+    "CreatedAt"  timestamptz NOT NULL,
+    "UsedAt"     timestamptz NULL,
+    "RevokedAt"  timestamptz NULL,
+    "ReplacedBy" uuid        NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "UX_RefreshToken_TokenHash" ON "RefreshToken" ("TokenHash");
+CREATE INDEX IF NOT EXISTS "IX_RefreshToken_FamilyID" ON "RefreshToken" ("FamilyID");
+CREATE INDEX IF NOT EXISTS "IX_RefreshToken_UserID" ON "RefreshToken" ("UserID");
+
+-- access token (jti) yang dicabut sebelum kedaluwarsa
+CREATE TABLE IF NOT EXISTS "RevokedAccessToken" (
+    "JTI"       uuid        PRIMARY KEY,
+    "UserID"    uuid        NOT NULL,
+    "ExpiresAt" timestamptz NOT NULL,
+    "RevokedAt" timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS "IX_RevokedAccessToken_ExpiresAt" ON "RevokedAccessToken" ("ExpiresAt");
+
+-- semua access token user dengan iat < RevokedBefore tidak berlaku (logout-all)
+CREATE TABLE IF NOT EXISTS "UserTokenRevocation" (
+    "UserID"        uuid        PRIMARY KEY REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "RevokedBefore" timestamptz NOT NULL
+);
@@ -0,0 +1,12 @@
+-- token sekali-pakai via email (verifikasi email, reset password)
+CREATE TABLE IF NOT EXISTS "UserActionToken" (
+    "TokenID"   uuid        PRIMARY KEY,
+    "UserID"    uuid        NOT NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "Purpose"   text        NOT NULL,
+    "TokenHash" text        NOT NULL,
+    "ExpiresAt" timestamptz NOT NULL,
+    "CreatedAt" timestamptz NOT NULL,
+    "UsedAt"    timestamptz NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "UX_UserActionToken_Purpose_TokenHash" ON "UserActionToken" ("Purpose", "TokenHash");
+CREATE INDEX IF NOT EXISTS "IX_UserActionToken_UserID_Purpose" ON "UserActionToken" ("UserID", "Purpose") WHERE "UsedAt" IS NULL;
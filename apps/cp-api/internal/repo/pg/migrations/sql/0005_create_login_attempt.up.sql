@@ -0,0 +1,7 @@
+-- counter gagal login per akun ("acct:<email>") dan per IP ("ip:<addr>")
+CREATE TABLE IF NOT EXISTS "LoginAttempt" (
+    "Key"           text        PRIMARY KEY,
+    "Failures"      integer     NOT NULL DEFAULT 0,
+    "LastFailureAt" timestamptz NOT NULL,
+    "LockedUntil"   timestamptz NULL
+);
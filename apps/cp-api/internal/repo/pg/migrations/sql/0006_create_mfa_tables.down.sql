@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "MFARecoveryCode";
+DROP TABLE IF EXISTS "MFAChallenge";
+DROP TABLE IF EXISTS "UserTOTP";
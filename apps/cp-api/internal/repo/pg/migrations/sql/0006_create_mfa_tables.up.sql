@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS "UserTOTP" (
+    "UserID"       uuid        PRIMARY KEY REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "SecretEnc"    bytea       NOT NULL, -- AES-GCM (MFA_ENCRYPTION_KEY)
+    "ConfirmedAt"  timestamptz NULL,
+    "LastUsedStep" bigint      NOT NULL DEFAULT 0,
+    "CreatedAt"    timestamptz NOT NULL
+);
+
+-- langkah kedua login (mfaToken)
+CREATE TABLE IF NOT EXISTS "MFAChallenge" (
+    "ChallengeID" uuid        PRIMARY KEY,
+    "UserID"      uuid        NOT NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "TokenHash"   text        NOT NULL,
+    "Attempts"    integer     NOT NULL DEFAULT 0,
+    "ExpiresAt"   timestamptz NOT NULL,
+    "ConsumedAt"  timestamptz NULL,
+    "CreatedAt"   timestamptz NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "UX_MFAChallenge_TokenHash" ON "MFAChallenge" ("TokenHash");
+
+-- recovery code "ssss-xxxx-xxxx": selector disimpan apa adanya, secret di-hash (PasswordHasher)
+CREATE TABLE IF NOT EXISTS "MFARecoveryCode" (
+    "CodeID"    uuid        PRIMARY KEY,
+    "UserID"    uuid        NOT NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "Selector"  text        NOT NULL,
+    "CodeHash"  text        NOT NULL,
+    "UsedAt"    timestamptz NULL,
+    "CreatedAt" timestamptz NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "UX_MFARecoveryCode_UserID_Selector" ON "MFARecoveryCode" ("UserID", "Selector");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "WebAuthnChallenge";
+DROP TABLE IF EXISTS "WebAuthnCredential";
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS "WebAuthnCredential" (
+    "CredentialID" bytea       PRIMARY KEY,
+    "UserID"       uuid        NOT NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "Name"         text        NOT NULL DEFAULT '',
+    "PublicKey"    bytea       NOT NULL, -- COSE key
+    "SignCount"    bigint      NOT NULL DEFAULT 0,
+    "AAGUID"       bytea       NULL,
+    "Transports"   text[]      NULL,
+    "CreatedAt"    timestamptz NOT NULL,
+    "LastUsedAt"   timestamptz NULL
+);
+CREATE INDEX IF NOT EXISTS "IX_WebAuthnCredential_UserID" ON "WebAuthnCredential" ("UserID");
+
+-- UserID NULL untuk login passkey (user belum diketahui)
+CREATE TABLE IF NOT EXISTS "WebAuthnChallenge" (
+    "ChallengeID" uuid        PRIMARY KEY,
+    "UserID"      uuid        NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "Purpose"     text        NOT NULL,
+    "Challenge"   bytea       NOT NULL,
+    "ExpiresAt"   timestamptz NOT NULL,
+    "ConsumedAt"  timestamptz NULL,
+    "CreatedAt"   timestamptz NOT NULL
+);
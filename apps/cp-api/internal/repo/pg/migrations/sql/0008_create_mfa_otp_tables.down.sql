@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "MFAOTPMethod";
+DROP TABLE IF EXISTS "MFAOTPCode";
@@ -0,0 +1,21 @@
+-- kode email/SMS; ChallengeID terisi untuk purpose "login"
+CREATE TABLE IF NOT EXISTS "MFAOTPCode" (
+    "CodeID"      uuid        PRIMARY KEY,
+    "UserID"      uuid        NOT NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "ChallengeID" uuid        NULL REFERENCES "MFAChallenge" ("ChallengeID") ON DELETE CASCADE,
+    "Method"      text        NOT NULL,
+    "Purpose"     text        NOT NULL,
+    "CodeHash"    text        NOT NULL,
+    "Attempts"    integer     NOT NULL DEFAULT 0,
+    "ExpiresAt"   timestamptz NOT NULL,
+    "ConsumedAt"  timestamptz NULL,
+    "CreatedAt"   timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS "IX_MFAOTPCode_Active" ON "MFAOTPCode" ("UserID", "Purpose", "Method") WHERE "ConsumedAt" IS NULL;
+
+CREATE TABLE IF NOT EXISTS "MFAOTPMethod" (
+    "UserID"    uuid        NOT NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "Method"    text        NOT NULL,
+    "EnabledAt" timestamptz NOT NULL,
+    PRIMARY KEY ("UserID", "Method")
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS "PasswordHistory" (
+    "HistoryID"    bigserial   PRIMARY KEY,
+    "UserID"       uuid        NOT NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "PasswordHash" text        NOT NULL,
+    "PasswordAlg"  text        NOT NULL,
+    "CreatedAt"    timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS "IX_PasswordHistory_UserID_CreatedAt" ON "PasswordHistory" ("UserID", "CreatedAt" DESC);
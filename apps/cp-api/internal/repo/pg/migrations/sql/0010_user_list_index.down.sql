@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "IX_User_CreatedAt_UserID";
@@ -0,0 +1,4 @@
+-- Keyset pagination daftar user admin: ORDER BY ("CreatedAt", "UserID") ASC/DESC.
+CREATE INDEX IF NOT EXISTS "IX_User_CreatedAt_UserID"
+    ON "User" ("CreatedAt", "UserID")
+    WHERE "IsDeleted" = FALSE;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "UserRole";
+DROP TABLE IF EXISTS "RolePermission";
+DROP TABLE IF EXISTS "Role";
+DROP TABLE IF EXISTS "Permission";
@@ -0,0 +1,49 @@
+-- RBAC: user mendapat permission lewat role. Permission dibawa sebagai scope
+-- di access token. Isi "Permission" harus sama dengan domain.Permissions.
+CREATE TABLE IF NOT EXISTS "Permission" (
+    "Name"        text PRIMARY KEY,
+    "Description" text NOT NULL
+);
+INSERT INTO "Permission" ("Name", "Description") VALUES
+    ('users:read',  'View users'),
+    ('users:write', 'Create, update, lock and delete users'),
+    ('roles:read',  'View roles and role assignments'),
+    ('roles:write', 'Manage roles and role assignments')
+ON CONFLICT ("Name") DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS "Role" (
+    "RoleID"      uuid        PRIMARY KEY,
+    "Name"        text        NOT NULL,
+    "Description" text        NULL,
+    "IsSystem"    boolean     NOT NULL DEFAULT FALSE,
+    "CreatedAt"   timestamptz NOT NULL,
+    "CreatedBy"   uuid        NULL,
+    "UpdatedAt"   timestamptz NOT NULL,
+    "UpdatedBy"   uuid        NULL
+);
+-- nama index dipakai roleWriteErr (repo/pg/role_repository_pg.go)
+CREATE UNIQUE INDEX IF NOT EXISTS "UX_Role_Name" ON "Role" ("Name");
+
+CREATE TABLE IF NOT EXISTS "RolePermission" (
+    "RoleID"     uuid NOT NULL REFERENCES "Role" ("RoleID") ON DELETE CASCADE,
+    "Permission" text NOT NULL REFERENCES "Permission" ("Name"),
+    PRIMARY KEY ("RoleID", "Permission")
+);
+
+CREATE TABLE IF NOT EXISTS "UserRole" (
+    "UserID"    uuid        NOT NULL REFERENCES "User" ("UserID") ON DELETE CASCADE,
+    "RoleID"    uuid        NOT NULL REFERENCES "Role" ("RoleID") ON DELETE CASCADE,
+    "CreatedAt" timestamptz NOT NULL,
+    "CreatedBy" uuid        NULL,
+    PRIMARY KEY ("UserID", "RoleID")
+);
+CREATE INDEX IF NOT EXISTS "IX_UserRole_RoleID" ON "UserRole" ("RoleID");
+
+-- Role bawaan "admin" (semua permission); admin pertama di-assign lewat
+-- "cp-api user create --role admin" atau "cp-api user grant-role".
+INSERT INTO "Role" ("RoleID", "Name", "Description", "IsSystem", "CreatedAt", "UpdatedAt")
+VALUES ('00000000-0000-4000-8000-000000000001', 'admin', 'Full access to the admin API', TRUE, now(), now())
+ON CONFLICT ("RoleID") DO NOTHING;
+INSERT INTO "RolePermission" ("RoleID", "Permission")
+SELECT '00000000-0000-4000-8000-000000000001', "Name" FROM "Permission"
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,94 @@
+// apps/cp-api/internal/repo/pg/otp_code_repository_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type otpCodeRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewOTPCodeRepositoryPG(db *pgxpool.Pool) contract.OTPCodeRepository {
+	return &otpCodeRepoPG{db: db}
+}
+
+func (r *otpCodeRepoPG) Create(ctx context.Context, c domain.OTPCode) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// hanya satu kode aktif per user+purpose+method
+	if _, err := tx.Exec(ctx, `
+		UPDATE "MFAOTPCode" SET "ConsumedAt" = $4
+		WHERE "UserID" = $1 AND "Purpose" = $2 AND "Method" = $3 AND "ConsumedAt" IS NULL
+	`, c.UserID, string(c.Purpose), string(c.Method), c.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO "MFAOTPCode" (
+			"CodeID","UserID","ChallengeID","Method","Purpose","CodeHash","Attempts","ExpiresAt","CreatedAt"
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+	`, c.CodeID, c.UserID, c.ChallengeID, string(c.Method), string(c.Purpose), c.CodeHash, c.Attempts, c.ExpiresAt, c.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *otpCodeRepoPG) GetActive(ctx context.Context, userID uuid.UUID, purpose domain.OTPPurpose, method domain.MFAMethod, now time.Time) (*domain.OTPCode, error) {
+	const q = `
+		SELECT "CodeID","UserID","ChallengeID","Method","Purpose","CodeHash","Attempts","ExpiresAt","ConsumedAt","CreatedAt"
+		FROM "MFAOTPCode"
+		WHERE "UserID" = $1 AND "Purpose" = $2 AND "Method" = $3 AND "ConsumedAt" IS NULL AND "ExpiresAt" > $4
+		ORDER BY "CreatedAt" DESC
+		LIMIT 1
+	`
+	var (
+		c                domain.OTPCode
+		methStr, purpStr string
+	)
+	if err := r.db.QueryRow(ctx, q, userID, string(purpose), string(method), now).Scan(
+		&c.CodeID, &c.UserID, &c.ChallengeID, &methStr, &purpStr, &c.CodeHash, &c.Attempts, &c.ExpiresAt, &c.ConsumedAt, &c.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	c.Method, c.Purpose = domain.MFAMethod(methStr), domain.OTPPurpose(purpStr)
+	return &c, nil
+}
+
+func (r *otpCodeRepoPG) IncrementAttempts(ctx context.Context, id uuid.UUID, max int) (bool, error) {
+	// cek dan tambah dalam satu statement: request paralel tidak bisa melewati max
+	tag, err := r.db.Exec(ctx, `
+		UPDATE "MFAOTPCode" SET "Attempts" = "Attempts" + 1
+		WHERE "CodeID" = $1 AND "Attempts" < $2
+	`, id, max)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *otpCodeRepoPG) Consume(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE "MFAOTPCode" SET "ConsumedAt" = $2
+		WHERE "CodeID" = $1 AND "ConsumedAt" IS NULL
+	`, id, at)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,50 @@
+// apps/cp-api/internal/repo/pg/otp_method_repository_pg.go
+package pg
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type otpMethodRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewOTPMethodRepositoryPG(db *pgxpool.Pool) contract.OTPMethodRepository {
+	return &otpMethodRepoPG{db: db}
+}
+
+func (r *otpMethodRepoPG) List(ctx context.Context, userID uuid.UUID) ([]domain.MFAMethod, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT "Method" FROM "MFAOTPMethod" WHERE "UserID" = $1 ORDER BY "EnabledAt"
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.MFAMethod
+	for rows.Next() {
+		var m string
+		if err := rows.Scan(&m); err != nil {
+			return nil, err
+		}
+		out = append(out, domain.MFAMethod(m))
+	}
+	return out, rows.Err()
+}
+
+func (r *otpMethodRepoPG) Enable(ctx context.Context, userID uuid.UUID, method domain.MFAMethod, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "MFAOTPMethod" ("UserID","Method","EnabledAt")
+		VALUES ($1,$2,$3)
+		ON CONFLICT ("UserID","Method") DO NOTHING
+	`, userID, string(method), at)
+	return err
+}
@@ -0,0 +1,75 @@
+// apps/cp-api/internal/repo/pg/password_history_repository_pg.go
+package pg
+
+import (
+	"context"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type passwordHistoryRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewPasswordHistoryRepositoryPG(db *pgxpool.Pool) contract.PasswordHistoryRepository {
+	return &passwordHistoryRepoPG{db: db}
+}
+
+func (r *passwordHistoryRepoPG) Add(ctx context.Context, e domain.PasswordHistoryEntry, keep int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO "PasswordHistory" ("UserID","PasswordHash","PasswordAlg","CreatedAt")
+		VALUES ($1,$2,$3,$4)
+	`, e.UserID, e.PasswordHash, string(e.PasswordAlg), e.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM "PasswordHistory"
+		WHERE "UserID" = $1 AND "HistoryID" NOT IN (
+			SELECT "HistoryID" FROM "PasswordHistory"
+			WHERE "UserID" = $1
+			ORDER BY "CreatedAt" DESC, "HistoryID" DESC
+			LIMIT $2
+		)
+	`, e.UserID, keep); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *passwordHistoryRepoPG) Recent(ctx context.Context, userID uuid.UUID, n int) ([]domain.PasswordHistoryEntry, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT "UserID","PasswordHash","PasswordAlg","CreatedAt"
+		FROM "PasswordHistory"
+		WHERE "UserID" = $1
+		ORDER BY "CreatedAt" DESC, "HistoryID" DESC
+		LIMIT $2
+	`, userID, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.PasswordHistoryEntry
+	for rows.Next() {
+		var (
+			e   domain.PasswordHistoryEntry
+			alg string
+		)
+		if err := rows.Scan(&e.UserID, &e.PasswordHash, &alg, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.PasswordAlg = domain.PasswordAlg(alg)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
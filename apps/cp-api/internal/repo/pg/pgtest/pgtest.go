@@ -0,0 +1,201 @@
+// Package pgtest: Postgres sekali pakai untuk integration test repo/pg.
+//
+// Sumber server, urut prioritas:
+//   - TEST_DATABASE_URL: server yang sudah jalan. Tiap Pool memakai schema
+//     baru yang di-drop setelah test, jadi data lain tidak tersentuh.
+//   - initdb/pg_ctl dari PG_BIN atau PATH: cluster sementara di temp dir,
+//     hanya lewat unix socket (tanpa TCP, tanpa network).
+//
+// Kalau keduanya tidak tersedia test di-skip, jadi `go test ./...` tetap
+// jalan di mesin tanpa Postgres.
+package pgtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/repo/pg/migrations"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	once    sync.Once
+	baseURL string // kosong = tidak ada server
+	skipMsg string
+	stopFn  = func() {}
+)
+
+// Main dipanggil dari TestMain supaya cluster sementara dihentikan setelah semua test.
+//
+//	func TestMain(m *testing.M) { pgtest.Main(m) }
+func Main(m *testing.M) {
+	code := m.Run()
+	stopFn()
+	os.Exit(code)
+}
+
+// Pool mengembalikan pool ke schema baru yang sudah dimigrasi penuh.
+// Schema di-drop dan pool ditutup lewat t.Cleanup.
+func Pool(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	once.Do(setup)
+	if baseURL == "" {
+		t.Skip(skipMsg)
+	}
+	ctx := context.Background()
+
+	schema := "pgtest_" + randHex(6)
+	admin, err := pgx.Connect(ctx, baseURL)
+	if err != nil {
+		t.Fatalf("pgtest: connect: %v", err)
+	}
+	defer admin.Close(ctx)
+	if _, err := admin.Exec(ctx, `CREATE SCHEMA `+pgx.Identifier{schema}.Sanitize()); err != nil {
+		t.Fatalf("pgtest: create schema: %v", err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(baseURL)
+	if err != nil {
+		t.Fatalf("pgtest: %v", err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("pgtest: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		if conn, err := pgx.Connect(context.Background(), baseURL); err == nil {
+			_, _ = conn.Exec(context.Background(), `DROP SCHEMA `+pgx.Identifier{schema}.Sanitize()+` CASCADE`)
+			conn.Close(context.Background())
+		}
+	})
+
+	m, err := migrations.New(pool)
+	if err != nil {
+		t.Fatalf("pgtest: %v", err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("pgtest: migrate: %v", err)
+	}
+	return pool
+}
+
+// Truncate mengosongkan tabel (dan tabel yang mereferensikannya).
+func Truncate(t testing.TB, pool *pgxpool.Pool, tables ...string) {
+	t.Helper()
+	names := make([]string, len(tables))
+	for i, tbl := range tables {
+		names[i] = pgx.Identifier{tbl}.Sanitize()
+	}
+	if _, err := pool.Exec(context.Background(), `TRUNCATE `+strings.Join(names, ", ")+` CASCADE`); err != nil {
+		t.Fatalf("pgtest: truncate: %v", err)
+	}
+}
+
+func setup() {
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		baseURL = url
+		return
+	}
+	initdb, pgctl, err := findBinaries()
+	if err != nil {
+		skipMsg = "pgtest: set TEST_DATABASE_URL or install Postgres (initdb, pg_ctl) to run integration tests: " + err.Error()
+		return
+	}
+	url, stop, err := startCluster(initdb, pgctl)
+	if err != nil {
+		skipMsg = "pgtest: start local Postgres: " + err.Error()
+		return
+	}
+	baseURL, stopFn = url, stop
+}
+
+func findBinaries() (initdb, pgctl string, err error) {
+	look := func(name string) (string, error) {
+		if dir := os.Getenv("PG_BIN"); dir != "" {
+			p := filepath.Join(dir, name)
+			if _, err := os.Stat(p); err != nil {
+				return "", err
+			}
+			return p, nil
+		}
+		if p, err := exec.LookPath(name); err == nil {
+			return p, nil
+		}
+		// Debian/Ubuntu tidak menaruh initdb di PATH
+		if ms, _ := filepath.Glob("/usr/lib/postgresql/*/bin/" + name); len(ms) > 0 {
+			return ms[len(ms)-1], nil
+		}
+		return "", fmt.Errorf("%s not found", name)
+	}
+	if initdb, err = look("initdb"); err != nil {
+		return "", "", err
+	}
+	if pgctl, err = look("pg_ctl"); err != nil {
+		return "", "", err
+	}
+	return initdb, pgctl, nil
+}
+
+// startCluster: initdb + pg_ctl start di temp dir; koneksi hanya lewat socket di dir yang sama.
+func startCluster(initdb, pgctl string) (string, func(), error) {
+	if os.Geteuid() == 0 {
+		return "", nil, errors.New("initdb refuses to run as root; set TEST_DATABASE_URL instead")
+	}
+	dir, err := os.MkdirTemp("", "cp-api-pgtest-")
+	if err != nil {
+		return "", nil, err
+	}
+	data := filepath.Join(dir, "data")
+	run := func(name string, args ...string) error {
+		out, err := exec.Command(name, args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s: %w\n%s", filepath.Base(name), err, out)
+		}
+		return nil
+	}
+
+	if err := run(initdb, "-D", data, "-U", "postgres", "--auth=trust", "-E", "UTF8", "--no-sync"); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	opts := fmt.Sprintf("-c listen_addresses='' -k %s -c fsync=off -c full_page_writes=off", dir)
+	if err := run(pgctl, "-D", data, "-o", opts, "-l", filepath.Join(dir, "log"), "-w", "-t", "30", "start"); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	stop := func() {
+		_ = run(pgctl, "-D", data, "-m", "immediate", "-w", "stop")
+		os.RemoveAll(dir)
+	}
+
+	url := fmt.Sprintf("postgres://postgres@/postgres?host=%s&sslmode=disable", dir)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := pgx.Connect(ctx, url)
+	if err != nil {
+		stop()
+		return "", nil, err
+	}
+	conn.Close(ctx)
+	return url, stop, nil
+}
+
+func randHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
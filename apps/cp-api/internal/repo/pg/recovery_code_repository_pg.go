@@ -0,0 +1,79 @@
+// apps/cp-api/internal/repo/pg/recovery_code_repository_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type recoveryCodeRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewRecoveryCodeRepositoryPG(db *pgxpool.Pool) contract.RecoveryCodeRepository {
+	return &recoveryCodeRepoPG{db: db}
+}
+
+func (r *recoveryCodeRepoPG) ReplaceAll(ctx context.Context, userID uuid.UUID, codes []domain.RecoveryCode) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx, `DELETE FROM "MFARecoveryCode" WHERE "UserID" = $1`, userID); err != nil {
+		return err
+	}
+	for _, c := range codes {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO "MFARecoveryCode" ("CodeID","UserID","Selector","CodeHash","CreatedAt")
+			VALUES ($1,$2,$3,$4,$5)
+		`, c.CodeID, userID, c.Selector, c.CodeHash, c.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *recoveryCodeRepoPG) GetUnused(ctx context.Context, userID uuid.UUID, selector string) (*domain.RecoveryCode, error) {
+	var c domain.RecoveryCode
+	err := r.db.QueryRow(ctx, `
+		SELECT "CodeID","UserID","Selector","CodeHash","UsedAt","CreatedAt"
+		FROM "MFARecoveryCode"
+		WHERE "UserID" = $1 AND "Selector" = $2 AND "UsedAt" IS NULL
+	`, userID, selector).Scan(&c.CodeID, &c.UserID, &c.Selector, &c.CodeHash, &c.UsedAt, &c.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *recoveryCodeRepoPG) MarkUsed(ctx context.Context, codeID uuid.UUID, at time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE "MFARecoveryCode" SET "UsedAt" = $2
+		WHERE "CodeID" = $1 AND "UsedAt" IS NULL
+	`, codeID, at)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *recoveryCodeRepoPG) CountUnused(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx, `
+		SELECT count(*) FROM "MFARecoveryCode" WHERE "UserID" = $1 AND "UsedAt" IS NULL
+	`, userID).Scan(&n)
+	return n, err
+}
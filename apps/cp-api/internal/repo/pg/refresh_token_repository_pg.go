@@ -0,0 +1,102 @@
+// apps/cp-api/internal/repo/pg/refresh_token_repository_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type refreshTokenRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewRefreshTokenRepositoryPG(db *pgxpool.Pool) contract.RefreshTokenRepository {
+	return &refreshTokenRepoPG{db: db}
+}
+
+const refreshTokenInsert = `
+	INSERT INTO "RefreshToken" (
+		"TokenID","FamilyID","UserID","TokenHash","ExpiresAt","CreatedAt"
+	) VALUES ($1,$2,$3,$4,$5,$6)`
+
+func (r *refreshTokenRepoPG) Create(ctx context.Context, t domain.RefreshToken) error {
+	_, err := r.db.Exec(ctx, refreshTokenInsert,
+		t.TokenID, t.FamilyID, t.UserID, t.TokenHash, t.ExpiresAt, t.CreatedAt,
+	)
+	return err
+}
+
+func (r *refreshTokenRepoPG) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	const q = `
+		SELECT
+			"TokenID","FamilyID","UserID","TokenHash","ExpiresAt","CreatedAt",
+			"UsedAt","RevokedAt","ReplacedBy"
+		FROM "RefreshToken"
+		WHERE "TokenHash" = $1
+	`
+	var t domain.RefreshToken
+	if err := r.db.QueryRow(ctx, q, hash).Scan(
+		&t.TokenID, &t.FamilyID, &t.UserID, &t.TokenHash, &t.ExpiresAt, &t.CreatedAt,
+		&t.UsedAt, &t.RevokedAt, &t.ReplacedBy,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *refreshTokenRepoPG) Rotate(ctx context.Context, oldID uuid.UUID, next domain.RefreshToken, at time.Time) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// UPDATE bersyarat: hanya satu request yang bisa menang
+	tag, err := tx.Exec(ctx, `
+		UPDATE "RefreshToken"
+		SET "UsedAt" = $2, "ReplacedBy" = $3
+		WHERE "TokenID" = $1 AND "UsedAt" IS NULL AND "RevokedAt" IS NULL
+	`, oldID, at, next.TokenID)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(ctx, refreshTokenInsert,
+		next.TokenID, next.FamilyID, next.UserID, next.TokenHash, next.ExpiresAt, next.CreatedAt,
+	); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+func (r *refreshTokenRepoPG) RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE "RefreshToken"
+		SET "RevokedAt" = $2
+		WHERE "FamilyID" = $1 AND "RevokedAt" IS NULL
+	`, familyID, at)
+	return err
+}
+
+func (r *refreshTokenRepoPG) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE "RefreshToken"
+		SET "RevokedAt" = $2
+		WHERE "UserID" = $1 AND "RevokedAt" IS NULL
+	`, userID, at)
+	return err
+}
@@ -0,0 +1,58 @@
+// apps/cp-api/internal/repo/pg/revocation_store_pg.go
+package pg
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type revocationStorePG struct {
+	db *pgxpool.Pool
+}
+
+func NewRevocationStorePG(db *pgxpool.Pool) contract.RevocationStore {
+	return &revocationStorePG{db: db}
+}
+
+func (r *revocationStorePG) RevokeToken(ctx context.Context, jti, userID uuid.UUID, expiresAt time.Time) error {
+	// entry yang sudah lewat exp tidak lagi berguna (token ditolak karena exp)
+	if _, err := r.db.Exec(ctx, `DELETE FROM "RevokedAccessToken" WHERE "ExpiresAt" < now()`); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "RevokedAccessToken" ("JTI","UserID","ExpiresAt","RevokedAt")
+		VALUES ($1,$2,$3,now())
+		ON CONFLICT ("JTI") DO NOTHING
+	`, jti, userID, expiresAt)
+	return err
+}
+
+func (r *revocationStorePG) RevokeUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	// iat di JWT presisi mikrodetik, sama dengan timestamptz
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "UserTokenRevocation" ("UserID","RevokedBefore")
+		VALUES ($1,$2)
+		ON CONFLICT ("UserID") DO UPDATE
+		SET "RevokedBefore" = GREATEST("UserTokenRevocation"."RevokedBefore", EXCLUDED."RevokedBefore")
+	`, userID, at.Truncate(time.Microsecond))
+	return err
+}
+
+func (r *revocationStorePG) IsRevoked(ctx context.Context, c contract.AccessClaims) (bool, error) {
+	const q = `
+		SELECT
+			EXISTS (SELECT 1 FROM "RevokedAccessToken" WHERE "JTI" = $1)
+			OR EXISTS (
+				SELECT 1 FROM "UserTokenRevocation"
+				WHERE "UserID" = $2 AND "RevokedBefore" > $3
+			)
+	`
+	var revoked bool
+	err := r.db.QueryRow(ctx, q, c.ID, c.UserID, c.IssuedAt).Scan(&revoked)
+	return revoked, err
+}
@@ -0,0 +1,230 @@
+// apps/cp-api/internal/repo/pg/role_repository_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type roleRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewRoleRepositoryPG(db *pgxpool.Pool) contract.RoleRepository {
+	return &roleRepoPG{db: db}
+}
+
+// roleSelect: role + permission-nya dalam satu baris (array urut).
+const roleSelect = `
+	SELECT r."RoleID", r."Name", r."Description", r."IsSystem",
+		r."CreatedAt", r."CreatedBy", r."UpdatedAt", r."UpdatedBy",
+		COALESCE(array_agg(rp."Permission" ORDER BY rp."Permission") FILTER (WHERE rp."Permission" IS NOT NULL), '{}')
+	FROM "Role" r
+	LEFT JOIN "RolePermission" rp ON rp."RoleID" = r."RoleID"`
+
+const roleNameIndex = "UX_Role_Name"
+
+func scanRole(row pgx.Row) (*domain.Role, error) {
+	var r domain.Role
+	if err := row.Scan(
+		&r.RoleID, &r.Name, &r.Description, &r.IsSystem,
+		&r.CreatedAt, &r.CreatedBy, &r.UpdatedAt, &r.UpdatedBy,
+		&r.Permissions,
+	); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *roleRepoPG) getOne(ctx context.Context, where string, arg any) (*domain.Role, error) {
+	role, err := scanRole(r.db.QueryRow(ctx, roleSelect+` WHERE `+where+` GROUP BY r."RoleID"`, arg))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return role, err
+}
+
+func (r *roleRepoPG) list(ctx context.Context, where string, args ...any) ([]domain.Role, error) {
+	rows, err := r.db.Query(ctx, roleSelect+` `+where+` GROUP BY r."RoleID" ORDER BY r."Name"`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *role)
+	}
+	return out, rows.Err()
+}
+
+func (r *roleRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*domain.Role, error) {
+	return r.getOne(ctx, `r."RoleID" = $1`, id)
+}
+
+func (r *roleRepoPG) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	return r.getOne(ctx, `r."Name" = $1`, name)
+}
+
+func (r *roleRepoPG) List(ctx context.Context) ([]domain.Role, error) {
+	return r.list(ctx, ``)
+}
+
+func (r *roleRepoPG) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.Role, error) {
+	return r.list(ctx, `WHERE r."RoleID" IN (SELECT "RoleID" FROM "UserRole" WHERE "UserID" = $1)`, userID)
+}
+
+func (r *roleRepoPG) Create(ctx context.Context, role domain.Role) (*domain.Role, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO "Role" ("RoleID","Name","Description","IsSystem","CreatedAt","CreatedBy","UpdatedAt","UpdatedBy")
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+	`, role.RoleID, role.Name, role.Description, role.IsSystem, role.CreatedAt, role.CreatedBy, role.UpdatedAt, role.UpdatedBy); err != nil {
+		return nil, roleWriteErr(err)
+	}
+	if err := setRolePermissions(ctx, tx, role.RoleID, role.Permissions); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, role.RoleID)
+}
+
+// Update: IsSystem dan Created* tidak diubah.
+func (r *roleRepoPG) Update(ctx context.Context, role domain.Role) (*domain.Role, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE "Role" SET "Name" = $2, "Description" = $3, "UpdatedAt" = $4, "UpdatedBy" = $5
+		WHERE "RoleID" = $1
+	`, role.RoleID, role.Name, role.Description, role.UpdatedAt, role.UpdatedBy)
+	if err != nil {
+		return nil, roleWriteErr(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM "RolePermission" WHERE "RoleID" = $1`, role.RoleID); err != nil {
+		return nil, err
+	}
+	if err := setRolePermissions(ctx, tx, role.RoleID, role.Permissions); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, role.RoleID)
+}
+
+func setRolePermissions(ctx context.Context, tx pgx.Tx, roleID uuid.UUID, perms []string) error {
+	if len(perms) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO "RolePermission" ("RoleID","Permission")
+		SELECT $1, p FROM unnest($2::text[]) AS p
+		ON CONFLICT DO NOTHING
+	`, roleID, perms)
+	return err
+}
+
+func (r *roleRepoPG) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM "Role" WHERE "RoleID" = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *roleRepoPG) UserIDs(ctx context.Context, roleID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, `SELECT "UserID" FROM "UserRole" WHERE "RoleID" = $1 ORDER BY "UserID"`, roleID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+func (r *roleRepoPG) Assign(ctx context.Context, userID, roleID uuid.UUID, by *uuid.UUID, at time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		INSERT INTO "UserRole" ("UserID","RoleID","CreatedAt","CreatedBy")
+		VALUES ($1,$2,$3,$4)
+		ON CONFLICT DO NOTHING
+	`, userID, roleID, at, by)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *roleRepoPG) Unassign(ctx context.Context, userID, roleID uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM "UserRole" WHERE "UserID" = $1 AND "RoleID" = $2`, userID, roleID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// LockAdmins: row lock pada role admin, dipegang transaksi terbuka sampai unlock.
+// lock_timeout supaya request yang antre tidak menahan koneksi pool selamanya.
+func (r *roleRepoPG) LockAdmins(ctx context.Context) (func(), error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	unlock := func() { _ = tx.Rollback(context.WithoutCancel(ctx)) }
+	if _, err := tx.Exec(ctx, `SET LOCAL lock_timeout = '10s'`); err != nil {
+		unlock()
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM "Role" WHERE "Name" = $1 FOR UPDATE`, domain.RoleAdmin); err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlock, nil
+}
+
+func (r *roleRepoPG) PermissionsFor(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT rp."Permission"
+		FROM "UserRole" ur
+		JOIN "RolePermission" rp ON rp."RoleID" = ur."RoleID"
+		WHERE ur."UserID" = $1
+		ORDER BY 1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// roleWriteErr: unique violation pada nama role -> contract.ErrRoleNameTaken
+func roleWriteErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == roleNameIndex {
+		return contract.ErrRoleNameTaken.Wrap(err)
+	}
+	return err
+}
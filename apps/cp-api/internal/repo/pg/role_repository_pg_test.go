@@ -0,0 +1,18 @@
+package pg
+
+import (
+	"testing"
+
+	"xeed/apps/cp-api/internal/repo/pg/pgtest"
+	"xeed/apps/cp-api/internal/repo/repotest"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+func TestRoleRepositoryPG(t *testing.T) {
+	pool := pgtest.Pool(t)
+	repotest.RoleRepository(t, func(t *testing.T) (contract.RoleRepository, contract.UserRepository) {
+		// "Permission" tetap (hasil seed migration, dirujuk RolePermission)
+		pgtest.Truncate(t, pool, "Role", "User")
+		return NewRoleRepositoryPG(pool), NewUserRepositoryPG(pool)
+	})
+}
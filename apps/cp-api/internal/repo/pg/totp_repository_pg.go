@@ -0,0 +1,75 @@
+// apps/cp-api/internal/repo/pg/totp_repository_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type totpRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewTOTPRepositoryPG(db *pgxpool.Pool) contract.TOTPRepository {
+	return &totpRepoPG{db: db}
+}
+
+func (r *totpRepoPG) Get(ctx context.Context, userID uuid.UUID) (*domain.TOTPCredential, error) {
+	const q = `
+		SELECT "UserID","SecretEnc","ConfirmedAt","LastUsedStep","CreatedAt"
+		FROM "UserTOTP"
+		WHERE "UserID" = $1
+	`
+	var c domain.TOTPCredential
+	if err := r.db.QueryRow(ctx, q, userID).Scan(
+		&c.UserID, &c.SecretEnc, &c.ConfirmedAt, &c.LastUsedStep, &c.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *totpRepoPG) Upsert(ctx context.Context, c domain.TOTPCredential) error {
+	// secret yang sudah dikonfirmasi tidak boleh ditimpa
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "UserTOTP" ("UserID","SecretEnc","ConfirmedAt","LastUsedStep","CreatedAt")
+		VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT ("UserID") DO UPDATE SET
+			"SecretEnc" = EXCLUDED."SecretEnc",
+			"ConfirmedAt" = EXCLUDED."ConfirmedAt",
+			"LastUsedStep" = EXCLUDED."LastUsedStep",
+			"CreatedAt" = EXCLUDED."CreatedAt"
+		WHERE "UserTOTP"."ConfirmedAt" IS NULL
+	`, c.UserID, c.SecretEnc, c.ConfirmedAt, c.LastUsedStep, c.CreatedAt)
+	return err
+}
+
+func (r *totpRepoPG) Confirm(ctx context.Context, userID uuid.UUID, step int64, at time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE "UserTOTP" SET "ConfirmedAt" = $2, "LastUsedStep" = $3
+		WHERE "UserID" = $1
+	`, userID, at, step)
+	return err
+}
+
+func (r *totpRepoPG) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE "UserTOTP" SET "LastUsedStep" = $2
+		WHERE "UserID" = $1 AND "LastUsedStep" < $2
+	`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
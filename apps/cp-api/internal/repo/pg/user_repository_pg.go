@@ -4,11 +4,15 @@ package pg
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"xeed/apps/cp-api/internal/domain"
 	"xeed/apps/cp-api/internal/usecase/contract"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -20,20 +24,16 @@ func NewUserRepositoryPG(db *pgxpool.Pool) contract.UserRepository {
 	return &userRepoPG{db: db}
 }
 
-func (r *userRepoPG) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	const q = `
-		SELECT
-			"UserID","Email","EmailVerifiedAt","PhoneE164","PhoneVerifiedAt",
-			"PasswordHash","PasswordAlg","PasswordUpdatedAt","MustChangePassword",
-			"Status","IsServiceAccount","DisplayName","AvatarURL",
-			"Locale","Timezone","Preferences","MFAEnrolled","MFADefaultMethod",
-			"LastLoginAt","LastLoginIP","CreatedAt","CreatedBy",
-			"UpdatedAt","UpdatedBy","IsDeleted"
-		FROM "User"
-		WHERE "Email" = $1 AND "IsDeleted" = FALSE
-	`
-	row := r.db.QueryRow(ctx, q, email)
+// Urutan kolom harus sama dengan scanUser
+const userColumns = `
+	"UserID","Email","EmailVerifiedAt","PhoneE164","PhoneVerifiedAt",
+	"PasswordHash","PasswordAlg","PasswordUpdatedAt","MustChangePassword",
+	"Status","IsServiceAccount","DisplayName","AvatarURL",
+	"Locale","Timezone","Preferences","MFAEnrolled","MFADefaultMethod",
+	"LastLoginAt","LastLoginIP","CreatedAt","CreatedBy",
+	"UpdatedAt","UpdatedBy","IsDeleted"`
 
+func scanUser(row pgx.Row) (*domain.User, error) {
 	var ur UserRow
 	if err := row.Scan(
 		&ur.UserID, &ur.Email, &ur.EmailVerifiedAt, &ur.PhoneE164, &ur.PhoneVerifiedAt,
@@ -43,9 +43,6 @@ func (r *userRepoPG) GetByEmail(ctx context.Context, email string) (*domain.User
 		&ur.LastLoginAt, &ur.LastLoginIP, &ur.CreatedAt, &ur.CreatedBy,
 		&ur.UpdatedAt, &ur.UpdatedBy, &ur.IsDeleted,
 	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, err
 	}
 	u, err := ur.ToDomain()
@@ -55,15 +52,32 @@ func (r *userRepoPG) GetByEmail(ctx context.Context, email string) (*domain.User
 	return &u, nil
 }
 
+// nil,nil kalau tidak ada
+func scanUserOrNil(row pgx.Row) (*domain.User, error) {
+	u, err := scanUser(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return u, err
+}
+
+func (r *userRepoPG) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	q := `SELECT ` + userColumns + `
+		FROM "User"
+		WHERE lower("Email") = lower($1) AND "IsDeleted" = FALSE`
+	return scanUserOrNil(r.db.QueryRow(ctx, q, email))
+}
+
+func (r *userRepoPG) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	q := `SELECT ` + userColumns + `
+		FROM "User"
+		WHERE "UserID" = $1 AND "IsDeleted" = FALSE`
+	return scanUserOrNil(r.db.QueryRow(ctx, q, id))
+}
+
 func (r *userRepoPG) Create(ctx context.Context, u domain.User) (*domain.User, error) {
-	const q = `
-		INSERT INTO "User" (
-			"UserID","Email","EmailVerifiedAt","PhoneE164","PhoneVerifiedAt",
-			"PasswordHash","PasswordAlg","PasswordUpdatedAt","MustChangePassword",
-			"Status","IsServiceAccount","DisplayName","AvatarURL",
-			"Locale","Timezone","Preferences","MFAEnrolled","MFADefaultMethod",
-			"LastLoginAt","LastLoginIP","CreatedAt","CreatedBy",
-			"UpdatedAt","UpdatedBy","IsDeleted"
+	q := `
+		INSERT INTO "User" (` + userColumns + `
 		) VALUES (
 			$1,$2,$3,$4,$5,
 			$6,$7,$8,$9,
@@ -72,14 +86,7 @@ func (r *userRepoPG) Create(ctx context.Context, u domain.User) (*domain.User, e
 			$19,COALESCE($20::inet, NULL),$21,$22,
 			$23,$24,$25
 		)
-		RETURNING 
-			"UserID","Email","EmailVerifiedAt","PhoneE164","PhoneVerifiedAt",
-			"PasswordHash","PasswordAlg","PasswordUpdatedAt","MustChangePassword",
-			"Status","IsServiceAccount","DisplayName","AvatarURL",
-			"Locale","Timezone","Preferences","MFAEnrolled","MFADefaultMethod",
-			"LastLoginAt","LastLoginIP","CreatedAt","CreatedBy",
-			"UpdatedAt","UpdatedBy","IsDeleted"
-	`
+		RETURNING ` + userColumns
 
 	row := r.db.QueryRow(ctx, q,
 		u.UserID, u.Email, u.EmailVerifiedAt, u.PhoneE164, u.PhoneVerifiedAt,
@@ -89,22 +96,128 @@ func (r *userRepoPG) Create(ctx context.Context, u domain.User) (*domain.User, e
 		u.LastLoginAt, u.LastLoginIP, u.CreatedAt, u.CreatedBy,
 		u.UpdatedAt, u.UpdatedBy, u.IsDeleted,
 	)
+	created, err := scanUser(row)
+	if err != nil {
+		return nil, userWriteErr(err)
+	}
+	return created, nil
+}
 
-	var ur UserRow
-	if err := row.Scan(
-		&ur.UserID, &ur.Email, &ur.EmailVerifiedAt, &ur.PhoneE164, &ur.PhoneVerifiedAt,
-		&ur.PasswordHash, &ur.PasswordAlg, &ur.PasswordUpdatedAt, &ur.MustChangePassword,
-		&ur.Status, &ur.IsServiceAccount, &ur.DisplayName, &ur.AvatarURL,
-		&ur.Locale, &ur.Timezone, &ur.Preferences, &ur.MFAEnrolled, &ur.MFADefaultMethod,
-		&ur.LastLoginAt, &ur.LastLoginIP, &ur.CreatedAt, &ur.CreatedBy,
-		&ur.UpdatedAt, &ur.UpdatedBy, &ur.IsDeleted,
-	); err != nil {
-		return nil, err
+func (r *userRepoPG) Update(ctx context.Context, u domain.User) (*domain.User, error) {
+	q := `
+		UPDATE "User" SET
+			"Email" = $2, "EmailVerifiedAt" = $3, "PhoneE164" = $4, "PhoneVerifiedAt" = $5,
+			"PasswordHash" = $6, "PasswordAlg" = $7, "PasswordUpdatedAt" = $8, "MustChangePassword" = $9,
+			"Status" = $10, "IsServiceAccount" = $11, "DisplayName" = $12, "AvatarURL" = $13,
+			"Locale" = $14, "Timezone" = $15, "Preferences" = COALESCE($16::jsonb, '{}'::jsonb),
+			"MFAEnrolled" = $17, "MFADefaultMethod" = $18,
+			"LastLoginAt" = $19, "LastLoginIP" = COALESCE($20::inet, NULL),
+			"UpdatedAt" = $21, "UpdatedBy" = $22, "IsDeleted" = $23
+		WHERE "UserID" = $1 AND "IsDeleted" = FALSE
+		RETURNING ` + userColumns
+
+	row := r.db.QueryRow(ctx, q,
+		u.UserID, u.Email, u.EmailVerifiedAt, u.PhoneE164, u.PhoneVerifiedAt,
+		u.PasswordHash, u.PasswordAlg, u.PasswordUpdatedAt, u.MustChangePassword,
+		u.Status, u.IsServiceAccount, u.DisplayName, u.AvatarURL,
+		u.Locale, u.Timezone, u.Preferences,
+		u.MFAEnrolled, u.MFADefaultMethod,
+		u.LastLoginAt, u.LastLoginIP,
+		u.UpdatedAt, u.UpdatedBy, u.IsDeleted,
+	)
+	updated, err := scanUserOrNil(row)
+	if err != nil {
+		return nil, userWriteErr(err)
+	}
+	return updated, nil
+}
+
+func (r *userRepoPG) SetStatus(ctx context.Context, id uuid.UUID, from, to domain.UserStatus, at time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE "User" SET "Status" = $3, "UpdatedAt" = $4
+		WHERE "UserID" = $1 AND "Status" = $2 AND "IsDeleted" = FALSE
+	`, id, from, to, at)
+	if err != nil {
+		return false, err
 	}
+	return tag.RowsAffected() > 0, nil
+}
 
-	user, err := ur.ToDomain()
+// List: keyset pagination di (CreatedAt, UserID); ambil limit+1 baris untuk tahu ada halaman berikutnya.
+func (r *userRepoPG) List(ctx context.Context, f contract.UserFilter) (*contract.UserPage, error) {
+	limit := f.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	dir, after := "ASC", ">"
+	if f.Sort == contract.UserSortCreatedDesc {
+		dir, after = "DESC", "<"
+	}
+	q := `SELECT ` + userColumns + `
+		FROM "User"
+		WHERE "IsDeleted" = FALSE
+			AND ($1 = '' OR "Status" = $1)
+			AND ($2 = '' OR "Email" ILIKE $2 ESCAPE '\' OR "DisplayName" ILIKE $2 ESCAPE '\')
+			AND ($3 = '' OR lower("Email") LIKE $3 ESCAPE '\')
+			AND ($4::boolean IS NULL OR "IsServiceAccount" = $4)
+			AND ($5::boolean IS NULL OR ("EmailVerifiedAt" IS NOT NULL) = $5)
+			AND ($6::timestamptz IS NULL OR "CreatedAt" >= $6)
+			AND ($7::timestamptz IS NULL OR "CreatedAt" < $7)
+			AND ($8::timestamptz IS NULL OR ("CreatedAt", "UserID") ` + after + ` ($8, $9::uuid))
+		ORDER BY "CreatedAt" ` + dir + `, "UserID" ` + dir + `
+		LIMIT $10`
+	pattern, prefix := "", ""
+	if f.Query != "" {
+		pattern = "%" + likeEscaper.Replace(f.Query) + "%"
+	}
+	if f.EmailPrefix != "" {
+		prefix = likeEscaper.Replace(strings.ToLower(f.EmailPrefix)) + "%"
+	}
+	var afterAt *time.Time
+	var afterID *uuid.UUID
+	if f.After != nil {
+		afterAt, afterID = &f.After.CreatedAt, &f.After.UserID
+	}
+	rows, err := r.db.Query(ctx, q, string(f.Status), pattern, prefix, f.ServiceAccount, f.EmailVerified,
+		f.CreatedFrom, f.CreatedTo, afterAt, afterID, limit+1)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	defer rows.Close()
+
+	var out []domain.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	page := &contract.UserPage{Users: out[:min(limit, len(out))]}
+	if len(out) > limit {
+		last := page.Users[limit-1]
+		page.Next = &contract.UserCursor{CreatedAt: last.CreatedAt, UserID: last.UserID}
+	}
+	return page, nil
+}
+
+// likeEscaper: input user dipakai literal di pola ILIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+const (
+	pgUniqueViolation = "23505"
+	userEmailIndex    = "UX_User_Email" // unique index lower("Email") untuk user yang belum dihapus
+)
+
+// userWriteErr: unique violation pada index email -> contract.ErrEmailTaken
+// (pesan pgx asli tetap ada sebagai penyebab untuk log).
+func userWriteErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == userEmailIndex {
+		return contract.ErrEmailTaken.Wrap(err)
+	}
+	return err
 }
@@ -0,0 +1,125 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/repo/pg/pgtest"
+	"xeed/apps/cp-api/internal/repo/repotest"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+func TestUserRepositoryPG(t *testing.T) {
+	pool := pgtest.Pool(t)
+	repotest.UserRepository(t, func(t *testing.T) contract.UserRepository {
+		pgtest.Truncate(t, pool, "User")
+		return NewUserRepositoryPG(pool)
+	})
+}
+
+// TestUserColumnMapping membaca kolom langsung dengan SQL: kolom yang tertukar
+// secara konsisten di INSERT dan scanUser tetap lolos round-trip, tapi tidak lolos di sini.
+func TestUserColumnMapping(t *testing.T) {
+	pool := pgtest.Pool(t)
+	ctx := context.Background()
+	u := repotest.FullUser("kolom@xeed.test")
+	if _, err := NewUserRepositoryPG(pool).Create(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		email, passwordAlg, status, locale, tz, ip  string
+		phone, hash, displayName, avatar, mfaMethod *string
+		emailVerifiedAt, phoneVerifiedAt, pwdAt     *time.Time
+		lastLoginAt                                 *time.Time
+		createdAt, updatedAt                        time.Time
+		createdBy, updatedBy                        *uuid.UUID
+		mustChange, svcAcct, mfa, deleted           bool
+		prefs                                       []byte
+	)
+	err := pool.QueryRow(ctx, `
+		SELECT "Email","EmailVerifiedAt","PhoneE164","PhoneVerifiedAt",
+			"PasswordHash","PasswordAlg","PasswordUpdatedAt","MustChangePassword",
+			"Status","IsServiceAccount","DisplayName","AvatarURL",
+			"Locale","Timezone","Preferences"::text,"MFAEnrolled","MFADefaultMethod",
+			"LastLoginAt",host("LastLoginIP"),"CreatedAt","CreatedBy",
+			"UpdatedAt","UpdatedBy","IsDeleted"
+		FROM "User" WHERE "UserID" = $1`, u.UserID).Scan(
+		&email, &emailVerifiedAt, &phone, &phoneVerifiedAt,
+		&hash, &passwordAlg, &pwdAt, &mustChange,
+		&status, &svcAcct, &displayName, &avatar,
+		&locale, &tz, &prefs, &mfa, &mfaMethod,
+		&lastLoginAt, &ip, &createdAt, &createdBy,
+		&updatedAt, &updatedBy, &deleted,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eqTime := func(a, b *time.Time) bool { return a != nil && b != nil && a.Equal(*b) }
+	var gotPrefs domain.Preferences
+	if err := json.Unmarshal(prefs, &gotPrefs); err != nil {
+		t.Fatalf("Preferences column %q: %v", prefs, err)
+	}
+	checks := []struct {
+		column string
+		ok     bool
+	}{
+		{"Email", email == u.Email},
+		{"EmailVerifiedAt", eqTime(emailVerifiedAt, u.EmailVerifiedAt)},
+		{"PhoneE164", phone != nil && *phone == *u.PhoneE164},
+		{"PhoneVerifiedAt", eqTime(phoneVerifiedAt, u.PhoneVerifiedAt)},
+		{"PasswordHash", hash != nil && *hash == *u.PasswordHash},
+		{"PasswordAlg", passwordAlg == string(u.PasswordAlg)},
+		{"PasswordUpdatedAt", eqTime(pwdAt, u.PasswordUpdatedAt)},
+		{"MustChangePassword", mustChange == u.MustChangePassword},
+		{"Status", status == string(u.Status)},
+		{"IsServiceAccount", svcAcct == u.IsServiceAccount},
+		{"DisplayName", displayName != nil && *displayName == *u.DisplayName},
+		{"AvatarURL", avatar != nil && *avatar == *u.AvatarURL},
+		{"Locale", locale == u.Locale},
+		{"Timezone", tz == u.Timezone},
+		{"Preferences", reflect.DeepEqual(gotPrefs, u.Preferences)},
+		{"MFAEnrolled", mfa == u.MFAEnrolled},
+		{"MFADefaultMethod", mfaMethod != nil && *mfaMethod == string(*u.MFADefaultMethod)},
+		{"LastLoginAt", eqTime(lastLoginAt, u.LastLoginAt)},
+		{"LastLoginIP", net.ParseIP(ip).Equal(*u.LastLoginIP)},
+		{"CreatedAt", createdAt.Equal(u.CreatedAt)},
+		{"CreatedBy", createdBy != nil && *createdBy == *u.CreatedBy},
+		{"UpdatedAt", updatedAt.Equal(u.UpdatedAt)},
+		{"UpdatedBy", updatedBy != nil && *updatedBy == *u.UpdatedBy},
+		{"IsDeleted", deleted == u.IsDeleted},
+	}
+	for _, c := range checks {
+		if !c.ok {
+			t.Errorf("column %q does not hold the value of the matching domain.User field", c.column)
+		}
+	}
+}
+
+func TestUserLastLoginIPv6(t *testing.T) {
+	pool := pgtest.Pool(t)
+	repo := NewUserRepositoryPG(pool)
+	ctx := context.Background()
+
+	u := repotest.NewUser(0, "ipv6@xeed.test")
+	ip := net.ParseIP("2001:db8::42")
+	u.LastLoginIP = &ip
+	if _, err := repo.Create(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetByID(ctx, u.UserID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByID = %v, %v", got, err)
+	}
+	if got.LastLoginIP == nil || !got.LastLoginIP.Equal(ip) {
+		t.Fatalf("LastLoginIP = %v; want %s", got.LastLoginIP, ip)
+	}
+}
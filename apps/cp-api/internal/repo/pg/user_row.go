@@ -3,7 +3,8 @@ package pg
 
 import (
 	"encoding/json"
-	"net" // ganti dari "net/netip" ke "net"
+	"fmt"
+	"net"
 	"time"
 	"xeed/apps/cp-api/internal/domain"
 
@@ -30,7 +31,7 @@ type UserRow struct {
 	MFAEnrolled        bool
 	MFADefaultMethod   *string
 	LastLoginAt        *time.Time
-	LastLoginIP        *string // simpan string inet (pgx bisa map langsung)
+	LastLoginIP        *net.IP // inet; pgx tidak bisa scan inet (binary) ke string
 	CreatedAt          time.Time
 	CreatedBy          *uuid.UUID
 	UpdatedAt          time.Time
@@ -41,14 +42,8 @@ type UserRow struct {
 func (r *UserRow) ToDomain() (domain.User, error) {
 	var prefs domain.Preferences
 	if len(r.Preferences) > 0 {
-		_ = json.Unmarshal(r.Preferences, &prefs)
-	}
-
-	// Samakan tipe dengan domain.User.LastLoginIP: *net.IP
-	var ip *net.IP
-	if r.LastLoginIP != nil {
-		if parsed := net.ParseIP(*r.LastLoginIP); parsed != nil {
-			ip = &parsed
+		if err := json.Unmarshal(r.Preferences, &prefs); err != nil {
+			return domain.User{}, fmt.Errorf("user %s: preferences: %w", r.UserID, err)
 		}
 	}
 
@@ -78,7 +73,7 @@ func (r *UserRow) ToDomain() (domain.User, error) {
 		MFAEnrolled:        r.MFAEnrolled,
 		MFADefaultMethod:   mfa,
 		LastLoginAt:        r.LastLoginAt,
-		LastLoginIP:        ip, // sekarang *net.IP
+		LastLoginIP:        r.LastLoginIP,
 		CreatedAt:          r.CreatedAt,
 		CreatedBy:          r.CreatedBy,
 		UpdatedAt:          r.UpdatedAt,
@@ -0,0 +1,54 @@
+// apps/cp-api/internal/repo/pg/webauthn_challenge_repository_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type webauthnChallengeRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewWebAuthnChallengeRepositoryPG(db *pgxpool.Pool) contract.WebAuthnChallengeRepository {
+	return &webauthnChallengeRepoPG{db: db}
+}
+
+func (r *webauthnChallengeRepoPG) Create(ctx context.Context, c domain.WebAuthnChallenge) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "WebAuthnChallenge" (
+			"ChallengeID","UserID","Purpose","Challenge","ExpiresAt","CreatedAt"
+		) VALUES ($1,$2,$3,$4,$5,$6)
+	`, c.ChallengeID, c.UserID, string(c.Purpose), c.Challenge, c.ExpiresAt, c.CreatedAt)
+	return err
+}
+
+func (r *webauthnChallengeRepoPG) Consume(ctx context.Context, id uuid.UUID, purpose domain.WebAuthnPurpose, now time.Time) (*domain.WebAuthnChallenge, error) {
+	const q = `
+		UPDATE "WebAuthnChallenge" SET "ConsumedAt" = $3
+		WHERE "ChallengeID" = $1 AND "Purpose" = $2 AND "ConsumedAt" IS NULL AND "ExpiresAt" > $3
+		RETURNING "ChallengeID","UserID","Purpose","Challenge","ExpiresAt","ConsumedAt","CreatedAt"
+	`
+	var (
+		c       domain.WebAuthnChallenge
+		purpStr string
+	)
+	if err := r.db.QueryRow(ctx, q, id, string(purpose), now).Scan(
+		&c.ChallengeID, &c.UserID, &purpStr, &c.Challenge, &c.ExpiresAt, &c.ConsumedAt, &c.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	c.Purpose = domain.WebAuthnPurpose(purpStr)
+	return &c, nil
+}
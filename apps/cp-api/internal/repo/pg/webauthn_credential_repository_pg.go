@@ -0,0 +1,97 @@
+// apps/cp-api/internal/repo/pg/webauthn_credential_repository_pg.go
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type webauthnCredentialRepoPG struct {
+	db *pgxpool.Pool
+}
+
+func NewWebAuthnCredentialRepositoryPG(db *pgxpool.Pool) contract.WebAuthnCredentialRepository {
+	return &webauthnCredentialRepoPG{db: db}
+}
+
+const webauthnCredentialColumns = `"CredentialID","UserID","Name","PublicKey","SignCount","AAGUID","Transports","CreatedAt","LastUsedAt"`
+
+func scanWebAuthnCredential(row pgx.Row) (*domain.WebAuthnCredential, error) {
+	var (
+		c     domain.WebAuthnCredential
+		count int64
+	)
+	if err := row.Scan(
+		&c.CredentialID, &c.UserID, &c.Name, &c.PublicKey, &count, &c.AAGUID, &c.Transports, &c.CreatedAt, &c.LastUsedAt,
+	); err != nil {
+		return nil, err
+	}
+	c.SignCount = uint32(count)
+	return &c, nil
+}
+
+func (r *webauthnCredentialRepoPG) Create(ctx context.Context, c domain.WebAuthnCredential) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO "WebAuthnCredential" (`+webauthnCredentialColumns+`)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+	`, c.CredentialID, c.UserID, c.Name, c.PublicKey, int64(c.SignCount), c.AAGUID, c.Transports, c.CreatedAt, c.LastUsedAt)
+	return err
+}
+
+func (r *webauthnCredentialRepoPG) GetByCredentialID(ctx context.Context, id []byte) (*domain.WebAuthnCredential, error) {
+	c, err := scanWebAuthnCredential(r.db.QueryRow(ctx, `
+		SELECT `+webauthnCredentialColumns+` FROM "WebAuthnCredential" WHERE "CredentialID" = $1
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *webauthnCredentialRepoPG) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+webauthnCredentialColumns+` FROM "WebAuthnCredential"
+		WHERE "UserID" = $1
+		ORDER BY "CreatedAt"
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.WebAuthnCredential
+	for rows.Next() {
+		c, err := scanWebAuthnCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
+
+func (r *webauthnCredentialRepoPG) UpdateSignCount(ctx context.Context, id []byte, count uint32, usedAt time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE "WebAuthnCredential" SET "SignCount" = $2, "LastUsedAt" = $3
+		WHERE "CredentialID" = $1
+	`, id, int64(count), usedAt)
+	return err
+}
+
+func (r *webauthnCredentialRepoPG) Delete(ctx context.Context, userID uuid.UUID, id []byte) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM "WebAuthnCredential" WHERE "CredentialID" = $1 AND "UserID" = $2
+	`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
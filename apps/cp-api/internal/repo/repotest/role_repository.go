@@ -0,0 +1,282 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// RoleRepository menjalankan suite konformansi role. newRepos dipanggil sekali
+// per subtest dan harus mengembalikan repository kosong; users dipakai untuk
+// membuat user yang diberi role (pg memeriksa foreign key).
+func RoleRepository(t *testing.T, newRepos func(t *testing.T) (contract.RoleRepository, contract.UserRepository)) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r contract.RoleRepository, users contract.UserRepository)
+	}{
+		{"CreateAndGetRoundTrip", testRoleCreateAndGet},
+		{"CreateDuplicateName", testRoleDuplicateName},
+		{"UpdateRoundTrip", testRoleUpdate},
+		{"ListOrderedByName", testRoleList},
+		{"Assignments", testRoleAssignments},
+		{"DeleteRemovesAssignments", testRoleDelete},
+		{"LockAdminsIsExclusive", testRoleLockAdmins},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, users := newRepos(t)
+			tc.fn(t, r, users)
+		})
+	}
+}
+
+// NewRole: role tanpa deskripsi dengan waktu baseTime.
+func NewRole(name string, perms ...string) domain.Role {
+	return domain.Role{
+		RoleID:      uuid.New(),
+		Name:        name,
+		Permissions: perms,
+		CreatedAt:   baseTime,
+		UpdatedAt:   baseTime,
+	}
+}
+
+func mustCreateRole(t *testing.T, r contract.RoleRepository, role domain.Role) *domain.Role {
+	t.Helper()
+	created, err := r.Create(context.Background(), role)
+	if err != nil {
+		t.Fatalf("Create(%s): %v", role.Name, err)
+	}
+	return created
+}
+
+func testRoleCreateAndGet(t *testing.T, r contract.RoleRepository, _ contract.UserRepository) {
+	ctx := context.Background()
+	desc, by := "Support team", uuid.New()
+	role := NewRole("support", domain.PermUsersWrite, domain.PermUsersRead)
+	role.Description, role.CreatedBy, role.UpdatedBy = &desc, &by, &by
+	role.IsSystem = true
+
+	want := role
+	want.Permissions = []string{domain.PermUsersRead, domain.PermUsersWrite} // urut
+	assertRoleEqual(t, "Create", want, *mustCreateRole(t, r, role))
+
+	got, err := r.GetByID(ctx, role.RoleID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByID = %v, %v", got, err)
+	}
+	assertRoleEqual(t, "GetByID", want, *got)
+	if got, err = r.GetByName(ctx, "support"); err != nil || got == nil || got.RoleID != role.RoleID {
+		t.Fatalf("GetByName = %v, %v", got, err)
+	}
+
+	if got, err := r.GetByID(ctx, uuid.New()); got != nil || err != nil {
+		t.Errorf("GetByID(missing) = %v, %v; want nil, nil", got, err)
+	}
+	if got, err := r.GetByName(ctx, "tidak-ada"); got != nil || err != nil {
+		t.Errorf("GetByName(missing) = %v, %v; want nil, nil", got, err)
+	}
+	empty := mustCreateRole(t, r, NewRole("kosong"))
+	if len(empty.Permissions) != 0 {
+		t.Errorf("Permissions = %v; want empty", empty.Permissions)
+	}
+}
+
+func testRoleDuplicateName(t *testing.T, r contract.RoleRepository, _ contract.UserRepository) {
+	mustCreateRole(t, r, NewRole("support"))
+	if _, err := r.Create(context.Background(), NewRole("support")); !errors.Is(err, contract.ErrRoleNameTaken) {
+		t.Fatalf("Create(duplicate) err = %v; want ErrRoleNameTaken", err)
+	}
+}
+
+func testRoleUpdate(t *testing.T, r contract.RoleRepository, _ contract.UserRepository) {
+	ctx := context.Background()
+	role := *mustCreateRole(t, r, NewRole("support", domain.PermUsersRead))
+	mustCreateRole(t, r, NewRole("auditor"))
+
+	desc, by := "Tim support", uuid.New()
+	role.Name, role.Description, role.UpdatedBy = "helpdesk", &desc, &by
+	role.Permissions = []string{domain.PermUsersWrite, domain.PermRolesRead}
+	role.UpdatedAt = baseTime.Add(time.Hour)
+	want := role
+	want.Permissions = []string{domain.PermRolesRead, domain.PermUsersWrite}
+
+	// IsSystem dan Created* tidak ikut diubah
+	changed := role
+	changed.IsSystem, changed.CreatedAt = true, baseTime.Add(time.Minute)
+	got, err := r.Update(ctx, changed)
+	if err != nil || got == nil {
+		t.Fatalf("Update = %v, %v", got, err)
+	}
+	assertRoleEqual(t, "Update", want, *got)
+	if got, _ = r.GetByID(ctx, role.RoleID); got == nil {
+		t.Fatal("role missing after Update")
+	}
+	assertRoleEqual(t, "GetByID after Update", want, *got)
+
+	role.Name = "auditor"
+	if _, err := r.Update(ctx, role); !errors.Is(err, contract.ErrRoleNameTaken) {
+		t.Errorf("Update(name taken) err = %v; want ErrRoleNameTaken", err)
+	}
+	if got, err := r.Update(ctx, NewRole("tidak-ada")); got != nil || err != nil {
+		t.Errorf("Update(missing) = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func testRoleList(t *testing.T, r contract.RoleRepository, _ contract.UserRepository) {
+	for _, name := range []string{"support", "admin", "auditor"} {
+		mustCreateRole(t, r, NewRole(name))
+	}
+	roles, err := r.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := roleNames(roles); got != "admin auditor support" {
+		t.Errorf("List = %s; want admin auditor support", got)
+	}
+}
+
+func testRoleAssignments(t *testing.T, r contract.RoleRepository, users contract.UserRepository) {
+	ctx := context.Background()
+	u := mustCreate(t, users, NewUser(0, "budi@xeed.test"))
+	other := mustCreate(t, users, NewUser(1, "ani@xeed.test"))
+	support := mustCreateRole(t, r, NewRole("support", domain.PermUsersRead, domain.PermUsersWrite))
+	auditor := mustCreateRole(t, r, NewRole("auditor", domain.PermUsersRead, domain.PermRolesRead))
+	mustCreateRole(t, r, NewRole("unused", domain.PermRolesWrite))
+
+	by := uuid.New()
+	for _, step := range []struct {
+		user, role uuid.UUID
+		want       bool
+	}{
+		{u.UserID, support.RoleID, true},
+		{u.UserID, support.RoleID, false}, // idempoten
+		{u.UserID, auditor.RoleID, true},
+		{other.UserID, support.RoleID, true},
+	} {
+		if added, err := r.Assign(ctx, step.user, step.role, &by, baseTime); err != nil || added != step.want {
+			t.Fatalf("Assign(%s, %s) = %t, %v; want %t", step.user, step.role, added, err, step.want)
+		}
+	}
+
+	roles, err := r.ListByUser(ctx, u.UserID)
+	if err != nil || roleNames(roles) != "auditor support" {
+		t.Fatalf("ListByUser = %s, %v; want auditor support", roleNames(roles), err)
+	}
+	perms, err := r.PermissionsFor(ctx, u.UserID)
+	if want := []string{domain.PermRolesRead, domain.PermUsersRead, domain.PermUsersWrite}; err != nil || !reflect.DeepEqual(perms, want) {
+		t.Fatalf("PermissionsFor = %v, %v; want %v", perms, err, want)
+	}
+	holders, err := r.UserIDs(ctx, support.RoleID)
+	want := []uuid.UUID{u.UserID, other.UserID}
+	slices.SortFunc(want, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	if err != nil || !reflect.DeepEqual(holders, want) {
+		t.Fatalf("UserIDs = %v, %v; want %v", holders, err, want)
+	}
+
+	if removed, err := r.Unassign(ctx, u.UserID, support.RoleID); err != nil || !removed {
+		t.Fatalf("Unassign = %t, %v; want true", removed, err)
+	}
+	if removed, err := r.Unassign(ctx, u.UserID, support.RoleID); err != nil || removed {
+		t.Fatalf("Unassign(again) = %t, %v; want false", removed, err)
+	}
+	if perms, _ := r.PermissionsFor(ctx, u.UserID); !reflect.DeepEqual(perms, []string{domain.PermRolesRead, domain.PermUsersRead}) {
+		t.Errorf("PermissionsFor after Unassign = %v", perms)
+	}
+	if perms, err := r.PermissionsFor(ctx, uuid.New()); err != nil || len(perms) != 0 {
+		t.Errorf("PermissionsFor(no roles) = %v, %v; want empty", perms, err)
+	}
+}
+
+func testRoleDelete(t *testing.T, r contract.RoleRepository, users contract.UserRepository) {
+	ctx := context.Background()
+	u := mustCreate(t, users, NewUser(0, "budi@xeed.test"))
+	role := mustCreateRole(t, r, NewRole("support", domain.PermUsersRead))
+	if _, err := r.Assign(ctx, u.UserID, role.RoleID, nil, baseTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := r.Delete(ctx, role.RoleID); err != nil || !ok {
+		t.Fatalf("Delete = %t, %v; want true", ok, err)
+	}
+	if got, err := r.GetByID(ctx, role.RoleID); got != nil || err != nil {
+		t.Errorf("GetByID(deleted) = %v, %v; want nil, nil", got, err)
+	}
+	if roles, err := r.ListByUser(ctx, u.UserID); err != nil || len(roles) != 0 {
+		t.Errorf("ListByUser after Delete = %s, %v; want none", roleNames(roles), err)
+	}
+	if perms, err := r.PermissionsFor(ctx, u.UserID); err != nil || len(perms) != 0 {
+		t.Errorf("PermissionsFor after Delete = %v, %v; want empty", perms, err)
+	}
+	if ok, err := r.Delete(ctx, role.RoleID); err != nil || ok {
+		t.Errorf("Delete(again) = %t, %v; want false", ok, err)
+	}
+}
+
+func roleNames(roles []domain.Role) string {
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = r.Name
+	}
+	return strings.Join(names, " ")
+}
+
+// assertRoleEqual: waktu dibandingkan dengan time.Equal, permission kosong = nil.
+func assertRoleEqual(t *testing.T, label string, want, got domain.Role) {
+	t.Helper()
+	norm := func(r domain.Role) domain.Role {
+		r.CreatedAt, r.UpdatedAt = r.CreatedAt.UTC(), r.UpdatedAt.UTC()
+		if len(r.Permissions) == 0 {
+			r.Permissions = nil
+		}
+		return r
+	}
+	if w, g := norm(want), norm(got); !reflect.DeepEqual(w, g) {
+		t.Errorf("%s = %+v; want %+v", label, g, w)
+	}
+}
+
+// Kunci kedua baru didapat setelah kunci pertama dilepas.
+func testRoleLockAdmins(t *testing.T, r contract.RoleRepository, _ contract.UserRepository) {
+	ctx := context.Background()
+	admin := NewRole(domain.RoleAdmin, domain.Permissions...)
+	admin.IsSystem = true
+	mustCreateRole(t, r, admin)
+
+	unlock, err := r.LockAdmins(ctx)
+	if err != nil {
+		t.Fatalf("LockAdmins: %v", err)
+	}
+	acquired := make(chan error, 1)
+	go func() {
+		unlock2, err := r.LockAdmins(ctx)
+		if err == nil {
+			unlock2()
+		}
+		acquired <- err
+	}()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("second LockAdmins returned while the first is held (err = %v)", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("second LockAdmins: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second LockAdmins still blocked after unlock")
+	}
+}
@@ -0,0 +1,536 @@
+// Package repotest: test konformansi yang dijalankan terhadap setiap
+// implementasi repository (memory, pg) supaya semantiknya tetap sama.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// baseTime: presisi mikrodetik supaya sama dengan timestamptz
+var baseTime = time.Date(2024, 3, 1, 8, 30, 0, 123456000, time.UTC)
+
+// UserRepository menjalankan suite konformansi. newRepo dipanggil sekali per
+// subtest dan harus mengembalikan repository kosong.
+func UserRepository(t *testing.T, newRepo func(t *testing.T) contract.UserRepository) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r contract.UserRepository)
+	}{
+		{"CreateAndGetRoundTrip", testUserCreateAndGet},
+		{"GetMissingReturnsNil", testUserGetMissing},
+		{"GetByEmailCaseInsensitive", testUserGetByEmailCaseInsensitive},
+		{"CreateDuplicateEmail", testUserCreateDuplicateEmail},
+		{"UpdateRoundTrip", testUserUpdate},
+		{"UpdateMissingReturnsNil", testUserUpdateMissing},
+		{"UpdateEmailTaken", testUserUpdateEmailTaken},
+		{"SoftDeleteHidesUser", testUserSoftDelete},
+		{"ReturnedValuesAreCopies", testUserCopies},
+		{"ListFilterAndOrder", testUserList},
+		{"ListPaging", testUserListPaging},
+		{"ConcurrentCreateSameEmail", testUserConcurrentCreate},
+		{"SetStatusCompareAndSet", testUserSetStatus},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newRepo(t))
+		})
+	}
+}
+
+// NewUser: user minimal yang valid; n menggeser CreatedAt supaya urutan deterministik.
+func NewUser(n int, email string) domain.User {
+	at := baseTime.Add(time.Duration(n) * time.Minute)
+	return domain.User{
+		UserID:      uuid.New(),
+		Email:       email,
+		PasswordAlg: domain.AlgNone,
+		Status:      domain.UserActive,
+		Locale:      "en",
+		Timezone:    "UTC",
+		Preferences: domain.Preferences{},
+		CreatedAt:   at,
+		UpdatedAt:   at,
+	}
+}
+
+// FullUser: semua kolom nullable terisi, untuk round-trip.
+func FullUser(email string) domain.User {
+	u := NewUser(0, email)
+	at := func(d time.Duration) *time.Time { v := baseTime.Add(d); return &v }
+	str := func(s string) *string { return &s }
+	method := domain.MFATOTP
+	ip := net.ParseIP("203.0.113.7")
+	actor := uuid.New()
+
+	u.EmailVerifiedAt = at(time.Hour)
+	u.PhoneE164 = str("+6281234567890")
+	u.PhoneVerifiedAt = at(2 * time.Hour)
+	u.PasswordHash = str("$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA")
+	u.PasswordAlg = domain.AlgArgon2id
+	u.PasswordUpdatedAt = at(3 * time.Hour)
+	u.MustChangePassword = true
+	u.Status = domain.UserLocked
+	u.IsServiceAccount = true
+	u.DisplayName = str("Budi Santoso")
+	u.AvatarURL = str("https://cdn.xeed.test/a/budi.png")
+	u.Locale = "id-ID"
+	u.Timezone = "Asia/Jakarta"
+	u.Preferences = domain.Preferences{
+		"theme":  "dark",
+		"beta":   true,
+		"nested": map[string]any{"pageSize": float64(25), "tags": []any{"a", "b"}},
+	}
+	u.MFAEnrolled = true
+	u.MFADefaultMethod = &method
+	u.LastLoginAt = at(4 * time.Hour)
+	u.LastLoginIP = &ip
+	u.CreatedBy = &actor
+	u.UpdatedBy = &actor
+	return u
+}
+
+func mustCreate(t *testing.T, r contract.UserRepository, u domain.User) *domain.User {
+	t.Helper()
+	created, err := r.Create(context.Background(), u)
+	if err != nil {
+		t.Fatalf("Create(%s): %v", u.Email, err)
+	}
+	return created
+}
+
+func testUserCreateAndGet(t *testing.T, r contract.UserRepository) {
+	ctx := context.Background()
+	want := FullUser("budi@xeed.test")
+
+	created := mustCreate(t, r, want)
+	AssertUserEqual(t, "Create", want, *created)
+
+	got, err := r.GetByID(ctx, want.UserID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByID = %v, %v", got, err)
+	}
+	AssertUserEqual(t, "GetByID", want, *got)
+
+	got, err = r.GetByEmail(ctx, want.Email)
+	if err != nil || got == nil {
+		t.Fatalf("GetByEmail = %v, %v", got, err)
+	}
+	AssertUserEqual(t, "GetByEmail", want, *got)
+}
+
+func testUserGetMissing(t *testing.T, r contract.UserRepository) {
+	ctx := context.Background()
+	mustCreate(t, r, NewUser(0, "ada@xeed.test"))
+
+	if u, err := r.GetByID(ctx, uuid.New()); u != nil || err != nil {
+		t.Fatalf("GetByID(unknown) = %v, %v; want nil, nil", u, err)
+	}
+	if u, err := r.GetByEmail(ctx, "tidak-ada@xeed.test"); u != nil || err != nil {
+		t.Fatalf("GetByEmail(unknown) = %v, %v; want nil, nil", u, err)
+	}
+}
+
+func testUserGetByEmailCaseInsensitive(t *testing.T, r contract.UserRepository) {
+	u := mustCreate(t, r, NewUser(0, "Siti.Aminah@Xeed.test"))
+
+	got, err := r.GetByEmail(context.Background(), "siti.aminah@XEED.TEST")
+	if err != nil || got == nil {
+		t.Fatalf("GetByEmail = %v, %v", got, err)
+	}
+	if got.UserID != u.UserID || got.Email != "Siti.Aminah@Xeed.test" {
+		t.Fatalf("GetByEmail = %s %q; want %s with email as stored", got.UserID, got.Email, u.UserID)
+	}
+}
+
+func testUserCreateDuplicateEmail(t *testing.T, r contract.UserRepository) {
+	mustCreate(t, r, NewUser(0, "dup@xeed.test"))
+
+	_, err := r.Create(context.Background(), NewUser(1, "DUP@xeed.test"))
+	if !errors.Is(err, contract.ErrEmailTaken) {
+		t.Fatalf("Create(duplicate) err = %v; want ErrEmailTaken", err)
+	}
+}
+
+func testUserUpdate(t *testing.T, r contract.UserRepository) {
+	ctx := context.Background()
+	orig := mustCreate(t, r, NewUser(0, "lama@xeed.test"))
+
+	next := FullUser("baru@xeed.test")
+	next.UserID = orig.UserID
+	next.UpdatedAt = baseTime.Add(24 * time.Hour)
+	// CreatedAt/CreatedBy tidak ikut di-update
+	next.CreatedAt = baseTime.Add(-24 * time.Hour)
+	next.CreatedBy = nil
+
+	want := next
+	want.CreatedAt, want.CreatedBy = orig.CreatedAt, orig.CreatedBy
+
+	updated, err := r.Update(ctx, next)
+	if err != nil || updated == nil {
+		t.Fatalf("Update = %v, %v", updated, err)
+	}
+	AssertUserEqual(t, "Update", want, *updated)
+
+	got, err := r.GetByID(ctx, orig.UserID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByID = %v, %v", got, err)
+	}
+	AssertUserEqual(t, "GetByID after Update", want, *got)
+
+	if u, err := r.GetByEmail(ctx, "lama@xeed.test"); u != nil || err != nil {
+		t.Fatalf("GetByEmail(old email) = %v, %v; want nil, nil", u, err)
+	}
+
+	// kolom nullable bisa dikosongkan lagi
+	cleared := NewUser(0, "baru@xeed.test")
+	cleared.UserID = orig.UserID
+	cleared.CreatedAt = orig.CreatedAt
+	updated, err = r.Update(ctx, cleared)
+	if err != nil || updated == nil {
+		t.Fatalf("Update(cleared) = %v, %v", updated, err)
+	}
+	AssertUserEqual(t, "Update(cleared)", cleared, *updated)
+}
+
+func testUserUpdateMissing(t *testing.T, r contract.UserRepository) {
+	u, err := r.Update(context.Background(), NewUser(0, "hantu@xeed.test"))
+	if u != nil || err != nil {
+		t.Fatalf("Update(unknown) = %v, %v; want nil, nil", u, err)
+	}
+}
+
+func testUserUpdateEmailTaken(t *testing.T, r contract.UserRepository) {
+	mustCreate(t, r, NewUser(0, "a@xeed.test"))
+	b := mustCreate(t, r, NewUser(1, "b@xeed.test"))
+
+	b.Email = "A@xeed.test"
+	if _, err := r.Update(context.Background(), *b); !errors.Is(err, contract.ErrEmailTaken) {
+		t.Fatalf("Update(email of other user) err = %v; want ErrEmailTaken", err)
+	}
+}
+
+func testUserSoftDelete(t *testing.T, r contract.UserRepository) {
+	ctx := context.Background()
+	u := mustCreate(t, r, NewUser(0, "hapus@xeed.test"))
+	keep := mustCreate(t, r, NewUser(1, "tetap@xeed.test"))
+
+	u.SoftDelete()
+	deleted, err := r.Update(ctx, *u)
+	if err != nil || deleted == nil {
+		t.Fatalf("Update(soft delete) = %v, %v", deleted, err)
+	}
+	if !deleted.IsDeleted || deleted.Status != domain.UserDeleted {
+		t.Fatalf("Update(soft delete) returned IsDeleted=%t Status=%s", deleted.IsDeleted, deleted.Status)
+	}
+
+	if got, err := r.GetByID(ctx, u.UserID); got != nil || err != nil {
+		t.Fatalf("GetByID(deleted) = %v, %v; want nil, nil", got, err)
+	}
+	if got, err := r.GetByEmail(ctx, u.Email); got != nil || err != nil {
+		t.Fatalf("GetByEmail(deleted) = %v, %v; want nil, nil", got, err)
+	}
+	if got, err := r.Update(ctx, *u); got != nil || err != nil {
+		t.Fatalf("Update(deleted) = %v, %v; want nil, nil", got, err)
+	}
+	list, err := r.List(ctx, contract.UserFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if ids := userIDs(list.Users); !reflect.DeepEqual(ids, []uuid.UUID{keep.UserID}) {
+		t.Fatalf("List = %v; want only %s", ids, keep.UserID)
+	}
+
+	// email user yang sudah dihapus boleh dipakai lagi
+	again := mustCreate(t, r, NewUser(2, "Hapus@xeed.test"))
+	if again.UserID == u.UserID {
+		t.Fatal("Create reused the deleted user's id")
+	}
+}
+
+func testUserCopies(t *testing.T, r contract.UserRepository) {
+	ctx := context.Background()
+	u := FullUser("salin@xeed.test")
+	created := mustCreate(t, r, u)
+
+	*created.DisplayName = "diubah"
+	created.Preferences["theme"] = "light"
+	*u.PhoneE164 = "+620000000"
+
+	got, err := r.GetByID(ctx, u.UserID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByID = %v, %v", got, err)
+	}
+	if *got.DisplayName != "Budi Santoso" || got.Preferences["theme"] != "dark" || *got.PhoneE164 != "+6281234567890" {
+		t.Fatalf("stored user changed through returned/input pointers: %q %v %q", *got.DisplayName, got.Preferences["theme"], *got.PhoneE164)
+	}
+}
+
+func testUserList(t *testing.T, r contract.UserRepository) {
+	ctx := context.Background()
+	name := func(s string) *string { return &s }
+	at := func(n int) *time.Time { v := baseTime.Add(time.Duration(n) * time.Minute); return &v }
+	yes, no := true, false
+
+	// dibuat tidak berurutan; List harus urut CreatedAt lalu UserID
+	c := NewUser(3, "citra@xeed.test")
+	c.Status = domain.UserLocked
+	a := NewUser(1, "andi@xeed.test")
+	a.DisplayName = name("Andi 100%")
+	b := NewUser(2, "budi@contoh.test")
+	b.DisplayName = name("Budi_X")
+	b.IsServiceAccount = true
+	d := NewUser(1, "dewi@xeed.test") // CreatedAt sama dengan a
+	d.EmailVerifiedAt = at(1)
+	for _, u := range []domain.User{c, a, b, d} {
+		mustCreate(t, r, u)
+	}
+	first, second := a.UserID, d.UserID
+	if first.String() > second.String() {
+		first, second = second, first
+	}
+
+	cases := []struct {
+		name string
+		f    contract.UserFilter
+		want []uuid.UUID
+	}{
+		{"all", contract.UserFilter{}, []uuid.UUID{first, second, b.UserID, c.UserID}},
+		{"desc", contract.UserFilter{Sort: contract.UserSortCreatedDesc}, []uuid.UUID{c.UserID, b.UserID, second, first}},
+		{"status", contract.UserFilter{Status: domain.UserLocked}, []uuid.UUID{c.UserID}},
+		{"service account", contract.UserFilter{ServiceAccount: &yes}, []uuid.UUID{b.UserID}},
+		{"not service account", contract.UserFilter{ServiceAccount: &no}, []uuid.UUID{first, second, c.UserID}},
+		{"verified", contract.UserFilter{EmailVerified: &yes}, []uuid.UUID{d.UserID}},
+		{"unverified", contract.UserFilter{EmailVerified: &no}, []uuid.UUID{a.UserID, b.UserID, c.UserID}},
+		{"email prefix", contract.UserFilter{EmailPrefix: "BU"}, []uuid.UUID{b.UserID}},
+		{"email prefix is not contains", contract.UserFilter{EmailPrefix: "xeed"}, nil},
+		{"email prefix wildcard literal", contract.UserFilter{EmailPrefix: "%"}, nil},
+		{"created range", contract.UserFilter{CreatedFrom: at(1), CreatedTo: at(3)}, []uuid.UUID{first, second, b.UserID}},
+		{"created from inclusive", contract.UserFilter{CreatedFrom: at(3)}, []uuid.UUID{c.UserID}},
+		{"query email", contract.UserFilter{Query: "XEED.test"}, []uuid.UUID{first, second, c.UserID}},
+		{"query display name", contract.UserFilter{Query: "budi_x"}, []uuid.UUID{b.UserID}},
+		{"query percent literal", contract.UserFilter{Query: "100%"}, []uuid.UUID{a.UserID}},
+		{"query underscore literal", contract.UserFilter{Query: "i_"}, []uuid.UUID{b.UserID}},
+		{"query and status", contract.UserFilter{Query: "citra", Status: domain.UserActive}, nil},
+	}
+	for _, tc := range cases {
+		got, err := r.List(ctx, tc.f)
+		if err != nil {
+			t.Fatalf("%s: List: %v", tc.name, err)
+		}
+		if ids := userIDs(got.Users); !reflect.DeepEqual(ids, tc.want) {
+			t.Errorf("%s: List = %v; want %v", tc.name, ids, tc.want)
+		}
+		if got.Next != nil {
+			t.Errorf("%s: Next = %+v; want nil (single page)", tc.name, got.Next)
+		}
+	}
+}
+
+func testUserListPaging(t *testing.T, r contract.UserRepository) {
+	ctx := context.Background()
+	// berpasangan dengan CreatedAt sama supaya batas halaman jatuh di tengah pasangan
+	var all []domain.User
+	for i := range 7 {
+		u := NewUser(i/2, fmt.Sprintf("u%d@xeed.test", i))
+		if i == 4 {
+			u.Status = domain.UserLocked
+		}
+		all = append(all, *mustCreate(t, r, u))
+	}
+	slices.SortFunc(all, func(a, b domain.User) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.UserID.String(), b.UserID.String())
+	})
+	asc := userIDs(all)
+	desc := slices.Clone(asc)
+	slices.Reverse(desc)
+	var active []uuid.UUID
+	for _, u := range all {
+		if u.Status == domain.UserActive {
+			active = append(active, u.UserID)
+		}
+	}
+
+	cases := []struct {
+		name  string
+		f     contract.UserFilter
+		want  []uuid.UUID
+		pages int
+	}{
+		{"asc", contract.UserFilter{Limit: 3}, asc, 3},
+		{"desc", contract.UserFilter{Limit: 3, Sort: contract.UserSortCreatedDesc}, desc, 3},
+		{"exact multiple", contract.UserFilter{Limit: 7}, asc, 1},
+		{"default limit", contract.UserFilter{}, asc, 1},
+		{"with filter", contract.UserFilter{Limit: 2, Status: domain.UserActive}, active, 3},
+	}
+	for _, tc := range cases {
+		var got []uuid.UUID
+		f, pages := tc.f, 0
+		for {
+			page, err := r.List(ctx, f)
+			if err != nil {
+				t.Fatalf("%s: List(%+v): %v", tc.name, f, err)
+			}
+			pages++
+			got = append(got, userIDs(page.Users)...)
+			if page.Next == nil || pages > len(all) {
+				break
+			}
+			last := page.Users[len(page.Users)-1]
+			if page.Next.UserID != last.UserID || !page.Next.CreatedAt.Equal(last.CreatedAt) {
+				t.Fatalf("%s: Next = %+v; want last user %s", tc.name, page.Next, last.UserID)
+			}
+			f.After = page.Next
+		}
+		if !reflect.DeepEqual(got, tc.want) || pages != tc.pages {
+			t.Errorf("%s: pages=%d %v; want pages=%d %v", tc.name, pages, got, tc.pages, tc.want)
+		}
+	}
+}
+
+func testUserConcurrentCreate(t *testing.T, r contract.UserRepository) {
+	const n = 10
+	var (
+		wg             sync.WaitGroup
+		mu             sync.Mutex
+		created, taken int
+	)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := r.Create(context.Background(), NewUser(i, "rebutan@xeed.test"))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created++
+			case errors.Is(err, contract.ErrEmailTaken):
+				taken++
+			default:
+				t.Errorf("Create: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if created != 1 || taken != n-1 {
+		t.Fatalf("concurrent Create: %d created, %d ErrEmailTaken; want 1 and %d", created, taken, n-1)
+	}
+}
+
+func testUserSetStatus(t *testing.T, r contract.UserRepository) {
+	ctx := context.Background()
+	full := FullUser("budi@xeed.test")
+	full.Status = domain.UserActive
+	u := mustCreate(t, r, full)
+	at := baseTime.Add(time.Hour)
+
+	if ok, err := r.SetStatus(ctx, u.UserID, domain.UserSuspended, domain.UserLocked, at); err != nil || ok {
+		t.Fatalf("SetStatus(wrong from) = %t, %v; want false", ok, err)
+	}
+	var wg sync.WaitGroup
+	var won atomic.Int32
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := r.SetStatus(ctx, u.UserID, domain.UserActive, domain.UserLocked, at)
+			if err != nil {
+				t.Errorf("SetStatus: %v", err)
+			}
+			if ok {
+				won.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if won.Load() != 1 {
+		t.Fatalf("concurrent SetStatus succeeded %d times; want 1", won.Load())
+	}
+
+	// kolom lain tidak ikut tertimpa
+	want := *u
+	want.Status, want.UpdatedAt = domain.UserLocked, at
+	got, err := r.GetByID(ctx, u.UserID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByID = %v, %v", got, err)
+	}
+	AssertUserEqual(t, "after SetStatus", want, *got)
+
+	if ok, err := r.SetStatus(ctx, uuid.New(), domain.UserActive, domain.UserLocked, at); err != nil || ok {
+		t.Errorf("SetStatus(unknown) = %t, %v; want false", ok, err)
+	}
+}
+
+func userIDs(us []domain.User) []uuid.UUID {
+	var out []uuid.UUID
+	for _, u := range us {
+		out = append(out, u.UserID)
+	}
+	return out
+}
+
+// AssertUserEqual membandingkan semua field domain.User. Waktu dibandingkan
+// dengan time.Equal (zona waktu dari DB bisa berbeda), IP dalam bentuk 16 byte.
+func AssertUserEqual(t *testing.T, label string, want, got domain.User) {
+	t.Helper()
+	w, g := reflect.ValueOf(normalizeUser(want)), reflect.ValueOf(normalizeUser(got))
+	for i := range w.NumField() {
+		if wf, gf := w.Field(i).Interface(), g.Field(i).Interface(); !reflect.DeepEqual(wf, gf) {
+			t.Errorf("%s: %s = %s; want %s", label, w.Type().Field(i).Name, show(gf), show(wf))
+		}
+	}
+}
+
+func normalizeUser(u domain.User) domain.User {
+	utc := func(p *time.Time) *time.Time {
+		if p == nil {
+			return nil
+		}
+		v := p.UTC()
+		return &v
+	}
+	u.EmailVerifiedAt = utc(u.EmailVerifiedAt)
+	u.PhoneVerifiedAt = utc(u.PhoneVerifiedAt)
+	u.PasswordUpdatedAt = utc(u.PasswordUpdatedAt)
+	u.LastLoginAt = utc(u.LastLoginAt)
+	u.CreatedAt = u.CreatedAt.UTC()
+	u.UpdatedAt = u.UpdatedAt.UTC()
+	if u.LastLoginIP != nil {
+		ip := u.LastLoginIP.To16()
+		u.LastLoginIP = &ip
+	}
+	if u.Preferences == nil {
+		u.Preferences = domain.Preferences{} // kolom JSONB default '{}'
+	}
+	return u
+}
+
+// show: nilai pointer ditampilkan isinya, bukan alamatnya.
+func show(v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "nil"
+		}
+		return fmt.Sprintf("&%v", rv.Elem().Interface())
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -2,25 +2,109 @@ package routers
 
 import (
 	"net/http"
+	"xeed/apps/cp-api/internal/domain"
 	"xeed/apps/cp-api/internal/http/handlers"
+	"xeed/apps/cp-api/internal/http/httperr"
+	"xeed/apps/cp-api/internal/http/middleware"
+	"xeed/apps/cp-api/internal/usecase/apperr"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	chimw "github.com/go-chi/chi/v5/middleware"
 )
 
-func InitRouter(userHandler *handlers.UserHandler) *chi.Mux {
+var errRouteNotFound = apperr.NotFound("route_not_found", "route not found")
+
+func InitRouter(
+	userHandler *handlers.UserHandler,
+	authHandler *handlers.AuthHandler,
+	jwksHandler *handlers.JWKSHandler,
+	mfaHandler *handlers.MFAHandler,
+	webauthnHandler *handlers.WebAuthnHandler,
+	adminUserHandler *handlers.AdminUserHandler,
+	adminRoleHandler *handlers.AdminRoleHandler,
+	authn *middleware.Authenticator,
+	authz *middleware.Authorization,
+) *chi.Mux {
 	r := chi.NewRouter()
-	r.Use(middleware.RequestID)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
+	r.Use(chimw.RequestID)
+	r.Use(chimw.Logger)
+	r.Use(chimw.Recoverer)
+
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		httperr.Write(w, r, errRouteNotFound)
+	})
 
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("ok"))
 	})
+	r.Get("/.well-known/jwks.json", jwksHandler.Get)
 
 	r.Route("/api/v1", func(r chi.Router) {
+		// public
 		r.Post("/users/register", userHandler.Register)
 		r.Post("/auth/login", userHandler.Login)
+		r.Post("/auth/refresh", authHandler.Refresh)
+		r.Post("/auth/verify-email", authHandler.VerifyEmail)
+		r.Post("/auth/verify-email/resend", authHandler.ResendVerification)
+		r.Post("/auth/password/forgot", authHandler.ForgotPassword)
+		r.Post("/auth/password/reset", authHandler.ResetPassword)
+		r.Post("/auth/mfa/verify", mfaHandler.Verify)
+		r.Post("/auth/mfa/webauthn/begin", mfaHandler.BeginWebAuthn)
+		r.Post("/auth/mfa/otp/send", mfaHandler.SendOTP)
+		r.Post("/auth/passkey/begin", webauthnHandler.BeginLogin)
+		r.Post("/auth/passkey/finish", webauthnHandler.FinishLogin)
+
+		// protected: butuh Bearer token
+		r.Group(func(r chi.Router) {
+			r.Use(authn.Required)
+			r.Post("/auth/logout", authHandler.Logout)
+			r.Post("/auth/logout-all", authHandler.LogoutAll)
+
+			r.Get("/me", userHandler.GetMe)
+			r.Patch("/me", userHandler.UpdateMe)
+			r.Post("/me/mfa/totp/enroll", mfaHandler.EnrollTOTP)
+			r.Post("/me/mfa/totp/confirm", mfaHandler.ConfirmTOTP)
+			r.Post("/me/mfa/otp/enroll", mfaHandler.EnrollOTP)
+			r.Post("/me/mfa/otp/confirm", mfaHandler.ConfirmOTP)
+			r.Post("/me/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+			r.Post("/me/webauthn/register/begin", webauthnHandler.BeginRegistration)
+			r.Post("/me/webauthn/register/finish", webauthnHandler.FinishRegistration)
+			r.Get("/me/webauthn/credentials", webauthnHandler.ListCredentials)
+			r.Delete("/me/webauthn/credentials/{id}", webauthnHandler.DeleteCredential)
+		})
+
+		// admin: permission dari role user, dibawa sebagai scope di access token
+		r.Group(func(r chi.Router) {
+			r.Use(authn.Required)
+			read, write := authz.RequirePermission(domain.PermUsersRead), authz.RequirePermission(domain.PermUsersWrite)
+			r.With(read).Get("/admin/users", adminUserHandler.List)
+			r.With(write).Post("/admin/users", adminUserHandler.Create)
+			r.With(read).Get("/admin/users/{id}", adminUserHandler.Get)
+			r.With(write).Patch("/admin/users/{id}", adminUserHandler.Update)
+			r.With(write).Delete("/admin/users/{id}", adminUserHandler.Delete)
+			r.With(write).Post("/admin/users/{id}/lock", adminUserHandler.Lock)
+			r.With(write).Post("/admin/users/{id}/suspend", adminUserHandler.Suspend)
+			r.With(write).Post("/admin/users/{id}/reactivate", adminUserHandler.Reactivate)
+			r.With(write).Post("/admin/users/{id}/force-password-change", adminUserHandler.ForcePasswordChange)
+			r.With(write).Post("/admin/users/{id}/reset-password", adminUserHandler.ResetPassword)
+
+			read, write = authz.RequirePermission(domain.PermRolesRead), authz.RequirePermission(domain.PermRolesWrite)
+			r.With(read).Get("/admin/permissions", adminRoleHandler.Permissions)
+			r.With(read).Get("/admin/roles", adminRoleHandler.List)
+			r.With(write).Post("/admin/roles", adminRoleHandler.Create)
+			r.With(read).Get("/admin/roles/{id}", adminRoleHandler.Get)
+			r.With(write).Patch("/admin/roles/{id}", adminRoleHandler.Update)
+			r.With(write).Delete("/admin/roles/{id}", adminRoleHandler.Delete)
+			r.With(read).Get("/admin/users/{id}/roles", adminRoleHandler.UserRoles)
+			r.With(write).Put("/admin/users/{id}/roles/{roleId}", adminRoleHandler.Assign)
+			r.With(write).Delete("/admin/users/{id}/roles/{roleId}", adminRoleHandler.Unassign)
+		})
+
+		// token terbatas (MustChangePassword) hanya bisa ke sini
+		r.Group(func(r chi.Router) {
+			r.Use(authn.AllowRestricted)
+			r.Post("/me/password", authHandler.ChangePassword)
+		})
 	})
 
 	return r
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"errors"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+)
+
+// AccountStatusError: login/refresh ditolak karena status akun bukan ACTIVE.
+// Hanya dikembalikan setelah password terverifikasi, jadi tidak membocorkan
+// keberadaan email.
+type AccountStatusError struct {
+	Status domain.UserStatus
+	Code   string // machine-readable, ex: "account_locked"
+	msg    string
+}
+
+func (e *AccountStatusError) Error() string { return e.msg }
+
+func (e *AccountStatusError) AppError() *apperr.Error {
+	kind := apperr.KindForbidden // pending, suspended, dsb
+	switch e.Status {
+	case domain.UserLocked:
+		kind = apperr.KindLocked
+	case domain.UserDeleted:
+		kind = apperr.KindGone
+	}
+	return apperr.New(kind, e.Code, e.msg)
+}
+
+var (
+	ErrAccountPending   = &AccountStatusError{Status: domain.UserPending, Code: "account_pending", msg: "account is pending email verification"}
+	ErrAccountLocked    = &AccountStatusError{Status: domain.UserLocked, Code: "account_locked", msg: "account is locked"}
+	ErrAccountSuspended = &AccountStatusError{Status: domain.UserSuspended, Code: "account_suspended", msg: "account is suspended"}
+	ErrAccountDeleted   = &AccountStatusError{Status: domain.UserDeleted, Code: "account_deleted", msg: "account has been deleted"}
+	ErrAccountInactive  = &AccountStatusError{Code: "account_inactive", msg: "account is not active"}
+)
+
+// checkAccountStatus: nil hanya untuk ACTIVE.
+func checkAccountStatus(u domain.User) error {
+	if u.IsDeleted {
+		return ErrAccountDeleted
+	}
+	switch u.Status {
+	case domain.UserActive:
+		return nil
+	case domain.UserPending:
+		return ErrAccountPending
+	case domain.UserLocked:
+		return ErrAccountLocked
+	case domain.UserSuspended:
+		return ErrAccountSuspended
+	case domain.UserDeleted:
+		return ErrAccountDeleted
+	default:
+		return ErrAccountInactive
+	}
+}
+
+// IsAccountStatusError memudahkan handler memetakan error status akun.
+func IsAccountStatusError(err error) (*AccountStatusError, bool) {
+	var se *AccountStatusError
+	if errors.As(err, &se) {
+		return se, true
+	}
+	return nil, false
+}
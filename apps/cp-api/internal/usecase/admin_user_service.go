@@ -0,0 +1,440 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidStatusTransition = apperr.Conflict("invalid_status_transition", "user status does not allow this action")
+	// admin tidak bisa mengunci dirinya sendiri keluar dari sistem
+	ErrSelfModification = apperr.Forbidden("cannot_modify_self", "this action cannot be applied to your own account")
+	// users:write tidak cukup untuk mengubah (mis. reset password) user yang aksesnya lebih luas
+	ErrTargetOutranksActor = apperr.Forbidden("target_outranks_actor", "this user has permissions you do not have")
+
+	ErrInvalidStatusFilter = fieldError("status", "invalid_status", "status must be one of PENDING, ACTIVE, LOCKED, SUSPENDED")
+	ErrInvalidSort         = fieldError("sort", "invalid_sort", "sort must be createdAt or -createdAt")
+	ErrInvalidLimit        = fieldError("limit", "invalid_limit", "limit must be between 1 and 500")
+	ErrInvalidCreatedRange = fieldError("createdTo", "invalid_created_range", "createdTo must be after createdFrom")
+)
+
+const maxUserListLimit = 500
+
+type adminUserService struct {
+	repo     contract.UserRepository
+	roles    contract.RoleRepository
+	clock    contract.Clock
+	idgen    contract.IDGen
+	hasher   contract.PasswordHasher
+	policy   contract.PasswordPolicy
+	sessions contract.SessionService
+	temp     contract.TempPasswordGen
+	guard    contract.LoginGuard // nil = tanpa proteksi brute-force
+}
+
+var _ contract.AdminUserService = (*adminUserService)(nil)
+
+func NewAdminUserService(
+	repo contract.UserRepository,
+	roles contract.RoleRepository,
+	clk contract.Clock,
+	idg contract.IDGen,
+	hasher contract.PasswordHasher,
+	policy contract.PasswordPolicy,
+	sessions contract.SessionService,
+	temp contract.TempPasswordGen,
+	guard contract.LoginGuard, // opsional
+) contract.AdminUserService {
+	if repo == nil {
+		panic("NewAdminUserService: repo is nil")
+	}
+	if roles == nil {
+		panic("NewAdminUserService: roles repo is nil")
+	}
+	if clk == nil {
+		panic("NewAdminUserService: clock is nil")
+	}
+	if idg == nil {
+		panic("NewAdminUserService: idgen is nil")
+	}
+	if hasher == nil {
+		panic("NewAdminUserService: hasher is nil")
+	}
+	if policy == nil {
+		panic("NewAdminUserService: policy is nil")
+	}
+	if sessions == nil {
+		panic("NewAdminUserService: sessions is nil")
+	}
+	if temp == nil {
+		panic("NewAdminUserService: temp password generator is nil")
+	}
+	return &adminUserService{repo: repo, roles: roles, clock: clk, idgen: idg, hasher: hasher, policy: policy, sessions: sessions, temp: temp, guard: guard}
+}
+
+// CreateUser: user langsung ACTIVE (tanpa email verifikasi).
+func (s *adminUserService) CreateUser(ctx context.Context, actor *uuid.UUID, in dto.AdminCreateUserRequest) (*dto.AdminCreateUserResponse, error) {
+	email := strings.ToLower(strings.TrimSpace(in.Email))
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+	var displayName *string
+	if in.DisplayName != nil {
+		v, err := validateDisplayName(*in.DisplayName)
+		if err != nil {
+			return nil, err
+		}
+		displayName = nilIfEmpty(v)
+	}
+
+	now := s.clock.Now()
+	u := domain.User{
+		UserID:      s.idgen.New(),
+		Email:       email,
+		DisplayName: displayName,
+		Locale:      "en",
+		Timezone:    "UTC",
+		Status:      domain.UserActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		CreatedBy:   actor,
+		UpdatedBy:   actor,
+	}
+	if in.EmailVerified {
+		u.VerifyEmail(now)
+	}
+
+	plain, temporary, err := s.password(ctx, in.Password, u)
+	if err != nil {
+		return nil, err
+	}
+	hash, alg, pwdAt, err := s.hasher.Hash(plain)
+	if err != nil {
+		return nil, err
+	}
+	u.PasswordAlg = alg
+	u.SetPasswordHash(hash, pwdAt, in.MustChangePassword || temporary)
+
+	created, err := s.repo.Create(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Remember(ctx, *created); err != nil {
+		return nil, err
+	}
+	resp := &dto.AdminCreateUserResponse{User: dto.ToAdminUserResponse(*created)}
+	if temporary {
+		resp.TemporaryPassword = plain
+	}
+	return resp, nil
+}
+
+func (s *adminUserService) Get(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	return s.get(ctx, userID)
+}
+
+// List: satu halaman daftar user, urut CreatedAt lalu UserID (keyset).
+func (s *adminUserService) List(ctx context.Context, in dto.AdminListUsersRequest) (*dto.AdminUserListResponse, error) {
+	f := contract.UserFilter{
+		Status:         domain.UserStatus(strings.ToUpper(strings.TrimSpace(in.Status))),
+		ServiceAccount: in.ServiceAccount,
+		EmailVerified:  in.EmailVerified,
+		EmailPrefix:    strings.TrimSpace(in.EmailPrefix),
+		Query:          strings.TrimSpace(in.Query),
+		CreatedFrom:    in.CreatedFrom,
+		CreatedTo:      in.CreatedTo,
+		Sort:           contract.UserSort(in.Sort),
+		Limit:          in.Limit,
+	}
+	switch f.Status {
+	case "", domain.UserPending, domain.UserActive, domain.UserLocked, domain.UserSuspended:
+	default:
+		return nil, ErrInvalidStatusFilter
+	}
+	switch f.Sort {
+	case "":
+		f.Sort = contract.UserSortCreatedAsc
+	case contract.UserSortCreatedAsc, contract.UserSortCreatedDesc:
+	default:
+		return nil, ErrInvalidSort
+	}
+	if f.Limit < 0 || f.Limit > maxUserListLimit {
+		return nil, ErrInvalidLimit
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedTo.After(*f.CreatedFrom) {
+		return nil, ErrInvalidCreatedRange
+	}
+	if in.Cursor != "" {
+		after, err := decodeUserCursor(in.Cursor, f.Sort)
+		if err != nil {
+			return nil, err
+		}
+		f.After = after
+	}
+
+	page, err := s.repo.List(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.AdminUserListResponse{Items: make([]dto.AdminUserResponse, len(page.Users))}
+	for i, u := range page.Users {
+		resp.Items[i] = dto.ToAdminUserResponse(u)
+	}
+	if page.Next != nil {
+		resp.NextCursor = encodeUserCursor(f.Sort, *page.Next)
+	}
+	return resp, nil
+}
+
+func (s *adminUserService) Update(ctx context.Context, actor *uuid.UUID, userID uuid.UUID, in dto.AdminUpdateUserRequest) (*domain.User, error) {
+	if err := s.ensureCanManage(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+	u, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProfile(u, in.UpdateProfileRequest); err != nil {
+		return nil, err
+	}
+	if in.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*in.Email))
+		if email != strings.ToLower(u.Email) {
+			if err := u.ChangeEmail(email); err != nil {
+				return nil, ErrInvalidEmail
+			}
+		}
+	}
+	if in.EmailVerified != nil {
+		switch {
+		case !*in.EmailVerified:
+			u.EmailVerifiedAt = nil
+		case u.EmailVerifiedAt == nil:
+			u.VerifyEmail(s.clock.Now())
+		}
+	}
+	return s.update(ctx, actor, *u) // ErrEmailTaken dari repo
+}
+
+// Delete: soft-delete; email bisa dipakai lagi untuk user baru.
+func (s *adminUserService) Delete(ctx context.Context, actor *uuid.UUID, userID uuid.UUID) error {
+	if isSelf(actor, userID) {
+		return ErrSelfModification
+	}
+	if err := s.ensureCanManage(ctx, actor, userID); err != nil {
+		return err
+	}
+	unlock, err := s.roles.LockAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	u, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := ensureOtherAdmin(ctx, s.roles, s.repo, *u); err != nil {
+		return err
+	}
+	u.SoftDelete()
+	if _, err := s.update(ctx, actor, *u); err != nil {
+		return err
+	}
+	return s.sessions.RevokeAll(ctx, userID)
+}
+
+// Lock & Suspend mencabut semua sesi user supaya berlaku segera; admin ACTIVE
+// terakhir tidak bisa dikunci / di-suspend (ErrLastAdmin).
+func (s *adminUserService) Lock(ctx context.Context, actor *uuid.UUID, userID uuid.UUID) (*domain.User, error) {
+	if isSelf(actor, userID) {
+		return nil, ErrSelfModification
+	}
+	return s.setStatus(ctx, actor, userID, (*domain.User).Lock, domain.UserActive, domain.UserPending, domain.UserSuspended, domain.UserLocked)
+}
+
+func (s *adminUserService) Suspend(ctx context.Context, actor *uuid.UUID, userID uuid.UUID) (*domain.User, error) {
+	if isSelf(actor, userID) {
+		return nil, ErrSelfModification
+	}
+	return s.setStatus(ctx, actor, userID, (*domain.User).Suspend, domain.UserActive, domain.UserPending, domain.UserLocked, domain.UserSuspended)
+}
+
+func (s *adminUserService) Reactivate(ctx context.Context, actor *uuid.UUID, userID uuid.UUID) (*domain.User, error) {
+	u, err := s.setStatus(ctx, actor, userID, (*domain.User).Activate, domain.UserLocked, domain.UserSuspended, domain.UserActive)
+	if err != nil {
+		return nil, err
+	}
+	// counter gagal login & kunci otomatis ikut dibersihkan
+	if s.guard != nil {
+		if err := s.guard.Success(ctx, u.Email); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// setStatus: transisi hanya dari status di from; transisi ke status yang sama tidak mengubah apa pun.
+func (s *adminUserService) setStatus(ctx context.Context, actor *uuid.UUID, userID uuid.UUID, apply func(*domain.User), from ...domain.UserStatus) (*domain.User, error) {
+	if err := s.ensureCanManage(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+	unlock, err := s.roles.LockAdmins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	u, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prev := u.Status
+	if !slices.Contains(from, prev) {
+		return nil, ErrInvalidStatusTransition
+	}
+	before := *u
+	apply(u)
+	if u.Status == prev {
+		return u, nil
+	}
+	if err := ensureOtherAdmin(ctx, s.roles, s.repo, before); err != nil {
+		return nil, err
+	}
+	updated, err := s.update(ctx, actor, *u)
+	if err != nil {
+		return nil, err
+	}
+	if updated.Status != domain.UserActive {
+		if err := s.sessions.RevokeAll(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+func (s *adminUserService) ResetPassword(ctx context.Context, actor *uuid.UUID, userID uuid.UUID, in dto.AdminResetPasswordRequest) (*dto.AdminResetPasswordResponse, error) {
+	if err := s.ensureCanManage(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+	u, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	plain, temporary, err := s.password(ctx, in.Password, *u)
+	if err != nil {
+		return nil, err
+	}
+	hash, alg, pwdAt, err := s.hasher.Hash(plain)
+	if err != nil {
+		return nil, err
+	}
+	u.PasswordAlg = alg
+	u.SetPasswordHash(hash, pwdAt, in.MustChangePassword || temporary)
+
+	updated, err := s.update(ctx, actor, *u)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Remember(ctx, *updated); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.RevokeAll(ctx, userID); err != nil {
+		return nil, err
+	}
+	if temporary {
+		return &dto.AdminResetPasswordResponse{TemporaryPassword: plain}, nil
+	}
+	return &dto.AdminResetPasswordResponse{}, nil
+}
+
+// SetMustChangePassword: sesi lama tetap berlaku; flag dicek lagi saat login/refresh berikutnya.
+func (s *adminUserService) SetMustChangePassword(ctx context.Context, actor *uuid.UUID, userID uuid.UUID, must bool) (*domain.User, error) {
+	if err := s.ensureCanManage(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+	u, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.MustChangePassword == must {
+		return u, nil
+	}
+	u.MustChangePassword = must
+	return s.update(ctx, actor, *u)
+}
+
+// password: plain dari operator (dicek policy) atau password sementara kalau kosong.
+func (s *adminUserService) password(ctx context.Context, plain string, u domain.User) (string, bool, error) {
+	if plain == "" {
+		tmp, err := s.temp.Generate()
+		return tmp, true, err
+	}
+	if err := s.policy.Check(ctx, plain, u); err != nil {
+		return "", false, err
+	}
+	return plain, false, nil
+}
+
+// ensureCanManage: actor hanya boleh mengubah user yang permission-nya subset
+// dari permission actor sendiri. actor nil (operator CLI) selalu boleh.
+func (s *adminUserService) ensureCanManage(ctx context.Context, actor *uuid.UUID, userID uuid.UUID) error {
+	if actor == nil {
+		return nil
+	}
+	mine, err := s.roles.PermissionsFor(ctx, *actor)
+	if err != nil {
+		return err
+	}
+	theirs, err := s.roles.PermissionsFor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, p := range theirs {
+		if !slices.Contains(mine, p) {
+			return ErrTargetOutranksActor
+		}
+	}
+	return nil
+}
+
+func (s *adminUserService) get(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (s *adminUserService) update(ctx context.Context, actor *uuid.UUID, u domain.User) (*domain.User, error) {
+	u.UpdatedAt = s.clock.Now()
+	u.UpdatedBy = actor
+	updated, err := s.repo.Update(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrUserNotFound
+	}
+	log.Printf("[admin] user %s updated by %s: status=%s mustChangePassword=%t", u.UserID, actorName(actor), updated.Status, updated.MustChangePassword)
+	return updated, nil
+}
+
+func isSelf(actor *uuid.UUID, userID uuid.UUID) bool { return actor != nil && *actor == userID }
+
+func actorName(actor *uuid.UUID) string {
+	if actor == nil {
+		return "operator"
+	}
+	return actor.String()
+}
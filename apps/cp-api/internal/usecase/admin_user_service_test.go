@@ -0,0 +1,428 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+type fixedTempPassword string
+
+func (p fixedTempPassword) Generate() (string, error) { return string(p), nil }
+
+func (e *testEnv) adminService() contract.AdminUserService {
+	return NewAdminUserService(e.users, e.roles, e.clock, e.ids, e.hasher, e.policy, e.sessions, fixedTempPassword("Sementara-123456"), nil)
+}
+
+func TestAdminCreateUser(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.adminService()
+	actor := uuid.MustParse("11111111-1111-4111-8111-111111111111")
+
+	resp, err := svc.CreateUser(context.Background(), &actor, dto.AdminCreateUserRequest{Email: "Admin@Xeed.test", EmailVerified: true})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if resp.TemporaryPassword != "Sementara-123456" {
+		t.Errorf("TemporaryPassword = %q", resp.TemporaryPassword)
+	}
+	u := e.mustGet(t, resp.User.UserID)
+	if u.Email != "admin@xeed.test" || u.Status != domain.UserActive || u.EmailVerifiedAt == nil {
+		t.Errorf("user = %q %s verifiedAt=%v", u.Email, u.Status, u.EmailVerifiedAt)
+	}
+	if !u.MustChangePassword {
+		t.Error("temporary password must force a password change")
+	}
+	if u.CreatedBy == nil || *u.CreatedBy != actor {
+		t.Errorf("CreatedBy = %v; want %s", u.CreatedBy, actor)
+	}
+
+	// password dari operator tetap dicek policy dan tidak dikembalikan
+	resp, err = svc.CreateUser(context.Background(), nil, dto.AdminCreateUserRequest{Email: "ops@xeed.test", Password: testPassword})
+	if err != nil {
+		t.Fatalf("CreateUser(password): %v", err)
+	}
+	if resp.TemporaryPassword != "" || e.mustGet(t, resp.User.UserID).MustChangePassword {
+		t.Errorf("CreateUser(password) = %+v; want no temporary password and no forced change", resp)
+	}
+	if _, err := svc.CreateUser(context.Background(), nil, dto.AdminCreateUserRequest{Email: "lemah@xeed.test", Password: "pendek"}); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("CreateUser(weak) err = %v; want ErrWeakPassword", err)
+	}
+	if _, err := svc.CreateUser(context.Background(), nil, dto.AdminCreateUserRequest{Email: "ADMIN@xeed.test"}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("CreateUser(duplicate) err = %v; want ErrEmailTaken", err)
+	}
+}
+
+func TestAdminStatusTransitions(t *testing.T) {
+	cases := []struct {
+		name    string
+		from    domain.UserStatus
+		action  func(contract.AdminUserService, context.Context, *uuid.UUID, uuid.UUID) (*domain.User, error)
+		want    domain.UserStatus
+		err     error
+		revoked bool
+	}{
+		{"lock active", domain.UserActive, contract.AdminUserService.Lock, domain.UserLocked, nil, true},
+		{"suspend locked", domain.UserLocked, contract.AdminUserService.Suspend, domain.UserSuspended, nil, true},
+		{"reactivate suspended", domain.UserSuspended, contract.AdminUserService.Reactivate, domain.UserActive, nil, false},
+		{"reactivate active", domain.UserActive, contract.AdminUserService.Reactivate, domain.UserActive, nil, false},
+		{"reactivate pending", domain.UserPending, contract.AdminUserService.Reactivate, domain.UserPending, ErrInvalidStatusTransition, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEnv(t)
+			u := e.seedUser(t, "budi@xeed.test", testPassword, func(u *domain.User) { u.Status = tc.from })
+			e.clock.Advance(time.Minute)
+
+			got, err := tc.action(e.adminService(), context.Background(), nil, u.UserID)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("err = %v; want %v", err, tc.err)
+			}
+			if stored := e.mustGet(t, u.UserID); stored.Status != tc.want {
+				t.Errorf("stored status = %s; want %s", stored.Status, tc.want)
+			}
+			if err == nil && got.Status != tc.want {
+				t.Errorf("returned status = %s; want %s", got.Status, tc.want)
+			}
+			if e.refresh.allRevoked(u.UserID) != tc.revoked {
+				t.Errorf("sessions revoked = %t; want %t", !tc.revoked, tc.revoked)
+			}
+		})
+	}
+}
+
+func TestAdminLockRevokesAccessTokens(t *testing.T) {
+	e := newTestEnv(t)
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	resp, err := e.sessions.Issue(context.Background(), u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.clock.Advance(time.Millisecond) // token yang diterbitkan bersamaan dengan revokasi tetap berlaku
+
+	if _, err := e.adminService().Lock(context.Background(), nil, u.UserID); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if _, err := e.sessions.Authenticate(context.Background(), resp.AccessToken); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("Authenticate after Lock err = %v; want ErrInvalidAccessToken", err)
+	}
+	if _, err := e.sessions.Refresh(context.Background(), dto.RefreshRequest{RefreshToken: resp.RefreshToken}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh after Lock err = %v; want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestAdminResetPassword(t *testing.T) {
+	e := newTestEnv(t)
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	svc := e.adminService()
+
+	resp, err := svc.ResetPassword(context.Background(), nil, u.UserID, dto.AdminResetPasswordRequest{})
+	if err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	stored := e.mustGet(t, u.UserID)
+	if !e.hasher.Verify(stored.PasswordAlg, resp.TemporaryPassword, *stored.PasswordHash) || !stored.MustChangePassword {
+		t.Errorf("temporary password not stored or change not forced")
+	}
+	if !e.refresh.allRevoked(u.UserID) {
+		t.Error("sessions not revoked")
+	}
+
+	if _, err := svc.ResetPassword(context.Background(), nil, uuid.New(), dto.AdminResetPasswordRequest{}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("ResetPassword(unknown) err = %v; want ErrUserNotFound", err)
+	}
+}
+
+func TestAdminUpdateUser(t *testing.T) {
+	e := newTestEnv(t)
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	e.seedUser(t, "ani@xeed.test", testPassword)
+	svc := e.adminService()
+	actor := uuid.MustParse("11111111-1111-4111-8111-111111111111")
+	str := func(s string) *string { return &s }
+	yes := true
+
+	got, err := svc.Update(context.Background(), &actor, u.UserID, dto.AdminUpdateUserRequest{
+		UpdateProfileRequest: dto.UpdateProfileRequest{DisplayName: str("Budi")},
+		Email:                str(" Budi.S@Xeed.test "),
+		EmailVerified:        &yes,
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got.Email != "budi.s@xeed.test" || got.DisplayName == nil || *got.DisplayName != "Budi" || got.EmailVerifiedAt == nil {
+		t.Errorf("updated = %q %v verifiedAt=%v", got.Email, got.DisplayName, got.EmailVerifiedAt)
+	}
+	if got.UpdatedBy == nil || *got.UpdatedBy != actor {
+		t.Errorf("UpdatedBy = %v; want %s", got.UpdatedBy, actor)
+	}
+
+	if _, err := svc.Update(context.Background(), nil, u.UserID, dto.AdminUpdateUserRequest{Email: str("ANI@xeed.test")}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Update(taken email) err = %v; want ErrEmailTaken", err)
+	}
+	if _, err := svc.Update(context.Background(), nil, u.UserID, dto.AdminUpdateUserRequest{Email: str("bukan-email")}); !errors.Is(err, ErrInvalidEmail) {
+		t.Errorf("Update(invalid email) err = %v; want ErrInvalidEmail", err)
+	}
+	if _, err := svc.Update(context.Background(), nil, uuid.New(), dto.AdminUpdateUserRequest{}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Update(unknown) err = %v; want ErrUserNotFound", err)
+	}
+}
+
+func TestAdminDeleteUser(t *testing.T) {
+	e := newTestEnv(t)
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	svc := e.adminService()
+
+	if err := svc.Delete(context.Background(), nil, u.UserID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := svc.Get(context.Background(), u.UserID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Get after Delete err = %v; want ErrUserNotFound", err)
+	}
+	if !e.refresh.allRevoked(u.UserID) {
+		t.Error("sessions not revoked")
+	}
+	// email bebas dipakai lagi
+	if _, err := svc.CreateUser(context.Background(), nil, dto.AdminCreateUserRequest{Email: "budi@xeed.test"}); err != nil {
+		t.Errorf("CreateUser(reused email): %v", err)
+	}
+}
+
+func TestAdminCannotModifySelf(t *testing.T) {
+	e := newTestEnv(t)
+	admin := e.seedUser(t, "admin@xeed.test", testPassword)
+	svc := e.adminService()
+	ctx := context.Background()
+	self := admin.UserID
+
+	if _, err := svc.Lock(ctx, &self, self); !errors.Is(err, ErrSelfModification) {
+		t.Errorf("Lock(self) err = %v; want ErrSelfModification", err)
+	}
+	if _, err := svc.Suspend(ctx, &self, self); !errors.Is(err, ErrSelfModification) {
+		t.Errorf("Suspend(self) err = %v; want ErrSelfModification", err)
+	}
+	if err := svc.Delete(ctx, &self, self); !errors.Is(err, ErrSelfModification) {
+		t.Errorf("Delete(self) err = %v; want ErrSelfModification", err)
+	}
+	if stored := e.mustGet(t, self); stored.Status != domain.UserActive {
+		t.Errorf("stored status = %s; want untouched", stored.Status)
+	}
+}
+
+// Admin ACTIVE terakhir tidak bisa dikunci, di-suspend, atau dihapus.
+func TestAdminKeepsLastActiveAdmin(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.adminService()
+	ctx := context.Background()
+	role := e.seedRole(t, domain.RoleAdmin, true, domain.Permissions...)
+	a := e.seedUser(t, "admin@xeed.test", testPassword)
+	b := e.seedUser(t, "admin2@xeed.test", testPassword)
+	for _, id := range []uuid.UUID{a.UserID, b.UserID} {
+		if _, err := e.roles.Assign(ctx, id, role.RoleID, nil, e.clock.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := svc.Lock(ctx, nil, a.UserID); err != nil {
+		t.Fatalf("Lock(first admin): %v", err)
+	}
+	if _, err := svc.Lock(ctx, nil, b.UserID); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("Lock(last admin) err = %v; want ErrLastAdmin", err)
+	}
+	if _, err := svc.Suspend(ctx, nil, b.UserID); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("Suspend(last admin) err = %v; want ErrLastAdmin", err)
+	}
+	if err := svc.Delete(ctx, nil, b.UserID); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("Delete(last admin) err = %v; want ErrLastAdmin", err)
+	}
+	if stored := e.mustGet(t, b.UserID); stored.Status != domain.UserActive {
+		t.Fatalf("last admin status = %s; want ACTIVE", stored.Status)
+	}
+	// admin yang terkunci boleh dihapus; ia tidak dihitung sebagai admin aktif
+	if err := svc.Delete(ctx, nil, a.UserID); err != nil {
+		t.Errorf("Delete(locked admin): %v", err)
+	}
+}
+
+// Dua admin terakhir dikunci bersamaan: hanya satu yang boleh berhasil.
+func TestAdminKeepsLastActiveAdminConcurrently(t *testing.T) {
+	for range 20 {
+		e := newTestEnv(t)
+		svc := e.adminService()
+		ctx := context.Background()
+		role := e.seedRole(t, domain.RoleAdmin, true, domain.Permissions...)
+		a := e.seedUser(t, "admin@xeed.test", testPassword)
+		b := e.seedUser(t, "admin2@xeed.test", testPassword)
+		for _, id := range []uuid.UUID{a.UserID, b.UserID} {
+			if _, err := e.roles.Assign(ctx, id, role.RoleID, nil, e.clock.Now()); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		errs := make(chan error, 2)
+		var start sync.WaitGroup
+		start.Add(1)
+		for _, id := range []uuid.UUID{a.UserID, b.UserID} {
+			go func() {
+				start.Wait()
+				_, err := svc.Suspend(ctx, nil, id)
+				errs <- err
+			}()
+		}
+		start.Done()
+		var ok, last int
+		for range 2 {
+			switch err := <-errs; {
+			case err == nil:
+				ok++
+			case errors.Is(err, ErrLastAdmin):
+				last++
+			default:
+				t.Fatalf("Suspend: %v", err)
+			}
+		}
+		if ok != 1 || last != 1 {
+			t.Fatalf("succeeded = %d, ErrLastAdmin = %d; want 1 and 1", ok, last)
+		}
+	}
+}
+
+// users:write tidak boleh dipakai untuk mengambil alih user yang aksesnya lebih luas.
+func TestAdminCannotModifyMorePrivilegedUser(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.adminService()
+	ctx := context.Background()
+	admin := e.seedRole(t, domain.RoleAdmin, true, domain.Permissions...)
+	support := e.seedRole(t, "support", false, domain.PermUsersRead, domain.PermUsersWrite)
+	boss := e.seedUser(t, "admin@xeed.test", testPassword)
+	helper := e.seedUser(t, "support@xeed.test", testPassword)
+	peer := e.seedUser(t, "support2@xeed.test", testPassword)
+	plain := e.seedUser(t, "budi@xeed.test", testPassword)
+	for _, a := range []struct{ user, role uuid.UUID }{
+		{boss.UserID, admin.RoleID}, {helper.UserID, support.RoleID}, {peer.UserID, support.RoleID},
+	} {
+		if _, err := e.roles.Assign(ctx, a.user, a.role, nil, e.clock.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	actions := map[string]func(actor *uuid.UUID, target uuid.UUID) error{
+		"ResetPassword": func(actor *uuid.UUID, target uuid.UUID) error {
+			_, err := svc.ResetPassword(ctx, actor, target, dto.AdminResetPasswordRequest{})
+			return err
+		},
+		"Update": func(actor *uuid.UUID, target uuid.UUID) error {
+			_, err := svc.Update(ctx, actor, target, dto.AdminUpdateUserRequest{})
+			return err
+		},
+		"SetMustChangePassword": func(actor *uuid.UUID, target uuid.UUID) error {
+			_, err := svc.SetMustChangePassword(ctx, actor, target, true)
+			return err
+		},
+		"Lock": func(actor *uuid.UUID, target uuid.UUID) error {
+			_, err := svc.Lock(ctx, actor, target)
+			return err
+		},
+		"Delete": func(actor *uuid.UUID, target uuid.UUID) error { return svc.Delete(ctx, actor, target) },
+	}
+	for name, do := range actions {
+		if err := do(&helper.UserID, boss.UserID); !errors.Is(err, ErrTargetOutranksActor) {
+			t.Errorf("%s(support -> admin) err = %v; want ErrTargetOutranksActor", name, err)
+		}
+	}
+	if stored := e.mustGet(t, boss.UserID); stored.Status != domain.UserActive || stored.MustChangePassword {
+		t.Errorf("admin changed by support: status=%s mustChangePassword=%t", stored.Status, stored.MustChangePassword)
+	}
+
+	// permission sama atau lebih sempit tetap boleh
+	for _, target := range []uuid.UUID{peer.UserID, plain.UserID} {
+		if _, err := svc.ResetPassword(ctx, &helper.UserID, target, dto.AdminResetPasswordRequest{}); err != nil {
+			t.Errorf("ResetPassword(support -> %s): %v", target, err)
+		}
+	}
+	if _, err := svc.ResetPassword(ctx, &boss.UserID, helper.UserID, dto.AdminResetPasswordRequest{}); err != nil {
+		t.Errorf("ResetPassword(admin -> support): %v", err)
+	}
+}
+
+func TestAdminListUsersCursor(t *testing.T) {
+	e := newTestEnv(t)
+	for _, email := range []string{"a@xeed.test", "b@xeed.test", "c@xeed.test", "d@xeed.test", "e@xeed.test"} {
+		e.seedUser(t, email, testPassword)
+		e.clock.Advance(time.Minute)
+	}
+	svc := e.adminService()
+	ctx := context.Background()
+
+	for _, sort := range []string{"", "-createdAt"} {
+		var emails []string
+		in := dto.AdminListUsersRequest{Sort: sort, Limit: 2}
+		for pages := 1; ; pages++ {
+			resp, err := svc.List(ctx, in)
+			if err != nil {
+				t.Fatalf("List(%q, page %d): %v", sort, pages, err)
+			}
+			for _, u := range resp.Items {
+				emails = append(emails, u.Email)
+			}
+			if resp.NextCursor == "" {
+				if pages != 3 {
+					t.Errorf("List(%q): %d pages; want 3", sort, pages)
+				}
+				break
+			}
+			in.Cursor = resp.NextCursor
+		}
+		want := "a@xeed.test b@xeed.test c@xeed.test d@xeed.test e@xeed.test"
+		if sort != "" {
+			want = "e@xeed.test d@xeed.test c@xeed.test b@xeed.test a@xeed.test"
+		}
+		if got := strings.Join(emails, " "); got != want {
+			t.Errorf("List(%q) = %s; want %s", sort, got, want)
+		}
+	}
+
+	// cursor terikat ke arah sort
+	resp, err := svc.List(ctx, dto.AdminListUsersRequest{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.List(ctx, dto.AdminListUsersRequest{Limit: 2, Sort: "-createdAt", Cursor: resp.NextCursor}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("List(cursor, other sort) err = %v; want ErrInvalidCursor", err)
+	}
+}
+
+func TestAdminListUsersRejects(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.adminService()
+	from := testNow
+	to := from.Add(-time.Hour)
+
+	cases := []struct {
+		name string
+		in   dto.AdminListUsersRequest
+		err  error
+	}{
+		{"status", dto.AdminListUsersRequest{Status: "DELETED"}, ErrInvalidStatusFilter},
+		{"sort", dto.AdminListUsersRequest{Sort: "email"}, ErrInvalidSort},
+		{"limit negative", dto.AdminListUsersRequest{Limit: -1}, ErrInvalidLimit},
+		{"limit too large", dto.AdminListUsersRequest{Limit: 501}, ErrInvalidLimit},
+		{"created range", dto.AdminListUsersRequest{CreatedFrom: &from, CreatedTo: &to}, ErrInvalidCreatedRange},
+		{"cursor not base64", dto.AdminListUsersRequest{Cursor: "!!"}, ErrInvalidCursor},
+		{"cursor not json", dto.AdminListUsersRequest{Cursor: "bm90LWpzb24"}, ErrInvalidCursor},
+	}
+	for _, tc := range cases {
+		if _, err := svc.List(context.Background(), tc.in); !errors.Is(err, tc.err) {
+			t.Errorf("%s: err = %v; want %v", tc.name, err, tc.err)
+		}
+	}
+}
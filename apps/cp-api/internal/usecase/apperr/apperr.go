@@ -0,0 +1,117 @@
+// Package apperr: model error usecase yang bertipe (Kind) dan punya kode
+// stabil untuk client. Layer HTTP memetakan Kind ke status code; usecase
+// tidak tahu apa-apa soal HTTP.
+package apperr
+
+import (
+	"errors"
+	"time"
+)
+
+type Kind uint8
+
+const (
+	KindInternal Kind = iota // default: detail tidak pernah dikirim ke client
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindLocked          // akun dikunci
+	KindGone            // resource sudah dihapus permanen
+	KindTooManyRequests // lihat Error.RetryAfter
+	KindUnavailable     // fitur tidak dikonfigurasi di server ini
+)
+
+// FieldError: detail validasi per field input (nama field mengikuti JSON request).
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind       Kind
+	Code       string // machine-readable, ex: "invalid_credentials"
+	Message    string // aman ditampilkan ke client
+	Fields     []FieldError
+	RetryAfter time.Duration // hanya untuk KindTooManyRequests
+	Err        error         // penyebab internal (opsional), hanya untuk log
+}
+
+func New(kind Kind, code, msg string) *Error {
+	return &Error{Kind: kind, Code: code, Message: msg}
+}
+
+// Validation: Fields kosong berarti error tidak terikat ke field tertentu.
+func Validation(code, msg string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: msg, Fields: fields}
+}
+
+// Field: satu FieldError; kode field sama dengan kode error-nya.
+func Field(field, code, msg string) FieldError {
+	return FieldError{Field: field, Code: code, Message: msg}
+}
+
+func Conflict(code, msg string) *Error     { return New(KindConflict, code, msg) }
+func NotFound(code, msg string) *Error     { return New(KindNotFound, code, msg) }
+func Unauthorized(code, msg string) *Error { return New(KindUnauthorized, code, msg) }
+func Forbidden(code, msg string) *Error    { return New(KindForbidden, code, msg) }
+
+// Internal membungkus error infrastruktur (DB, jaringan, dsb).
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal", Message: "internal error", Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is: dua *Error dianggap sama kalau Kind dan Code sama, jadi salinan
+// (ex: hasil Wrap) tetap cocok dengan sentinel-nya lewat errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap: salinan e dengan penyebab internal; kode & pesan untuk client tetap.
+func (e *Error) Wrap(cause error) *Error {
+	c := *e
+	c.Err = cause
+	return &c
+}
+
+// WithRetryAfter: salinan e dengan RetryAfter yang dihitung saat itu.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	c := *e
+	c.RetryAfter = d
+	return &c
+}
+
+// Typed diimplementasikan error usecase lain (ex: AccountStatusError) yang
+// punya representasi *Error.
+type Typed interface {
+	AppError() *Error
+}
+
+// From mengembalikan *Error untuk err; error yang tidak dikenal menjadi
+// KindInternal (pesan asli tidak dibocorkan, tetap tersedia di Err).
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var t Typed
+	if errors.As(err, &t) {
+		return t.AppError()
+	}
+	return Internal(err)
+}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"context"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+// scopeAuthorizer: keputusan dari permission yang dibawa access token (scope),
+// tanpa query per request. Scope tetap segar karena setiap pengurangan
+// permission mencabut sesi user (lihat roleService).
+type scopeAuthorizer struct{}
+
+var _ contract.Authorizer = scopeAuthorizer{}
+
+func NewAuthorizer() contract.Authorizer { return scopeAuthorizer{} }
+
+// Can: permission berlaku untuk semua resource; resource belum dipakai untuk aturan kepemilikan.
+func (scopeAuthorizer) Can(_ context.Context, p domain.Principal, permission, _ string) (bool, error) {
+	if p.IsRestricted() || !domain.IsPermission(permission) {
+		return false, nil
+	}
+	return p.HasScope(permission), nil
+}
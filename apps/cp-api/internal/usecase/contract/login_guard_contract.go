@@ -0,0 +1,28 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+)
+
+// Storage counter gagal login; harus shared antar replica (Postgres) di production.
+type LoginAttemptStore interface {
+	Get(ctx context.Context, key string) (domain.LoginAttempts, error) // zero value kalau tidak ada
+	// RecordFailure menambah counter; counter di-reset kalau gagal terakhir lebih lama dari window.
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (domain.LoginAttempts, error)
+	SetLockedUntil(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// Proteksi brute-force untuk login
+type LoginGuard interface {
+	// Check menolak (ThrottledError) kalau IP/akun masih dalam masa tunggu.
+	Check(ctx context.Context, email, ip string) error
+	// Failure mencatat gagal login; mengunci user (kalau ada) saat threshold tercapai.
+	Failure(ctx context.Context, email, ip string, u *domain.User) error
+	Success(ctx context.Context, email string) error
+	// AutoUnlock mengaktifkan kembali user yang dikunci otomatis dan masa kuncinya sudah lewat.
+	AutoUnlock(ctx context.Context, u *domain.User) error
+}
@@ -0,0 +1,79 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+type TOTPRepository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*domain.TOTPCredential, error) // nil,nil kalau tidak ada
+	// Upsert menyimpan secret baru (enrollment ulang selama belum dikonfirmasi).
+	Upsert(ctx context.Context, c domain.TOTPCredential) error
+	Confirm(ctx context.Context, userID uuid.UUID, step int64, at time.Time) error
+	// UseStep menyimpan step terakhir secara atomik; false kalau step <= step tersimpan (replay).
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+}
+
+type MFAChallengeRepository interface {
+	Create(ctx context.Context, c domain.MFAChallenge) error
+	// GetActive: nil,nil kalau tidak ada / expired / sudah dipakai.
+	GetActive(ctx context.Context, hash string, now time.Time) (*domain.MFAChallenge, error)
+	// IncrementAttempts memakai satu jatah percobaan secara atomik (hanya kalau
+	// Attempts < max); false kalau jatah habis / challenge tidak ada.
+	IncrementAttempts(ctx context.Context, id uuid.UUID, max int) (bool, error)
+	// Consume: false kalau sudah dipakai request lain.
+	Consume(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+}
+
+type RecoveryCodeRepository interface {
+	// ReplaceAll menghapus kode lama user dan menyimpan set baru (satu transaksi).
+	ReplaceAll(ctx context.Context, userID uuid.UUID, codes []domain.RecoveryCode) error
+	// GetUnused mencari kode yang belum dipakai lewat selector; nil,nil kalau tidak ada.
+	GetUnused(ctx context.Context, userID uuid.UUID, selector string) (*domain.RecoveryCode, error)
+	// MarkUsed: false kalau kode sudah dipakai request lain.
+	MarkUsed(ctx context.Context, codeID uuid.UUID, at time.Time) (bool, error)
+	CountUnused(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// Generator recovery code yang mudah diketik (ex: "k7qm-3xdp-9hwa")
+type RecoveryCodeGen interface {
+	Generate(n int) ([]string, error)
+	// Split: selector (disimpan apa adanya) dan secret (yang di-hash) dari kode
+	// yang sudah dinormalisasi; false kalau formatnya salah.
+	Split(code string) (selector, secret string, ok bool)
+}
+
+// TOTP generator/verifier (RFC 6238)
+type TOTP interface {
+	GenerateSecret() ([]byte, error)
+	EncodeSecret(secret []byte) string // base32, untuk input manual
+	URI(secret []byte, account string) string
+	Verify(secret []byte, code string, now time.Time) (step int64, ok bool)
+}
+
+// Enkripsi simetris untuk secret yang disimpan di DB
+type SecretBox interface {
+	Seal(plain []byte) ([]byte, error)
+	Open(sealed []byte) ([]byte, error)
+}
+
+type MFAService interface {
+	EnrollTOTP(ctx context.Context, p domain.Principal) (*dto.TOTPEnrollResponse, error)
+	// ConfirmTOTP mengaktifkan MFA dan mengembalikan recovery code (hanya ditampilkan sekali).
+	ConfirmTOTP(ctx context.Context, p domain.Principal, in dto.TOTPConfirmRequest) (*dto.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, p domain.Principal) (*dto.RecoveryCodesResponse, error)
+	RecoveryCodesRemaining(ctx context.Context, userID uuid.UUID) (int, error)
+	// Challenge dipanggil Login untuk user yang MFAEnrolled: token MFA, bukan access token.
+	Challenge(ctx context.Context, u domain.User) (*dto.LoginResponse, error)
+	BeginWebAuthn(ctx context.Context, in dto.MFAWebAuthnBeginRequest) (*dto.WebAuthnRequestOptions, error)
+	EnrollOTP(ctx context.Context, p domain.Principal, in dto.MFAOTPEnrollRequest) (*dto.OTPSentResponse, error)
+	// ConfirmOTP: recovery code hanya dikembalikan kalau ini faktor kedua pertama user.
+	ConfirmOTP(ctx context.Context, p domain.Principal, in dto.MFAOTPConfirmRequest) (*dto.RecoveryCodesResponse, error)
+	SendOTP(ctx context.Context, in dto.MFAOTPSendRequest) (*dto.OTPSentResponse, error)
+	Verify(ctx context.Context, in dto.MFAVerifyRequest) (*dto.LoginResponse, error)
+}
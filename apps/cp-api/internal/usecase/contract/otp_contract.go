@@ -0,0 +1,46 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, body string) error
+}
+
+type OTPCodeRepository interface {
+	// Create juga membatalkan kode aktif sebelumnya untuk user+purpose+method yang sama.
+	Create(ctx context.Context, c domain.OTPCode) error
+	GetActive(ctx context.Context, userID uuid.UUID, purpose domain.OTPPurpose, method domain.MFAMethod, now time.Time) (*domain.OTPCode, error)
+	// IncrementAttempts memakai satu jatah percobaan secara atomik (hanya kalau
+	// Attempts < max); false kalau jatah habis / kode tidak ada.
+	IncrementAttempts(ctx context.Context, id uuid.UUID, max int) (bool, error)
+	Consume(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+}
+
+// Metode OTP (email/sms) yang sudah diaktifkan user sebagai faktor kedua
+type OTPMethodRepository interface {
+	List(ctx context.Context, userID uuid.UUID) ([]domain.MFAMethod, error)
+	Enable(ctx context.Context, userID uuid.UUID, method domain.MFAMethod, at time.Time) error
+}
+
+// Generator kode numerik, ex: "048213"
+type OneTimeCodeGen interface {
+	Generate() (string, error)
+}
+
+// OTPService: siklus hidup kode OTP (buat, kirim, cek). Dipakai MFAService.
+type OTPService interface {
+	Send(ctx context.Context, u domain.User, method domain.MFAMethod, purpose domain.OTPPurpose, challengeID *uuid.UUID) (*dto.OTPSentResponse, error)
+	// Check: false kalau kode salah/expired/attempt habis.
+	Check(ctx context.Context, userID uuid.UUID, method domain.MFAMethod, purpose domain.OTPPurpose, challengeID *uuid.UUID, code string) (bool, error)
+	// EnabledMethods hanya mengembalikan metode yang tujuannya masih terverifikasi.
+	EnabledMethods(ctx context.Context, u domain.User) ([]domain.MFAMethod, error)
+	Enable(ctx context.Context, userID uuid.UUID, method domain.MFAMethod) error
+}
@@ -0,0 +1,28 @@
+package contract
+
+import (
+	"context"
+
+	"xeed/apps/cp-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type PasswordHistoryRepository interface {
+	// Add menyimpan entry baru dan hanya menyisakan keep entry terbaru.
+	Add(ctx context.Context, e domain.PasswordHistoryEntry, keep int) error
+	Recent(ctx context.Context, userID uuid.UUID, n int) ([]domain.PasswordHistoryEntry, error)
+}
+
+// BreachedPasswordChecker: cek password terhadap daftar password yang pernah bocor.
+type BreachedPasswordChecker interface {
+	IsBreached(ctx context.Context, plain string) (bool, error)
+}
+
+type PasswordPolicy interface {
+	// Check mengembalikan *usecase.PasswordPolicyError berisi semua pelanggaran.
+	// u = pemilik password (untuk cek email/nama & riwayat); user baru boleh belum tersimpan.
+	Check(ctx context.Context, plain string, u domain.User) error
+	// Remember mencatat hash password u saat ini ke riwayat.
+	Remember(ctx context.Context, u domain.User) error
+}
@@ -0,0 +1,61 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+
+	"github.com/google/uuid"
+)
+
+// ErrRoleNameTaken: nama role sudah dipakai. Dijamin atomik oleh repo (unique index).
+var ErrRoleNameTaken = apperr.Conflict("role_name_taken", "role name already exists")
+
+// Repository role, permission per role dan assignment user -> role
+type RoleRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Role, error)  // nil,nil kalau tidak ada
+	GetByName(ctx context.Context, name string) (*domain.Role, error) // nil,nil kalau tidak ada
+	List(ctx context.Context) ([]domain.Role, error)                  // urut Name
+	Create(ctx context.Context, r domain.Role) (*domain.Role, error)  // ErrRoleNameTaken
+	Update(ctx context.Context, r domain.Role) (*domain.Role, error)  // nil,nil kalau tidak ada; set permission diganti
+	Delete(ctx context.Context, id uuid.UUID) (bool, error)           // assignment ikut terhapus
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.Role, error)
+	UserIDs(ctx context.Context, roleID uuid.UUID) ([]uuid.UUID, error) // pemegang role
+	// Assign idempoten: false kalau user sudah memegang role.
+	Assign(ctx context.Context, userID, roleID uuid.UUID, by *uuid.UUID, at time.Time) (bool, error)
+	Unassign(ctx context.Context, userID, roleID uuid.UUID) (bool, error) // false kalau tidak dipegang
+	// LockAdmins mengunci role admin sampai unlock dipanggil (pg: row lock dalam
+	// transaksi). Cek "admin ACTIVE terakhir" dan perubahan sesudahnya dijalankan
+	// di bawah kunci ini supaya dua request paralel tidak sama-sama lolos.
+	LockAdmins(ctx context.Context) (unlock func(), err error)
+	PermissionResolver
+}
+
+// PermissionResolver: permission efektif user (gabungan semua role-nya), urut.
+// Dipakai SessionService untuk scope access token.
+type PermissionResolver interface {
+	PermissionsFor(ctx context.Context, userID uuid.UUID) ([]string, error)
+}
+
+// Authorizer memutuskan apakah principal boleh melakukan permission pada resource.
+// resource = ID objek yang disentuh (ex: user ID), kosong = koleksi / global.
+type Authorizer interface {
+	Can(ctx context.Context, p domain.Principal, permission, resource string) (bool, error)
+}
+
+// Pengelolaan role oleh admin. Perubahan yang mengurangi permission user
+// mencabut sesi user tersebut supaya scope lama di token tidak berlaku lagi.
+type RoleService interface {
+	List(ctx context.Context) ([]domain.Role, error)
+	Get(ctx context.Context, roleID uuid.UUID) (*domain.Role, error)
+	GetByName(ctx context.Context, name string) (*domain.Role, error)
+	Create(ctx context.Context, actor *uuid.UUID, in dto.CreateRoleRequest) (*domain.Role, error)
+	Update(ctx context.Context, actor *uuid.UUID, roleID uuid.UUID, in dto.UpdateRoleRequest) (*domain.Role, error)
+	Delete(ctx context.Context, actor *uuid.UUID, roleID uuid.UUID) error
+	UserRoles(ctx context.Context, userID uuid.UUID) ([]domain.Role, error)
+	Assign(ctx context.Context, actor *uuid.UUID, userID, roleID uuid.UUID) error
+	Unassign(ctx context.Context, actor *uuid.UUID, userID, roleID uuid.UUID) error
+}
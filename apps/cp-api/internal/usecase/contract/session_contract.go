@@ -0,0 +1,62 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// Repository refresh token (hanya hash yang disimpan)
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, t domain.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) // nil,nil kalau tidak ada
+	// Rotate menandai token lama sebagai used dan menyimpan penggantinya secara atomik.
+	// Return false kalau token lama sudah used/revoked (kalah race / replay).
+	Rotate(ctx context.Context, oldID uuid.UUID, next domain.RefreshToken, at time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
+
+// Store revokasi access token (JWT) sebelum exp
+type RevocationStore interface {
+	// RevokeToken mencabut satu token (jti); entry boleh dibuang setelah expiresAt.
+	RevokeToken(ctx context.Context, jti, userID uuid.UUID, expiresAt time.Time) error
+	// RevokeUser mencabut semua token user yang diterbitkan sebelum at (eksklusif),
+	// supaya token yang diterbitkan tepat setelah revokasi (ganti password) tetap berlaku.
+	RevokeUser(ctx context.Context, userID uuid.UUID, at time.Time) error
+	IsRevoked(ctx context.Context, c AccessClaims) (bool, error)
+}
+
+// Service sesi: penerbitan & rotasi token
+type SessionService interface {
+	Issue(ctx context.Context, u domain.User) (*dto.LoginResponse, error)
+	// IssueRestricted: access token tanpa refresh token, hanya untuk ganti password.
+	IssueRestricted(ctx context.Context, u domain.User) (*dto.LoginResponse, error)
+	Refresh(ctx context.Context, in dto.RefreshRequest) (*dto.LoginResponse, error)
+	// Authenticate memvalidasi access token (signature, exp, iss, aud, revokasi).
+	Authenticate(ctx context.Context, accessToken string) (*domain.Principal, error)
+	Logout(ctx context.Context, p domain.Principal) error    // sesi saat ini
+	LogoutAll(ctx context.Context, p domain.Principal) error // semua sesi user
+	RevokeAll(ctx context.Context, userID uuid.UUID) error   // dipakai flow lain (reset password, dsb)
+}
+
+// Generator token opaque (refresh token, dsb). Hash dipakai untuk lookup di DB.
+type OpaqueTokenGen interface {
+	New() (plain string, hash string, err error)
+	Hash(plain string) string
+}
+
+// Isi access token. IssuedAt & ExpiresAt diisi oleh verifier.
+type AccessClaims struct {
+	ID        uuid.UUID // jti
+	UserID    uuid.UUID
+	Email     string
+	SessionID uuid.UUID // = RefreshToken.FamilyID
+	Scopes    []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
@@ -6,20 +6,88 @@ import (
 
 	"xeed/apps/cp-api/internal/domain"
 	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/apperr"
 
 	"github.com/google/uuid"
 )
 
+// ErrEmailTaken: email (case-insensitive) sudah dipakai user lain yang belum dihapus.
+// Implementasi repo wajib menjaminnya secara atomik (ex: unique index), bukan cek-lalu-insert.
+var ErrEmailTaken = apperr.Conflict("email_taken", "email already registered")
+
 // Repository interface yang harus diimplementasikan infra (pg, mongo, dll)
 type UserRepository interface {
-	GetByEmail(ctx context.Context, email string) (*domain.User, error) // nil,nil kalau tidak ada
-	Create(ctx context.Context, u domain.User) (*domain.User, error)
+	GetByEmail(ctx context.Context, email string) (*domain.User, error) // nil,nil kalau tidak ada; case-insensitive
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)    // nil,nil kalau tidak ada
+	Create(ctx context.Context, u domain.User) (*domain.User, error)    // ErrEmailTaken kalau email sudah dipakai
+	Update(ctx context.Context, u domain.User) (*domain.User, error)    // nil,nil kalau tidak ada; ErrEmailTaken
+	List(ctx context.Context, f UserFilter) (*UserPage, error)          // tanpa user yang sudah dihapus
+	// SetStatus: compare-and-set atomik (hanya kolom Status & UpdatedAt).
+	// false kalau user tidak ada / status saat ini bukan from.
+	SetStatus(ctx context.Context, id uuid.UUID, from, to domain.UserStatus, at time.Time) (bool, error)
+}
+
+// UserSort: urutan List. Keyset selalu (CreatedAt, UserID), hanya arahnya yang berubah.
+type UserSort string
+
+const (
+	UserSortCreatedAsc  UserSort = "createdAt"
+	UserSortCreatedDesc UserSort = "-createdAt"
+)
+
+// UserCursor: posisi keyset = user terakhir di halaman sebelumnya.
+type UserCursor struct {
+	CreatedAt time.Time
+	UserID    uuid.UUID
+}
+
+// UserFilter: filter daftar user (admin). Nilai kosong = tidak difilter.
+type UserFilter struct {
+	Status         domain.UserStatus
+	ServiceAccount *bool
+	EmailVerified  *bool
+	EmailPrefix    string     // awal email, case-insensitive
+	Query          string     // potongan email / display name, case-insensitive
+	CreatedFrom    *time.Time // inklusif
+	CreatedTo      *time.Time // eksklusif
+	Sort           UserSort   // kosong = UserSortCreatedAsc
+	Limit          int        // 0 = default repo
+	After          *UserCursor
+}
+
+// UserPage: satu halaman List; Next nil kalau tidak ada halaman berikutnya.
+type UserPage struct {
+	Users []domain.User
+	Next  *UserCursor
 }
 
 // Service interface untuk layer bisnis
 type UserService interface {
 	RegisterUser(ctx context.Context, in dto.RegisterUserRequest) (*domain.User, error)
 	Login(ctx context.Context, in dto.LoginRequest) (*dto.LoginResponse, error)
+	GetProfile(ctx context.Context, userID uuid.UUID) (*domain.User, error)
+	GetMe(ctx context.Context, userID uuid.UUID) (*dto.MeResponse, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, in dto.UpdateProfileRequest) (*domain.User, error)
+}
+
+// Operasi user oleh operator/admin. actor = user yang melakukan perubahan
+// (dicatat di UpdatedBy/CreatedBy); nil untuk operator CLI.
+type AdminUserService interface {
+	CreateUser(ctx context.Context, actor *uuid.UUID, in dto.AdminCreateUserRequest) (*dto.AdminCreateUserResponse, error)
+	Get(ctx context.Context, userID uuid.UUID) (*domain.User, error)
+	List(ctx context.Context, in dto.AdminListUsersRequest) (*dto.AdminUserListResponse, error)
+	Update(ctx context.Context, actor *uuid.UUID, userID uuid.UUID, in dto.AdminUpdateUserRequest) (*domain.User, error)
+	Delete(ctx context.Context, actor *uuid.UUID, userID uuid.UUID) error // soft-delete + cabut semua sesi
+	Lock(ctx context.Context, actor *uuid.UUID, userID uuid.UUID) (*domain.User, error)
+	Suspend(ctx context.Context, actor *uuid.UUID, userID uuid.UUID) (*domain.User, error)
+	Reactivate(ctx context.Context, actor *uuid.UUID, userID uuid.UUID) (*domain.User, error) // LOCKED/SUSPENDED -> ACTIVE
+	ResetPassword(ctx context.Context, actor *uuid.UUID, userID uuid.UUID, in dto.AdminResetPasswordRequest) (*dto.AdminResetPasswordResponse, error)
+	SetMustChangePassword(ctx context.Context, actor *uuid.UUID, userID uuid.UUID, must bool) (*domain.User, error)
+}
+
+// Generator password sementara (dibuat admin, wajib diganti saat login).
+type TempPasswordGen interface {
+	Generate() (string, error)
 }
 
 // Adapter utilitas (Clock, UUID, PasswordHasher)
@@ -27,9 +95,21 @@ type Clock interface{ Now() time.Time }
 type IDGen interface{ New() uuid.UUID }
 type PasswordHasher interface {
 	Hash(plain string) (hash string, alg domain.PasswordAlg, updatedAt time.Time, err error)
-	Verify(plain, hash string) bool
+	// Verify memakai algoritma sesuai alg (User.PasswordAlg); alg kosong = deteksi dari format hash.
+	Verify(alg domain.PasswordAlg, plain, hash string) bool
+	// NeedsRehash: hash memakai algoritma/parameter lama dan sebaiknya di-upgrade.
+	NeedsRehash(alg domain.PasswordAlg, hash string) bool
 }
 
 type TokenSigner interface {
-	Sign(userID uuid.UUID, email string, now time.Time) (string, error)
+	Sign(c AccessClaims, now time.Time) (string, error)
+}
+
+type TokenVerifier interface {
+	Verify(token string, now time.Time) (*AccessClaims, error)
+}
+
+// Public key untuk verifikasi token oleh service lain (/.well-known/jwks.json)
+type JWKSProvider interface {
+	JWKS() dto.JWKS
 }
@@ -0,0 +1,40 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+// Repository token sekali-pakai (verifikasi email, dsb)
+type ActionTokenRepository interface {
+	Create(ctx context.Context, t domain.ActionToken) error
+	// GetActive membaca token tanpa memakainya (validasi input sebelum Consume).
+	GetActive(ctx context.Context, purpose domain.TokenPurpose, hash string, now time.Time) (*domain.ActionToken, error)
+	// Consume menandai token used secara atomik. nil,nil kalau tidak ada / expired / sudah dipakai.
+	Consume(ctx context.Context, purpose domain.TokenPurpose, hash string, now time.Time) (*domain.ActionToken, error)
+	// InvalidateForUser menandai semua token aktif user untuk purpose tsb sebagai used.
+	InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose domain.TokenPurpose, at time.Time) error
+}
+
+// Pengirim email (SMTP, provider, atau outbox lokal untuk dev)
+type EmailSender interface {
+	SendEmail(ctx context.Context, to, subject, body string) error
+}
+
+type VerificationService interface {
+	SendEmailVerification(ctx context.Context, u domain.User) error
+	ResendEmailVerification(ctx context.Context, in dto.ResendVerificationRequest) error
+	VerifyEmail(ctx context.Context, in dto.VerifyEmailRequest) (*domain.User, error)
+}
+
+type PasswordService interface {
+	ForgotPassword(ctx context.Context, in dto.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, in dto.ResetPasswordRequest) error
+	// ChangePassword mencabut semua sesi lama dan mengembalikan sesi baru.
+	ChangePassword(ctx context.Context, p domain.Principal, in dto.ChangePasswordRequest) (*dto.LoginResponse, error)
+}
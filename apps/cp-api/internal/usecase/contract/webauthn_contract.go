@@ -0,0 +1,61 @@
+package contract
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+
+	"github.com/google/uuid"
+)
+
+type WebAuthnCredentialRepository interface {
+	Create(ctx context.Context, c domain.WebAuthnCredential) error
+	GetByCredentialID(ctx context.Context, id []byte) (*domain.WebAuthnCredential, error) // nil, nil kalau tidak ada
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error)
+	UpdateSignCount(ctx context.Context, id []byte, count uint32, usedAt time.Time) error
+	Delete(ctx context.Context, userID uuid.UUID, id []byte) (bool, error)
+}
+
+type WebAuthnChallengeRepository interface {
+	Create(ctx context.Context, c domain.WebAuthnChallenge) error
+	// Consume mengambil challenge yang belum dipakai & belum expired lalu
+	// menandainya terpakai secara atomik; nil kalau tidak ada.
+	Consume(ctx context.Context, id uuid.UUID, purpose domain.WebAuthnPurpose, now time.Time) (*domain.WebAuthnChallenge, error)
+}
+
+// Hasil registration yang sudah diverifikasi
+type WebAuthnAttestation struct {
+	CredentialID   []byte
+	PublicKey      []byte // COSE_Key
+	AAGUID         []byte
+	SignCount      uint32
+	UserVerified   bool
+	BackupEligible bool
+}
+
+// Verifikasi kriptografis ceremony WebAuthn untuk satu Relying Party
+type WebAuthnVerifier interface {
+	RPID() string
+	NewChallenge() ([]byte, error)
+	VerifyRegistration(challenge, clientDataJSON, attestationObject []byte, requireUV bool) (*WebAuthnAttestation, error)
+	// VerifyAssertion mengembalikan signCount dari authenticator (clone check di usecase).
+	VerifyAssertion(challenge, clientDataJSON, authenticatorData, signature, publicKey []byte, requireUV bool) (uint32, error)
+}
+
+type WebAuthnService interface {
+	BeginRegistration(ctx context.Context, p domain.Principal) (*dto.WebAuthnCreationOptions, error)
+	FinishRegistration(ctx context.Context, p domain.Principal, in dto.WebAuthnRegistrationRequest) (*dto.WebAuthnCredentialResponse, error)
+	ListCredentials(ctx context.Context, p domain.Principal) ([]dto.WebAuthnCredentialResponse, error)
+	DeleteCredential(ctx context.Context, p domain.Principal, credentialID string) error
+
+	// login passkey tanpa password (discoverable credential)
+	BeginLogin(ctx context.Context) (*dto.WebAuthnRequestOptions, error)
+	FinishLogin(ctx context.Context, in dto.WebAuthnAssertion) (*dto.LoginResponse, error)
+
+	// faktor kedua, dipanggil MFAService
+	HasCredentials(ctx context.Context, userID uuid.UUID) (bool, error)
+	BeginMFA(ctx context.Context, userID uuid.UUID) (*dto.WebAuthnRequestOptions, error)
+	VerifyMFA(ctx context.Context, userID uuid.UUID, in dto.WebAuthnAssertion) (bool, error)
+}
@@ -0,0 +1,412 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/adapter/fake"
+	"xeed/apps/cp-api/internal/adapter/security"
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/repo/memory"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testNow = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+// testEnv: dependency bersama untuk test service, semuanya in-memory dan deterministik.
+type testEnv struct {
+	users    contract.UserRepository
+	clock    *fake.Clock
+	ids      *fake.IDGen
+	signer   *fake.TokenSigner
+	hasher   contract.PasswordHasher
+	policy   contract.PasswordPolicy
+	refresh  *refreshTokenStub
+	revoked  *memory.RevocationStore
+	roles    *memory.RoleRepository
+	sessions contract.SessionService
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	e := &testEnv{
+		users:   memory.NewUserRepository(),
+		clock:   fake.NewClock(testNow),
+		ids:     &fake.IDGen{},
+		signer:  &fake.TokenSigner{},
+		hasher:  security.BcryptHasher{Cost: bcrypt.MinCost},
+		refresh: &refreshTokenStub{byHash: map[string]domain.RefreshToken{}},
+		roles:   memory.NewRoleRepository(),
+	}
+	e.revoked = memory.NewRevocationStore(e.clock)
+	e.policy = NewPasswordPolicy(PasswordPolicyConfig{MinLength: 10, MinCharClasses: 2}, e.hasher, nil, nil)
+	e.sessions = NewSessionService(e.users, e.refresh, e.revoked, e.clock, e.ids, e.signer, e.signer, security.OpaqueTokens{}, time.Hour, e.roles)
+	return e
+}
+
+// seedUser menyimpan user ACTIVE dengan password plain.
+func (e *testEnv) seedUser(t *testing.T, email, plain string, opts ...func(*domain.User)) domain.User {
+	t.Helper()
+	hash, alg, at, err := e.hasher.Hash(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := e.clock.Now()
+	u := domain.User{
+		UserID:    e.ids.New(),
+		Email:     email,
+		Status:    domain.UserActive,
+		Locale:    "en",
+		Timezone:  "UTC",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	u.PasswordAlg = alg
+	u.SetPasswordHash(hash, at, false)
+	for _, opt := range opts {
+		opt(&u)
+	}
+	created, err := e.users.Create(context.Background(), u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *created
+}
+
+func (e *testEnv) mustGet(t *testing.T, id uuid.UUID) domain.User {
+	t.Helper()
+	u, err := e.users.GetByID(context.Background(), id)
+	if err != nil || u == nil {
+		t.Fatalf("GetByID(%s) = %v, %v", id, u, err)
+	}
+	return *u
+}
+
+type refreshTokenStub struct {
+	mu        sync.Mutex
+	byHash    map[string]domain.RefreshToken
+	revokedAt map[uuid.UUID]time.Time // RevokeAllForUser per user
+}
+
+func (s *refreshTokenStub) Create(_ context.Context, t domain.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byHash[t.TokenHash] = t
+	return nil
+}
+
+func (s *refreshTokenStub) GetByHash(_ context.Context, hash string) (*domain.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.byHash[hash]; ok {
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (s *refreshTokenStub) Rotate(_ context.Context, oldID uuid.UUID, next domain.RefreshToken, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for h, t := range s.byHash {
+		if t.TokenID != oldID {
+			continue
+		}
+		if t.IsUsed() || t.IsRevoked() {
+			return false, nil
+		}
+		t.UsedAt, t.ReplacedBy = &at, &next.TokenID
+		s.byHash[h] = t
+		s.byHash[next.TokenHash] = next
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *refreshTokenStub) RevokeFamily(_ context.Context, familyID uuid.UUID, at time.Time) error {
+	s.revokeWhere(func(t domain.RefreshToken) bool { return t.FamilyID == familyID }, at)
+	return nil
+}
+
+func (s *refreshTokenStub) RevokeAllForUser(_ context.Context, userID uuid.UUID, at time.Time) error {
+	s.revokeWhere(func(t domain.RefreshToken) bool { return t.UserID == userID }, at)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.revokedAt == nil {
+		s.revokedAt = map[uuid.UUID]time.Time{}
+	}
+	s.revokedAt[userID] = at
+	return nil
+}
+
+func (s *refreshTokenStub) revokeWhere(match func(domain.RefreshToken) bool, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for h, t := range s.byHash {
+		if match(t) && t.RevokedAt == nil {
+			t.RevokedAt = &at
+			s.byHash[h] = t
+		}
+	}
+}
+
+func (s *refreshTokenStub) allRevoked(userID uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.revokedAt[userID]
+	return ok
+}
+
+// --- MFA ---
+
+// testTOTPCode: satu-satunya kode yang diterima totpStub.
+const testTOTPCode = "123456"
+
+// totpStub: kode tetap, step = detik/30 supaya anti-replay tetap teruji.
+type totpStub struct{}
+
+func (totpStub) GenerateSecret() ([]byte, error)   { return []byte("0123456789abcdefghij"), nil }
+func (totpStub) EncodeSecret(secret []byte) string { return string(secret) }
+func (totpStub) URI(_ []byte, account string) string {
+	return "otpauth://totp/xeed:" + account
+}
+func (totpStub) Verify(_ []byte, code string, now time.Time) (int64, bool) {
+	return now.Unix() / 30, code == testTOTPCode
+}
+
+type totpRepoStub struct {
+	mu    sync.Mutex
+	creds map[uuid.UUID]domain.TOTPCredential
+}
+
+func (s *totpRepoStub) Get(_ context.Context, userID uuid.UUID) (*domain.TOTPCredential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.creds[userID]; ok {
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (s *totpRepoStub) Upsert(_ context.Context, c domain.TOTPCredential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.creds[c.UserID] = c
+	return nil
+}
+
+func (s *totpRepoStub) Confirm(_ context.Context, userID uuid.UUID, step int64, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.creds[userID]
+	c.ConfirmedAt, c.LastUsedStep = &at, step
+	s.creds[userID] = c
+	return nil
+}
+
+func (s *totpRepoStub) UseStep(_ context.Context, userID uuid.UUID, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.creds[userID]
+	if !ok || step <= c.LastUsedStep {
+		return false, nil
+	}
+	c.LastUsedStep = step
+	s.creds[userID] = c
+	return true, nil
+}
+
+type challengeStub struct {
+	mu   sync.Mutex
+	byID map[uuid.UUID]domain.MFAChallenge
+}
+
+func (s *challengeStub) Create(_ context.Context, c domain.MFAChallenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byID[c.ChallengeID] = c
+	return nil
+}
+
+func (s *challengeStub) GetActive(_ context.Context, hash string, now time.Time) (*domain.MFAChallenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.byID {
+		if c.TokenHash == hash && c.ConsumedAt == nil && now.Before(c.ExpiresAt) {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *challengeStub) IncrementAttempts(_ context.Context, id uuid.UUID, max int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.byID[id]
+	if !ok || c.Attempts >= max {
+		return false, nil
+	}
+	c.Attempts++
+	s.byID[id] = c
+	return true, nil
+}
+
+func (s *challengeStub) Consume(_ context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.byID[id]
+	if !ok || c.ConsumedAt != nil {
+		return false, nil
+	}
+	c.ConsumedAt = &at
+	s.byID[id] = c
+	return true, nil
+}
+
+type recoveryStub struct {
+	mu    sync.Mutex
+	codes []domain.RecoveryCode
+}
+
+func (s *recoveryStub) ReplaceAll(_ context.Context, userID uuid.UUID, codes []domain.RecoveryCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes = slices.DeleteFunc(s.codes, func(c domain.RecoveryCode) bool { return c.UserID == userID })
+	s.codes = append(s.codes, codes...)
+	return nil
+}
+
+func (s *recoveryStub) GetUnused(_ context.Context, userID uuid.UUID, selector string) (*domain.RecoveryCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.codes {
+		if c.UserID == userID && c.Selector == selector && c.UsedAt == nil {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *recoveryStub) MarkUsed(_ context.Context, codeID uuid.UUID, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.codes {
+		if c.CodeID == codeID && c.UsedAt == nil {
+			s.codes[i].UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *recoveryStub) CountUnused(_ context.Context, userID uuid.UUID) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, c := range s.codes {
+		if c.UserID == userID && c.UsedAt == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// --- OTP email/SMS ---
+
+type otpCodeStub struct {
+	mu    sync.Mutex
+	codes map[uuid.UUID]domain.OTPCode
+}
+
+func (s *otpCodeStub) Create(_ context.Context, c domain.OTPCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, cur := range s.codes {
+		if cur.UserID == c.UserID && cur.Purpose == c.Purpose && cur.Method == c.Method && cur.ConsumedAt == nil {
+			cur.ConsumedAt = &c.CreatedAt
+			s.codes[id] = cur
+		}
+	}
+	s.codes[c.CodeID] = c
+	return nil
+}
+
+func (s *otpCodeStub) GetActive(_ context.Context, userID uuid.UUID, purpose domain.OTPPurpose, method domain.MFAMethod, now time.Time) (*domain.OTPCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out *domain.OTPCode
+	for _, c := range s.codes {
+		if c.UserID != userID || c.Purpose != purpose || c.Method != method || c.ConsumedAt != nil || !now.Before(c.ExpiresAt) {
+			continue
+		}
+		if out == nil || c.CreatedAt.After(out.CreatedAt) {
+			out = &c
+		}
+	}
+	return out, nil
+}
+
+func (s *otpCodeStub) IncrementAttempts(_ context.Context, id uuid.UUID, max int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.codes[id]
+	if !ok || c.Attempts >= max {
+		return false, nil
+	}
+	c.Attempts++
+	s.codes[id] = c
+	return true, nil
+}
+
+func (s *otpCodeStub) Consume(_ context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.codes[id]
+	if !ok || c.ConsumedAt != nil {
+		return false, nil
+	}
+	c.ConsumedAt = &at
+	s.codes[id] = c
+	return true, nil
+}
+
+type otpMethodStub struct {
+	mu      sync.Mutex
+	methods map[uuid.UUID][]domain.MFAMethod
+}
+
+func (s *otpMethodStub) List(_ context.Context, userID uuid.UUID) ([]domain.MFAMethod, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.methods[userID]), nil
+}
+
+func (s *otpMethodStub) Enable(_ context.Context, userID uuid.UUID, method domain.MFAMethod, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.methods[userID], method) {
+		s.methods[userID] = append(s.methods[userID], method)
+	}
+	return nil
+}
+
+// historyStub: riwayat password per user, terbaru di depan.
+type historyStub struct {
+	entries map[uuid.UUID][]domain.PasswordHistoryEntry
+}
+
+func (s *historyStub) Add(_ context.Context, e domain.PasswordHistoryEntry, keep int) error {
+	list := append([]domain.PasswordHistoryEntry{e}, s.entries[e.UserID]...)
+	s.entries[e.UserID] = list[:min(keep, len(list))]
+	return nil
+}
+
+func (s *historyStub) Recent(_ context.Context, userID uuid.UUID, n int) ([]domain.PasswordHistoryEntry, error) {
+	list := s.entries[userID]
+	return slices.Clone(list[:min(n, len(list))]), nil
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+var ErrTooManyAttempts = apperr.New(apperr.KindTooManyRequests, "too_many_attempts", "too many failed login attempts")
+
+// ThrottledError: login ditolak sementara; client boleh coba lagi setelah RetryAfter.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string        { return ErrTooManyAttempts.Error() }
+func (e *ThrottledError) Is(target error) bool { return target == ErrTooManyAttempts }
+
+func (e *ThrottledError) AppError() *apperr.Error {
+	return ErrTooManyAttempts.WithRetryAfter(e.RetryAfter)
+}
+
+type LoginGuardConfig struct {
+	Window        time.Duration // counter di-reset kalau tidak ada gagal selama window
+	DelayAfter    int           // delay progresif mulai setelah N gagal berturut-turut
+	BaseDelay     time.Duration // delay pertama, lalu x2 setiap gagal
+	MaxDelay      time.Duration
+	LockThreshold int           // akun dikunci (UserLocked) setelah N gagal
+	LockDuration  time.Duration // auto-unlock setelah durasi ini
+	IPMaxFailures int           // gagal per IP dalam window sebelum IP di-throttle
+}
+
+type loginGuard struct {
+	store contract.LoginAttemptStore
+	users contract.UserRepository
+	clock contract.Clock
+	cfg   LoginGuardConfig
+}
+
+var _ contract.LoginGuard = (*loginGuard)(nil)
+
+func NewLoginGuard(store contract.LoginAttemptStore, users contract.UserRepository, clk contract.Clock, cfg LoginGuardConfig) contract.LoginGuard {
+	if store == nil {
+		panic("NewLoginGuard: store is nil")
+	}
+	if users == nil {
+		panic("NewLoginGuard: users is nil")
+	}
+	if clk == nil {
+		panic("NewLoginGuard: clock is nil")
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 15 * time.Minute
+	}
+	if cfg.DelayAfter <= 0 {
+		cfg.DelayAfter = 3
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = time.Second
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = time.Minute
+	}
+	if cfg.LockThreshold <= 0 {
+		cfg.LockThreshold = 10
+	}
+	if cfg.LockDuration <= 0 {
+		cfg.LockDuration = 15 * time.Minute
+	}
+	if cfg.IPMaxFailures <= 0 {
+		cfg.IPMaxFailures = 100
+	}
+	return &loginGuard{store: store, users: users, clock: clk, cfg: cfg}
+}
+
+func accountKey(email string) string { return "acct:" + email }
+func ipKey(ip string) string         { return "ip:" + ip }
+
+func (g *loginGuard) Check(ctx context.Context, email, ip string) error {
+	now := g.clock.Now()
+	if ip != "" {
+		a, err := g.store.Get(ctx, ipKey(ip))
+		if err != nil {
+			return err
+		}
+		if a.Active(now, g.cfg.Window) && a.Failures >= g.cfg.IPMaxFailures {
+			return &ThrottledError{RetryAfter: a.LastFailureAt.Add(g.cfg.Window).Sub(now)}
+		}
+	}
+
+	a, err := g.store.Get(ctx, accountKey(email))
+	if err != nil {
+		return err
+	}
+	// selama kunci otomatis password tidak dicek sama sekali; kalau dicek,
+	// beda jawaban (423 vs 401) membocorkan password yang benar
+	if a.LockedUntil != nil && now.Before(*a.LockedUntil) {
+		return &ThrottledError{RetryAfter: a.LockedUntil.Sub(now)}
+	}
+	if a.Active(now, g.cfg.Window) && a.Failures >= g.cfg.DelayAfter {
+		if next := a.LastFailureAt.Add(g.delay(a.Failures)); now.Before(next) {
+			return &ThrottledError{RetryAfter: next.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// delay: BaseDelay * 2^(n-DelayAfter), dibatasi MaxDelay
+func (g *loginGuard) delay(failures int) time.Duration {
+	d := g.cfg.BaseDelay
+	for i := g.cfg.DelayAfter; i < failures && d < g.cfg.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, g.cfg.MaxDelay)
+}
+
+func (g *loginGuard) Failure(ctx context.Context, email, ip string, u *domain.User) error {
+	now := g.clock.Now()
+	if ip != "" {
+		if _, err := g.store.RecordFailure(ctx, ipKey(ip), now, g.cfg.Window); err != nil {
+			return err
+		}
+	}
+	// email yang tidak terdaftar tetap dihitung supaya perilakunya sama
+	a, err := g.store.RecordFailure(ctx, accountKey(email), now, g.cfg.Window)
+	if err != nil {
+		return err
+	}
+	if u == nil || a.Failures < g.cfg.LockThreshold {
+		return nil
+	}
+
+	// compare-and-set, bukan Update seluruh baris: u bisa basi kalau ada
+	// gagal login paralel, dan hanya satu request yang benar-benar mengunci.
+	locked, err := g.users.SetStatus(ctx, u.UserID, domain.UserActive, domain.UserLocked, now)
+	if err != nil || !locked {
+		return err // sudah dikunci (request lain / admin) atau bukan ACTIVE
+	}
+	u.Lock()
+	u.UpdatedAt = now
+	return g.store.SetLockedUntil(ctx, accountKey(email), now.Add(g.cfg.LockDuration))
+}
+
+func (g *loginGuard) Success(ctx context.Context, email string) error {
+	return g.store.Reset(ctx, accountKey(email))
+}
+
+// AutoUnlock hanya berlaku untuk kunci otomatis (ada LockedUntil); kunci manual oleh admin tetap.
+func (g *loginGuard) AutoUnlock(ctx context.Context, u *domain.User) error {
+	if u.Status != domain.UserLocked {
+		return nil
+	}
+	a, err := g.store.Get(ctx, accountKey(u.Email))
+	if err != nil {
+		return err
+	}
+	now := g.clock.Now()
+	if a.LockedUntil == nil || now.Before(*a.LockedUntil) {
+		return nil
+	}
+	// user diubah (ex: dikunci ulang admin) setelah kunci otomatis: jangan dibuka
+	if u.UpdatedAt.After(a.LockedUntil.Add(-g.cfg.LockDuration)) {
+		return nil
+	}
+
+	unlocked, err := g.users.SetStatus(ctx, u.UserID, domain.UserLocked, domain.UserActive, now)
+	if err != nil || !unlocked {
+		return err
+	}
+	u.Activate()
+	u.UpdatedAt = now
+	return g.store.Reset(ctx, accountKey(u.Email))
+}
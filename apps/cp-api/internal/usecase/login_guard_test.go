@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/repo/memory"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+)
+
+// Gagal login paralel dengan snapshot user yang sama: akun terkunci sekali,
+// perubahan lain pada user tidak tertimpa, lalu terbuka lagi setelah LockDuration.
+func TestLoginGuardConcurrentFailures(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	store := memory.NewLoginAttemptStore()
+	guard := NewLoginGuard(store, e.users, e.clock, LoginGuardConfig{LockThreshold: 3, LockDuration: time.Minute})
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+
+	// perubahan profil setelah snapshot diambil
+	changed := e.mustGet(t, u.UserID)
+	name := "Budi"
+	changed.DisplayName = &name
+	if _, err := e.users.Update(ctx, changed); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stale := u
+			if err := guard.Failure(ctx, u.Email, "203.0.113.7", &stale); err != nil {
+				t.Errorf("Failure: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := e.mustGet(t, u.UserID)
+	if got.Status != domain.UserLocked {
+		t.Fatalf("Status = %s; want LOCKED", got.Status)
+	}
+	if got.DisplayName == nil || *got.DisplayName != name {
+		t.Errorf("DisplayName = %v; concurrent profile change was overwritten", got.DisplayName)
+	}
+	a, _ := store.Get(ctx, accountKey(u.Email))
+	if a.Failures != 10 || a.LockedUntil == nil {
+		t.Errorf("attempts = %+v; want 10 failures and LockedUntil", a)
+	}
+
+	e.clock.Advance(2 * time.Minute)
+	if err := guard.AutoUnlock(ctx, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.mustGet(t, u.UserID); got.Status != domain.UserActive {
+		t.Errorf("Status after AutoUnlock = %s; want ACTIVE", got.Status)
+	}
+}
+
+// Selama kunci otomatis Login ditolak sebelum password dicek: password benar
+// dan salah mendapat jawaban yang sama.
+func TestLoginRejectedWhileAutoLocked(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	store := memory.NewLoginAttemptStore()
+	guard := NewLoginGuard(store, e.users, e.clock, LoginGuardConfig{DelayAfter: 100, LockThreshold: 3, LockDuration: 15 * time.Minute})
+	svc := NewUserService(e.users, e.clock, e.ids, e.hasher, e.policy, e.sessions, nil, guard, nil)
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+
+	for range 3 {
+		if _, err := svc.Login(ctx, dto.LoginRequest{Email: u.Email, Password: "salah"}); !errors.Is(err, ErrInvalidCredential) {
+			t.Fatalf("Login(wrong) err = %v; want ErrInvalidCredential", err)
+		}
+	}
+	if got := e.mustGet(t, u.UserID); got.Status != domain.UserLocked {
+		t.Fatalf("Status = %s; want LOCKED", got.Status)
+	}
+
+	e.clock.Advance(time.Minute)
+	for _, pw := range []string{testPassword, "salah"} {
+		_, err := svc.Login(ctx, dto.LoginRequest{Email: u.Email, Password: pw})
+		if ae := apperr.From(err); !errors.Is(err, ErrTooManyAttempts) || ae.RetryAfter != 14*time.Minute {
+			t.Errorf("Login(%q) while locked err = %v (retry after %s); want ErrTooManyAttempts after 14m", pw, err, ae.RetryAfter)
+		}
+	}
+	if a, _ := store.Get(ctx, accountKey(u.Email)); a.Failures != 3 {
+		t.Errorf("failures = %d; want 3 (password not checked while locked)", a.Failures)
+	}
+
+	e.clock.Advance(15 * time.Minute)
+	if resp, err := svc.Login(ctx, dto.LoginRequest{Email: u.Email, Password: testPassword}); err != nil || resp.AccessToken == "" {
+		t.Fatalf("Login after lock expired = %+v, %v", resp, err)
+	}
+}
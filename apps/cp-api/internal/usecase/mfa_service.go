@@ -0,0 +1,551 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMFAAlreadyEnrolled = apperr.Conflict("mfa_already_enrolled", "mfa already enrolled")
+	ErrMFANotEnrolling    = apperr.Conflict("mfa_not_enrolling", "no pending totp enrollment")
+	ErrInvalidMFACode     = apperr.Unauthorized("invalid_mfa_code", "invalid mfa code")
+	ErrInvalidMFAToken    = apperr.Unauthorized("invalid_mfa_token", "invalid or expired mfa token")
+	ErrMFANotEnrolled     = apperr.Conflict("mfa_not_enrolled", "mfa is not enabled")
+)
+
+const (
+	mfaChallengeTTL = 5 * time.Minute
+	mfaMaxAttempts  = 5
+
+	recoveryCodeCount = 10
+	// metode second factor di LoginResponse.MFAMethods
+	mfaMethodRecoveryCode = "recovery_code"
+)
+
+type mfaService struct {
+	users      contract.UserRepository
+	totpRepo   contract.TOTPRepository
+	challenges contract.MFAChallengeRepository
+	sessions   contract.SessionService
+	totp       contract.TOTP
+	box        contract.SecretBox
+	clock      contract.Clock
+	idgen      contract.IDGen
+	opaque     contract.OpaqueTokenGen
+	recovery   contract.RecoveryCodeRepository
+	codegen    contract.RecoveryCodeGen
+	hasher     contract.PasswordHasher  // secret recovery code di-hash seperti password
+	webauthn   contract.WebAuthnService // nil = security key tidak bisa jadi faktor kedua
+	otp        contract.OTPService      // nil = tanpa kode via email/SMS
+	guard      contract.LoginGuard      // nil = kode salah tidak dihitung ke lockout akun
+}
+
+var _ contract.MFAService = (*mfaService)(nil)
+
+func NewMFAService(
+	users contract.UserRepository,
+	totpRepo contract.TOTPRepository,
+	challenges contract.MFAChallengeRepository,
+	sessions contract.SessionService,
+	totp contract.TOTP,
+	box contract.SecretBox,
+	clk contract.Clock,
+	idg contract.IDGen,
+	opaque contract.OpaqueTokenGen,
+	recovery contract.RecoveryCodeRepository,
+	codegen contract.RecoveryCodeGen,
+	hasher contract.PasswordHasher,
+	webauthn contract.WebAuthnService, // opsional
+	otp contract.OTPService, // opsional
+	guard contract.LoginGuard, // opsional
+) contract.MFAService {
+	if users == nil {
+		panic("NewMFAService: users is nil")
+	}
+	if totpRepo == nil {
+		panic("NewMFAService: totp repo is nil")
+	}
+	if challenges == nil {
+		panic("NewMFAService: challenges is nil")
+	}
+	if sessions == nil {
+		panic("NewMFAService: sessions is nil")
+	}
+	if totp == nil {
+		panic("NewMFAService: totp is nil")
+	}
+	if box == nil {
+		panic("NewMFAService: secret box is nil")
+	}
+	if clk == nil {
+		panic("NewMFAService: clock is nil")
+	}
+	if idg == nil {
+		panic("NewMFAService: idgen is nil")
+	}
+	if opaque == nil {
+		panic("NewMFAService: opaque is nil")
+	}
+	if recovery == nil {
+		panic("NewMFAService: recovery repo is nil")
+	}
+	if codegen == nil {
+		panic("NewMFAService: codegen is nil")
+	}
+	if hasher == nil {
+		panic("NewMFAService: hasher is nil")
+	}
+	return &mfaService{
+		users: users, totpRepo: totpRepo, challenges: challenges, sessions: sessions,
+		totp: totp, box: box, clock: clk, idgen: idg, opaque: opaque,
+		recovery: recovery, codegen: codegen, hasher: hasher, webauthn: webauthn,
+		otp: otp, guard: guard,
+	}
+}
+
+// EnrollTOTP membuat secret baru (belum aktif sampai ConfirmTOTP).
+func (s *mfaService) EnrollTOTP(ctx context.Context, p domain.Principal) (*dto.TOTPEnrollResponse, error) {
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	cur, err := s.totpRepo.Get(ctx, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if cur != nil && cur.IsConfirmed() {
+		return nil, ErrMFAAlreadyEnrolled
+	}
+
+	secret, err := s.totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.box.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.totpRepo.Upsert(ctx, domain.TOTPCredential{
+		UserID:    u.UserID,
+		SecretEnc: sealed,
+		CreatedAt: s.clock.Now(),
+	}); err != nil {
+		return nil, err
+	}
+	return &dto.TOTPEnrollResponse{
+		Secret:     s.totp.EncodeSecret(secret),
+		OTPAuthURI: s.totp.URI(secret, u.Email),
+	}, nil
+}
+
+// ConfirmTOTP mengaktifkan MFA setelah user membuktikan authenticator-nya menghasilkan kode yang benar.
+// Recovery code dibuat sekaligus dan hanya dikembalikan di sini.
+func (s *mfaService) ConfirmTOTP(ctx context.Context, p domain.Principal, in dto.TOTPConfirmRequest) (*dto.RecoveryCodesResponse, error) {
+	cur, err := s.totpRepo.Get(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if cur == nil {
+		return nil, ErrMFANotEnrolling
+	}
+	if cur.IsConfirmed() {
+		return nil, ErrMFAAlreadyEnrolled
+	}
+	step, ok, err := s.checkTOTP(*cur, in.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	now := s.clock.Now()
+	if err := s.totpRepo.Confirm(ctx, u.UserID, step, now); err != nil {
+		return nil, err
+	}
+	u.EnableMFA(domain.MFATOTP)
+	u.UpdatedAt = now
+	u.UpdatedBy = &u.UserID
+	if _, err := s.users.Update(ctx, *u); err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(ctx, u.UserID, now)
+}
+
+// RegenerateRecoveryCodes membatalkan semua recovery code lama dan membuat set baru.
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, p domain.Principal) (*dto.RecoveryCodesResponse, error) {
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	if !u.MFAEnrolled {
+		return nil, ErrMFANotEnrolled
+	}
+	return s.issueRecoveryCodes(ctx, u.UserID, s.clock.Now())
+}
+
+func (s *mfaService) RecoveryCodesRemaining(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.recovery.CountUnused(ctx, userID)
+}
+
+func (s *mfaService) issueRecoveryCodes(ctx context.Context, userID uuid.UUID, now time.Time) (*dto.RecoveryCodesResponse, error) {
+	plain, err := s.codegen.Generate(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]domain.RecoveryCode, len(plain))
+	for i, c := range plain {
+		sel, secret, _ := s.codegen.Split(c)
+		hash, _, _, err := s.hasher.Hash(secret)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = domain.RecoveryCode{
+			CodeID:    s.idgen.New(),
+			UserID:    userID,
+			Selector:  sel,
+			CodeHash:  hash,
+			CreatedAt: now,
+		}
+	}
+	if err := s.recovery.ReplaceAll(ctx, userID, codes); err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodesResponse{RecoveryCodes: plain}, nil
+}
+
+func (s *mfaService) Challenge(ctx context.Context, u domain.User) (*dto.LoginResponse, error) {
+	now := s.clock.Now()
+	plain, hash, err := s.opaque.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.challenges.Create(ctx, domain.MFAChallenge{
+		ChallengeID: s.idgen.New(),
+		UserID:      u.UserID,
+		TokenHash:   hash,
+		ExpiresAt:   now.Add(mfaChallengeTTL),
+		CreatedAt:   now,
+	}); err != nil {
+		return nil, err
+	}
+	methods, err := s.methods(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{
+		MFARequired: true,
+		MFAToken:    plain,
+		MFAMethods:  methods,
+	}, nil
+}
+
+// methods: faktor kedua yang bisa dipakai user ini
+func (s *mfaService) methods(ctx context.Context, u domain.User) ([]string, error) {
+	var out []string
+	cred, err := s.totpRepo.Get(ctx, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if cred != nil && cred.IsConfirmed() {
+		out = append(out, string(domain.MFATOTP))
+	}
+	if s.webauthn != nil {
+		ok, err := s.webauthn.HasCredentials(ctx, u.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, string(domain.MFAWebAuthn))
+		}
+	}
+	if s.otp != nil {
+		list, err := s.otp.EnabledMethods(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range list {
+			out = append(out, string(m))
+		}
+	}
+	n, err := s.recovery.CountUnused(ctx, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		out = append(out, mfaMethodRecoveryCode)
+	}
+	return out, nil
+}
+
+// BeginWebAuthn: options navigator.credentials.get() untuk langkah MFA.
+func (s *mfaService) BeginWebAuthn(ctx context.Context, in dto.MFAWebAuthnBeginRequest) (*dto.WebAuthnRequestOptions, error) {
+	if s.webauthn == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	ch, err := s.activeChallenge(ctx, in.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := s.webauthn.BeginMFA(ctx, ch.UserID)
+	if errors.Is(err, ErrWebAuthnCredentialNotFound) {
+		return nil, ErrMFANotEnrolled
+	}
+	return opts, err
+}
+
+// EnrollOTP mengirim kode ke email/HP user; metode aktif setelah ConfirmOTP.
+func (s *mfaService) EnrollOTP(ctx context.Context, p domain.Principal, in dto.MFAOTPEnrollRequest) (*dto.OTPSentResponse, error) {
+	if s.otp == nil {
+		return nil, ErrOTPMethodUnsupported
+	}
+	method, err := parseOTPMethod(in.Method)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	return s.otp.Send(ctx, *u, method, domain.OTPEnroll, nil)
+}
+
+// ConfirmOTP mengaktifkan metode email/SMS. Kalau ini faktor kedua pertama
+// user, recovery code dibuat dan dikembalikan (selain itu nil).
+func (s *mfaService) ConfirmOTP(ctx context.Context, p domain.Principal, in dto.MFAOTPConfirmRequest) (*dto.RecoveryCodesResponse, error) {
+	if s.otp == nil {
+		return nil, ErrOTPMethodUnsupported
+	}
+	method, err := parseOTPMethod(in.Method)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.otp.Check(ctx, p.UserID, method, domain.OTPEnroll, nil, in.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	if err := s.otp.Enable(ctx, u.UserID, method); err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	firstFactor := !u.MFAEnrolled
+	if method == domain.MFASMS {
+		u.VerifyPhone(now) // kode terkirim ke nomor ini, jadi nomornya terbukti
+	}
+	if firstFactor {
+		u.EnableMFA(method)
+	}
+	u.UpdatedAt = now
+	u.UpdatedBy = &u.UserID
+	if _, err := s.users.Update(ctx, *u); err != nil {
+		return nil, err
+	}
+	if !firstFactor {
+		return nil, nil
+	}
+	return s.issueRecoveryCodes(ctx, u.UserID, now)
+}
+
+// SendOTP: langkah MFA login dengan kode email/SMS (public, pakai mfaToken).
+func (s *mfaService) SendOTP(ctx context.Context, in dto.MFAOTPSendRequest) (*dto.OTPSentResponse, error) {
+	if s.otp == nil {
+		return nil, ErrOTPMethodUnsupported
+	}
+	method, err := parseOTPMethod(in.Method)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := s.activeChallenge(ctx, in.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(ctx, ch.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrInvalidMFAToken
+	}
+	enabled, err := s.otp.EnabledMethods(ctx, *u)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(enabled, method) {
+		return nil, ErrMFANotEnrolled
+	}
+	return s.otp.Send(ctx, *u, method, domain.OTPLogin, &ch.ChallengeID)
+}
+
+func (s *mfaService) activeChallenge(ctx context.Context, token string) (*domain.MFAChallenge, error) {
+	plain := strings.TrimSpace(token)
+	if plain == "" {
+		return nil, ErrInvalidMFAToken
+	}
+	ch, err := s.challenges.GetActive(ctx, s.opaque.Hash(plain), s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil || ch.Attempts >= mfaMaxAttempts {
+		return nil, ErrInvalidMFAToken
+	}
+	return ch, nil
+}
+
+// Verify menyelesaikan login dua langkah. Setiap percobaan memakai satu jatah
+// attempts sebelum kode diperiksa (atomik, aman untuk request paralel);
+// setelah mfaMaxAttempts challenge hangus dan user harus login ulang.
+// Kode salah juga dihitung LoginGuard (per akun, lintas challenge), sehingga
+// login ulang untuk challenge baru tetap berujung delay / akun terkunci.
+func (s *mfaService) Verify(ctx context.Context, in dto.MFAVerifyRequest) (*dto.LoginResponse, error) {
+	ch, err := s.activeChallenge(ctx, in.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+
+	u, err := s.users.GetByID(ctx, ch.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrInvalidMFAToken
+	}
+	if err := checkAccountStatus(*u); err != nil {
+		return nil, err
+	}
+	if s.guard != nil {
+		if err := s.guard.Check(ctx, u.Email, in.ClientIP); err != nil {
+			return nil, err
+		}
+	}
+	reserved, err := s.challenges.IncrementAttempts(ctx, ch.ChallengeID, mfaMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return nil, ErrInvalidMFAToken
+	}
+
+	var ok bool
+	method := domain.MFAMethod(strings.ToLower(strings.TrimSpace(in.Method)))
+	switch {
+	case in.WebAuthn != nil && s.webauthn != nil:
+		ok, err = s.webauthn.VerifyMFA(ctx, u.UserID, *in.WebAuthn)
+	case strings.TrimSpace(in.RecoveryCode) != "":
+		ok, err = s.useRecoveryCode(ctx, u.UserID, in.RecoveryCode, now)
+	case (method == domain.MFAEmail || method == domain.MFASMS) && s.otp != nil:
+		ok, err = s.otp.Check(ctx, u.UserID, method, domain.OTPLogin, &ch.ChallengeID, in.Code)
+	default:
+		ok, err = s.verifyTOTP(ctx, u.UserID, in.Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if s.guard != nil {
+			if err := s.guard.Failure(ctx, u.Email, in.ClientIP, u); err != nil {
+				return nil, err
+			}
+		}
+		return nil, ErrInvalidMFACode
+	}
+
+	consumed, err := s.challenges.Consume(ctx, ch.ChallengeID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrInvalidMFAToken
+	}
+	if s.guard != nil {
+		if err := s.guard.Success(ctx, u.Email); err != nil {
+			return nil, err
+		}
+	}
+	if u.MustChangePassword {
+		return s.sessions.IssueRestricted(ctx, *u)
+	}
+	return s.sessions.Issue(ctx, *u)
+}
+
+func (s *mfaService) verifyTOTP(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	cred, err := s.totpRepo.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if cred == nil || !cred.IsConfirmed() {
+		return false, nil
+	}
+	step, ok, err := s.checkTOTP(*cred, code)
+	if err != nil || !ok {
+		return false, err
+	}
+	// kode yang sama tidak boleh dipakai dua kali
+	return s.totpRepo.UseStep(ctx, userID, step)
+}
+
+// useRecoveryCode: baris dicari lewat selector sehingga hanya ada satu Verify
+// per percobaan, lalu kode ditandai terpakai (sekali pakai).
+func (s *mfaService) useRecoveryCode(ctx context.Context, userID uuid.UUID, code string, now time.Time) (bool, error) {
+	sel, secret, ok := s.codegen.Split(code)
+	if !ok {
+		return false, nil
+	}
+	c, err := s.recovery.GetUnused(ctx, userID, sel)
+	if err != nil || c == nil {
+		return false, err
+	}
+	if !s.hasher.Verify("", secret, c.CodeHash) {
+		return false, nil
+	}
+	return s.recovery.MarkUsed(ctx, c.CodeID, now)
+}
+
+func (s *mfaService) checkTOTP(c domain.TOTPCredential, code string) (int64, bool, error) {
+	secret, err := s.box.Open(c.SecretEnc)
+	if err != nil {
+		return 0, false, err
+	}
+	step, ok := s.totp.Verify(secret, code, s.clock.Now())
+	if !ok || step <= c.LastUsedStep {
+		return 0, false, nil
+	}
+	return step, true, nil
+}
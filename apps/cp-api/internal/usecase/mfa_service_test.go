@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/adapter/security"
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/repo/memory"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// mfaEnv: testEnv + MFAService, LoginGuard dan UserService yang saling terhubung.
+type mfaEnv struct {
+	*testEnv
+	totpRepo   *totpRepoStub
+	challenges *challengeStub
+	recovery   *recoveryStub
+	attempts   *memory.LoginAttemptStore
+	box        contract.SecretBox
+	guard      contract.LoginGuard
+	mfa        contract.MFAService
+	login      contract.UserService
+}
+
+func newMFAEnv(t *testing.T, otp contract.OTPService) *mfaEnv {
+	t.Helper()
+	e := &mfaEnv{
+		testEnv:    newTestEnv(t),
+		totpRepo:   &totpRepoStub{creds: map[uuid.UUID]domain.TOTPCredential{}},
+		challenges: &challengeStub{byID: map[uuid.UUID]domain.MFAChallenge{}},
+		recovery:   &recoveryStub{},
+		attempts:   memory.NewLoginAttemptStore(),
+	}
+	box, err := security.NewSecretBox(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.box = box
+	// tanpa delay progresif supaya test hanya melihat lockout
+	e.guard = NewLoginGuard(e.attempts, e.users, e.clock, LoginGuardConfig{DelayAfter: 100, LockThreshold: 4})
+	e.mfa = e.mfaService(otp, e.guard)
+	e.login = NewUserService(e.users, e.clock, e.ids, e.hasher, e.policy, e.sessions, nil, e.guard, e.mfa)
+	return e
+}
+
+func (e *mfaEnv) mfaService(otp contract.OTPService, guard contract.LoginGuard) contract.MFAService {
+	return NewMFAService(e.users, e.totpRepo, e.challenges, e.sessions, totpStub{}, e.box, e.clock, e.ids,
+		security.OpaqueTokens{}, e.recovery, security.RecoveryCodes{}, e.hasher, nil, otp, guard)
+}
+
+// seedTOTPUser: user ACTIVE dengan TOTP terkonfirmasi.
+func (e *mfaEnv) seedTOTPUser(t *testing.T, email string) domain.User {
+	t.Helper()
+	u := e.seedUser(t, email, testPassword, func(u *domain.User) { u.EnableMFA(domain.MFATOTP) })
+	secret, _ := totpStub{}.GenerateSecret()
+	sealed, err := e.box.Seal(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := e.clock.Now()
+	e.totpRepo.creds[u.UserID] = domain.TOTPCredential{UserID: u.UserID, SecretEnc: sealed, ConfirmedAt: &at, CreatedAt: at}
+	return u
+}
+
+func (e *mfaEnv) mfaToken(t *testing.T, email string) string {
+	t.Helper()
+	resp, err := e.login.Login(context.Background(), dto.LoginRequest{Email: email, Password: testPassword})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !resp.MFARequired || resp.MFAToken == "" {
+		t.Fatalf("Login response = %+v; want MFA challenge", resp)
+	}
+	return resp.MFAToken
+}
+
+// Password benar tidak me-reset counter gagal selama MFA belum lolos; kode MFA
+// salah dihitung lintas challenge sampai akun terkunci.
+func TestMFAFailuresCountTowardLockout(t *testing.T) {
+	e := newMFAEnv(t, nil)
+	ctx := context.Background()
+	u := e.seedTOTPUser(t, "budi@xeed.test")
+
+	for i := range 4 {
+		if i%2 == 0 {
+			e.clock.Advance(time.Minute) // step TOTP baru, challenge baru
+		}
+		tok := e.mfaToken(t, u.Email)
+		if _, err := e.mfa.Verify(ctx, dto.MFAVerifyRequest{MFAToken: tok, Code: "000000"}); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("Verify(wrong code #%d) err = %v; want ErrInvalidMFACode", i+1, err)
+		}
+	}
+
+	if got := e.mustGet(t, u.UserID); got.Status != domain.UserLocked {
+		t.Fatalf("Status = %s; want LOCKED after repeated MFA failures", got.Status)
+	}
+	// selama kunci otomatis password benar pun ditolak tanpa dicek
+	if _, err := e.login.Login(ctx, dto.LoginRequest{Email: u.Email, Password: testPassword}); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("Login after lockout err = %v; want ErrTooManyAttempts", err)
+	}
+}
+
+func TestMFASuccessResetsLoginGuard(t *testing.T) {
+	e := newMFAEnv(t, nil)
+	ctx := context.Background()
+	u := e.seedTOTPUser(t, "budi@xeed.test")
+
+	if _, err := e.login.Login(ctx, dto.LoginRequest{Email: u.Email, Password: "salah"}); !errors.Is(err, ErrInvalidCredential) {
+		t.Fatalf("Login(wrong password) err = %v", err)
+	}
+	tok := e.mfaToken(t, u.Email)
+	if a, _ := e.attempts.Get(ctx, accountKey(u.Email)); a.Failures != 1 {
+		t.Fatalf("failures after password step = %d; want 1 (not reset before MFA)", a.Failures)
+	}
+
+	e.clock.Advance(time.Minute)
+	resp, err := e.mfa.Verify(ctx, dto.MFAVerifyRequest{MFAToken: tok, Code: testTOTPCode})
+	if err != nil || resp.AccessToken == "" {
+		t.Fatalf("Verify = %+v, %v", resp, err)
+	}
+	if a, _ := e.attempts.Get(ctx, accountKey(u.Email)); a.Failures != 0 {
+		t.Errorf("failures after MFA = %d; want 0", a.Failures)
+	}
+}
+
+// Percobaan paralel pada satu challenge tidak boleh melebihi mfaMaxAttempts.
+func TestMFAVerifyAttemptsAreAtomic(t *testing.T) {
+	e := newMFAEnv(t, nil)
+	ctx := context.Background()
+	u := e.seedTOTPUser(t, "budi@xeed.test")
+	tok := e.mfaToken(t, u.Email)
+	svc := e.mfaService(nil, nil) // tanpa lockout akun, hanya jatah per challenge
+
+	const n = 20
+	results := make(chan error, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.Verify(ctx, dto.MFAVerifyRequest{MFAToken: tok, Code: "000000"})
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var checked int
+	for err := range results {
+		switch {
+		case errors.Is(err, ErrInvalidMFACode):
+			checked++
+		case errors.Is(err, ErrInvalidMFAToken):
+		default:
+			t.Errorf("Verify err = %v", err)
+		}
+	}
+	if checked != mfaMaxAttempts {
+		t.Errorf("codes checked = %d; want exactly %d", checked, mfaMaxAttempts)
+	}
+	if _, err := svc.Verify(ctx, dto.MFAVerifyRequest{MFAToken: tok, Code: testTOTPCode}); !errors.Is(err, ErrInvalidMFAToken) {
+		t.Errorf("Verify(correct code, exhausted) err = %v; want ErrInvalidMFAToken", err)
+	}
+}
+
+func TestMFARecoveryCode(t *testing.T) {
+	e := newMFAEnv(t, nil)
+	ctx := context.Background()
+	u := e.seedTOTPUser(t, "budi@xeed.test")
+	codes, err := e.mfa.RegenerateRecoveryCodes(ctx, domain.Principal{UserID: u.UserID})
+	if err != nil || len(codes.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("RegenerateRecoveryCodes = %+v, %v", codes, err)
+	}
+	for i, c := range e.recovery.codes {
+		if !strings.HasPrefix(codes.RecoveryCodes[i], c.Selector+"-") || strings.Contains(c.CodeHash, codes.RecoveryCodes[i][5:9]) {
+			t.Fatalf("stored %q/%q for code %q; want selector + password hash", c.Selector, c.CodeHash, codes.RecoveryCodes[i])
+		}
+	}
+
+	code := " " + strings.ToUpper(codes.RecoveryCodes[3]) + " " // tetap dinormalisasi
+	resp, err := e.mfa.Verify(ctx, dto.MFAVerifyRequest{MFAToken: e.mfaToken(t, u.Email), RecoveryCode: code})
+	if err != nil || resp.AccessToken == "" {
+		t.Fatalf("Verify(recovery code) = %+v, %v", resp, err)
+	}
+	if n, _ := e.mfa.RecoveryCodesRemaining(ctx, u.UserID); n != recoveryCodeCount-1 {
+		t.Errorf("remaining = %d; want %d", n, recoveryCodeCount-1)
+	}
+
+	other := codes.RecoveryCodes[5]
+	for _, tc := range []struct{ name, code string }{
+		{"reused", code},
+		{"wrong secret", other[:5] + wrongSecret(other[5:])},
+		{"unknown selector", "zzzz-zzzz-zzzz"},
+		{"malformed", "zzzz-zzzz"},
+	} {
+		_, err := e.mfa.Verify(ctx, dto.MFAVerifyRequest{MFAToken: e.mfaToken(t, u.Email), RecoveryCode: tc.code})
+		if !errors.Is(err, ErrInvalidMFACode) {
+			t.Errorf("%s: err = %v; want ErrInvalidMFACode", tc.name, err)
+		}
+	}
+}
+
+// wrongSecret mengganti satu karakter secret "xxxx-xxxx" dengan karakter lain.
+func wrongSecret(secret string) string {
+	if secret[0] == 'a' {
+		return "b" + secret[1:]
+	}
+	return "a" + secret[1:]
+}
@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOTPMethodUnsupported = apperr.Validation("unsupported_method", "unsupported otp method",
+		apperr.Field("method", "unsupported_method", "must be one of: email, sms"))
+	ErrOTPDestinationUnverified = apperr.Conflict("destination_unverified", "otp destination is missing or unverified")
+	ErrOTPResendTooSoon         = apperr.New(apperr.KindTooManyRequests, "resend_too_soon", "otp code was sent recently, try again later")
+	ErrOTPTooManyAttempts       = apperr.New(apperr.KindTooManyRequests, "otp_too_many_attempts", "too many wrong codes, try again later")
+)
+
+const (
+	otpTTL            = 10 * time.Minute
+	otpMaxAttempts    = 5 // per kode
+	otpResendInterval = 30 * time.Second
+	// per user lintas kode: kirim ulang membuat kode baru dengan jatah baru,
+	// jadi tanpa batas ini tebakan kode tidak terbatas
+	otpMaxFailures   = 10
+	otpFailureWindow = time.Hour
+)
+
+type otpService struct {
+	codes   contract.OTPCodeRepository
+	methods contract.OTPMethodRepository
+	fails   contract.LoginAttemptStore // counter kode salah per user (lintas kode)
+	mailer  contract.EmailSender
+	sms     contract.SMSSender
+	gen     contract.OneTimeCodeGen
+	clock   contract.Clock
+	idgen   contract.IDGen
+	opaque  contract.OpaqueTokenGen
+	appName string // ditulis di isi pesan, ex: "Xeed"
+}
+
+var _ contract.OTPService = (*otpService)(nil)
+
+func NewOTPService(
+	codes contract.OTPCodeRepository,
+	methods contract.OTPMethodRepository,
+	fails contract.LoginAttemptStore,
+	mailer contract.EmailSender,
+	sms contract.SMSSender,
+	gen contract.OneTimeCodeGen,
+	clk contract.Clock,
+	idg contract.IDGen,
+	opaque contract.OpaqueTokenGen,
+	appName string,
+) contract.OTPService {
+	if codes == nil {
+		panic("NewOTPService: codes is nil")
+	}
+	if methods == nil {
+		panic("NewOTPService: methods is nil")
+	}
+	if fails == nil {
+		panic("NewOTPService: fails is nil")
+	}
+	if mailer == nil {
+		panic("NewOTPService: mailer is nil")
+	}
+	if sms == nil {
+		panic("NewOTPService: sms is nil")
+	}
+	if gen == nil {
+		panic("NewOTPService: gen is nil")
+	}
+	if clk == nil {
+		panic("NewOTPService: clock is nil")
+	}
+	if idg == nil {
+		panic("NewOTPService: idgen is nil")
+	}
+	if opaque == nil {
+		panic("NewOTPService: opaque is nil")
+	}
+	return &otpService{
+		codes: codes, methods: methods, fails: fails, mailer: mailer, sms: sms, gen: gen,
+		clock: clk, idgen: idg, opaque: opaque, appName: def(appName, "Xeed"),
+	}
+}
+
+func parseOTPMethod(s string) (domain.MFAMethod, error) {
+	switch m := domain.MFAMethod(strings.ToLower(strings.TrimSpace(s))); m {
+	case domain.MFAEmail, domain.MFASMS:
+		return m, nil
+	}
+	return "", ErrOTPMethodUnsupported
+}
+
+// destination: alamat tujuan kode. Untuk enroll SMS nomor boleh belum
+// terverifikasi (kode ini yang memverifikasinya); selain itu wajib terverifikasi.
+func destination(u domain.User, method domain.MFAMethod, purpose domain.OTPPurpose) (string, error) {
+	switch method {
+	case domain.MFAEmail:
+		if u.EmailVerifiedAt == nil {
+			return "", ErrOTPDestinationUnverified
+		}
+		return u.Email, nil
+	case domain.MFASMS:
+		if u.PhoneE164 == nil || (purpose == domain.OTPLogin && u.PhoneVerifiedAt == nil) {
+			return "", ErrOTPDestinationUnverified
+		}
+		return *u.PhoneE164, nil
+	}
+	return "", ErrOTPMethodUnsupported
+}
+
+func (s *otpService) Send(ctx context.Context, u domain.User, method domain.MFAMethod, purpose domain.OTPPurpose, challengeID *uuid.UUID) (*dto.OTPSentResponse, error) {
+	to, err := destination(u, method, purpose)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if err := s.checkFailures(ctx, u.UserID, now); err != nil {
+		return nil, err
+	}
+	cur, err := s.codes.GetActive(ctx, u.UserID, purpose, method, now)
+	if err != nil {
+		return nil, err
+	}
+	if cur != nil && now.Sub(cur.CreatedAt) < otpResendInterval {
+		return nil, ErrOTPResendTooSoon.WithRetryAfter(otpResendInterval - now.Sub(cur.CreatedAt))
+	}
+
+	code, err := s.gen.Generate()
+	if err != nil {
+		return nil, err
+	}
+	c := domain.OTPCode{
+		CodeID:      s.idgen.New(),
+		UserID:      u.UserID,
+		ChallengeID: challengeID,
+		Method:      method,
+		Purpose:     purpose,
+		ExpiresAt:   now.Add(otpTTL),
+		CreatedAt:   now,
+	}
+	c.CodeHash = s.hash(c.CodeID, code)
+	if err := s.codes.Create(ctx, c); err != nil {
+		return nil, err
+	}
+
+	msg := fmt.Sprintf("Your %s verification code is %s. It expires in %d minutes. Never share this code.",
+		s.appName, code, int(otpTTL.Minutes()))
+	if method == domain.MFASMS {
+		err = s.sms.SendSMS(ctx, to, msg)
+	} else {
+		err = s.mailer.SendEmail(ctx, to, s.appName+" verification code", msg+"\n")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dto.OTPSentResponse{
+		Method:      string(method),
+		Destination: maskDestination(method, to),
+		ExpiresIn:   int(otpTTL.Seconds()),
+	}, nil
+}
+
+func (s *otpService) Check(ctx context.Context, userID uuid.UUID, method domain.MFAMethod, purpose domain.OTPPurpose, challengeID *uuid.UUID, code string) (bool, error) {
+	now := s.clock.Now()
+	c, err := s.codes.GetActive(ctx, userID, purpose, method, now)
+	if err != nil {
+		return false, err
+	}
+	if c == nil {
+		return false, nil
+	}
+	// kode login hanya berlaku untuk challenge yang memintanya
+	if challengeID != nil && (c.ChallengeID == nil || *c.ChallengeID != *challengeID) {
+		return false, nil
+	}
+	if err := s.checkFailures(ctx, userID, now); err != nil {
+		return false, err
+	}
+	// jatah dipakai sebelum kode dibandingkan (atomik, aman untuk request paralel)
+	reserved, err := s.codes.IncrementAttempts(ctx, c.CodeID, otpMaxAttempts)
+	if err != nil || !reserved {
+		return false, err
+	}
+	got := s.hash(c.CodeID, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(got), []byte(c.CodeHash)) != 1 {
+		_, err := s.fails.RecordFailure(ctx, otpFailureKey(userID), now, otpFailureWindow)
+		return false, err
+	}
+	ok, err := s.codes.Consume(ctx, c.CodeID, now)
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, s.fails.Reset(ctx, otpFailureKey(userID))
+}
+
+func otpFailureKey(userID uuid.UUID) string { return "otp:" + userID.String() }
+
+// checkFailures: terlalu banyak kode salah dalam otpFailureWindow menolak kirim
+// ulang maupun cek kode sampai window lewat.
+func (s *otpService) checkFailures(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	a, err := s.fails.Get(ctx, otpFailureKey(userID))
+	if err != nil {
+		return err
+	}
+	if a.Active(now, otpFailureWindow) && a.Failures >= otpMaxFailures {
+		return ErrOTPTooManyAttempts.WithRetryAfter(a.LastFailureAt.Add(otpFailureWindow).Sub(now))
+	}
+	return nil
+}
+
+func (s *otpService) EnabledMethods(ctx context.Context, u domain.User) ([]domain.MFAMethod, error) {
+	list, err := s.methods.List(ctx, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(list, func(m domain.MFAMethod) bool {
+		_, err := destination(u, m, domain.OTPLogin)
+		return err != nil
+	}), nil
+}
+
+func (s *otpService) Enable(ctx context.Context, userID uuid.UUID, method domain.MFAMethod) error {
+	return s.methods.Enable(ctx, userID, method, s.clock.Now())
+}
+
+// hash di-salt dengan CodeID supaya kode 6 digit yang sama tidak menghasilkan hash yang sama
+func (s *otpService) hash(codeID uuid.UUID, code string) string {
+	return s.opaque.Hash(codeID.String() + ":" + code)
+}
+
+// maskDestination: "+6281234567890" -> "+62*******890", "budi@xeed.id" -> "b***@xeed.id"
+func maskDestination(method domain.MFAMethod, to string) string {
+	if method == domain.MFAEmail {
+		local, domainPart, ok := strings.Cut(to, "@")
+		if !ok || local == "" {
+			return "***"
+		}
+		return local[:1] + "***@" + domainPart
+	}
+	if len(to) <= 6 {
+		return strings.Repeat("*", len(to))
+	}
+	return to[:3] + strings.Repeat("*", len(to)-6) + to[len(to)-3:]
+}
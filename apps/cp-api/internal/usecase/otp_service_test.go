@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/adapter/notify"
+	"xeed/apps/cp-api/internal/adapter/security"
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/repo/memory"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// otpEnv: OTPService dengan outbox file (tanpa provider email/SMS).
+type otpEnv struct {
+	codes   *otpCodeStub
+	methods *otpMethodStub
+	fails   *memory.LoginAttemptStore
+	outbox  string
+	svc     contract.OTPService
+}
+
+func (e *testEnv) otpEnv(t *testing.T) *otpEnv {
+	t.Helper()
+	o := &otpEnv{
+		codes:   &otpCodeStub{codes: map[uuid.UUID]domain.OTPCode{}},
+		methods: &otpMethodStub{methods: map[uuid.UUID][]domain.MFAMethod{}},
+		fails:   memory.NewLoginAttemptStore(),
+		outbox:  t.TempDir(),
+	}
+	o.svc = NewOTPService(o.codes, o.methods, o.fails, notify.FileEmailSender{Dir: o.outbox}, notify.FileSMSSender{Dir: o.outbox},
+		security.NumericCodes{}, e.clock, e.ids, security.OpaqueTokens{}, "Xeed")
+	return o
+}
+
+var rxOTPCode = regexp.MustCompile(`code is (\d{6})\.`)
+
+// lastCode membaca kode dari pesan terbaru di outbox lalu menghapus pesan itu:
+// nama file memakai jam asli (resolusi ms), jadi dua kirim dalam ms yang sama
+// tidak bisa diurutkan dari namanya.
+func (o *otpEnv) lastCode(t *testing.T) string {
+	t.Helper()
+	entries, err := os.ReadDir(o.outbox)
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("outbox empty: %v", err)
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	slices.Sort(names) // nama file diawali timestamp
+	path := filepath.Join(o.outbox, names[len(names)-1])
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	m := rxOTPCode.FindStringSubmatch(string(b))
+	if m == nil {
+		t.Fatalf("no code in message:\n%s", b)
+	}
+	return m[1]
+}
+
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+func withVerifiedPhone(u *domain.User) {
+	phone := "+6281234567890"
+	u.PhoneE164, u.PhoneVerifiedAt = &phone, &u.CreatedAt
+}
+
+// Percobaan paralel pada satu kode tidak boleh melebihi otpMaxAttempts.
+func TestOTPCheckAttemptsAreAtomic(t *testing.T) {
+	e := newTestEnv(t)
+	o := e.otpEnv(t)
+	ctx := context.Background()
+	u := e.seedUser(t, "budi@xeed.test", testPassword, withVerifiedPhone)
+	if _, err := o.svc.Send(ctx, u, domain.MFASMS, domain.OTPEnroll, nil); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	code := o.lastCode(t)
+	wrong := wrongCode(code)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, err := o.svc.Check(ctx, u.UserID, domain.MFASMS, domain.OTPEnroll, nil, wrong); err != nil || ok {
+				t.Errorf("Check(wrong) = %t, %v", ok, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	c, _ := o.codes.GetActive(ctx, u.UserID, domain.OTPEnroll, domain.MFASMS, e.clock.Now())
+	if c == nil || c.Attempts != otpMaxAttempts {
+		t.Fatalf("code = %+v; want Attempts = %d", c, otpMaxAttempts)
+	}
+	if ok, err := o.svc.Check(ctx, u.UserID, domain.MFASMS, domain.OTPEnroll, nil, code); err != nil || ok {
+		t.Errorf("Check(correct code, exhausted) = %t, %v; want false", ok, err)
+	}
+}
+
+// Kirim ulang membuat kode baru dengan jatah baru; jumlah kode salah per user
+// tetap dibatasi lintas kode.
+func TestOTPFailuresCappedAcrossResends(t *testing.T) {
+	e := newTestEnv(t)
+	o := e.otpEnv(t)
+	ctx := context.Background()
+	u := e.seedUser(t, "budi@xeed.test", testPassword, withVerifiedPhone)
+
+	for sent := 0; sent < otpMaxFailures/otpMaxAttempts; sent++ {
+		if _, err := o.svc.Send(ctx, u, domain.MFASMS, domain.OTPEnroll, nil); err != nil {
+			t.Fatalf("Send #%d: %v", sent+1, err)
+		}
+		wrong := wrongCode(o.lastCode(t))
+		for range otpMaxAttempts {
+			if ok, err := o.svc.Check(ctx, u.UserID, domain.MFASMS, domain.OTPEnroll, nil, wrong); err != nil || ok {
+				t.Fatalf("Check(wrong) = %t, %v", ok, err)
+			}
+		}
+		e.clock.Advance(otpResendInterval + time.Second)
+	}
+
+	_, err := o.svc.Send(ctx, u, domain.MFASMS, domain.OTPEnroll, nil)
+	var ae *apperr.Error
+	if !errors.As(err, &ae) || !errors.Is(err, ErrOTPTooManyAttempts) || ae.RetryAfter <= 0 {
+		t.Fatalf("Send after cap = %v; want ErrOTPTooManyAttempts with RetryAfter", err)
+	}
+	if _, err := o.svc.Check(ctx, u.UserID, domain.MFASMS, domain.OTPEnroll, nil, "123456"); !errors.Is(err, ErrOTPTooManyAttempts) {
+		t.Fatalf("Check after cap = %v; want ErrOTPTooManyAttempts", err)
+	}
+
+	e.clock.Advance(otpFailureWindow)
+	if _, err := o.svc.Send(ctx, u, domain.MFASMS, domain.OTPEnroll, nil); err != nil {
+		t.Fatalf("Send after window: %v", err)
+	}
+	if ok, err := o.svc.Check(ctx, u.UserID, domain.MFASMS, domain.OTPEnroll, nil, o.lastCode(t)); err != nil || !ok {
+		t.Fatalf("Check(correct) after window = %t, %v", ok, err)
+	}
+	if a, _ := o.fails.Get(ctx, otpFailureKey(u.UserID)); a.Failures != 0 {
+		t.Errorf("failures after success = %d; want 0", a.Failures)
+	}
+}
+
+// smsLogin: user dengan MFA SMS, sudah lolos password; mengembalikan token
+// challenge setelah kode OTP dikirim ke outbox.
+func smsLogin(t *testing.T) (*mfaEnv, *otpEnv, string) {
+	t.Helper()
+	e := newMFAEnv(t, nil)
+	o := e.otpEnv(t)
+	e.mfa = e.mfaService(o.svc, e.guard)
+	e.login = NewUserService(e.users, e.clock, e.ids, e.hasher, e.policy, e.sessions, nil, e.guard, e.mfa)
+
+	u := e.seedUser(t, "budi@xeed.test", testPassword, withVerifiedPhone, func(u *domain.User) { u.EnableMFA(domain.MFASMS) })
+	o.methods.methods[u.UserID] = []domain.MFAMethod{domain.MFASMS}
+	tok := e.mfaToken(t, u.Email)
+	if _, err := e.mfa.SendOTP(context.Background(), dto.MFAOTPSendRequest{MFAToken: tok, Method: "sms"}); err != nil {
+		t.Fatalf("SendOTP: %v", err)
+	}
+	return e, o, tok
+}
+
+func TestMFASMSLogin(t *testing.T) {
+	e, o, tok := smsLogin(t)
+	resp, err := e.mfa.Verify(context.Background(), dto.MFAVerifyRequest{MFAToken: tok, Method: "sms", Code: o.lastCode(t)})
+	if err != nil || resp.AccessToken == "" || resp.MFARequired {
+		t.Fatalf("Verify = %+v, %v; want session", resp, err)
+	}
+}
+
+func TestMFASMSLoginWrongCode(t *testing.T) {
+	e, o, tok := smsLogin(t)
+	ctx := context.Background()
+	code := o.lastCode(t)
+	if resp, err := e.mfa.Verify(ctx, dto.MFAVerifyRequest{MFAToken: tok, Method: "sms", Code: wrongCode(code)}); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("Verify(wrong code) = %+v, %v; want ErrInvalidMFACode", resp, err)
+	}
+	// challenge masih berlaku; kode yang benar tetap diterima
+	if resp, err := e.mfa.Verify(ctx, dto.MFAVerifyRequest{MFAToken: tok, Method: "sms", Code: code}); err != nil || resp.AccessToken == "" {
+		t.Fatalf("Verify(correct code) = %+v, %v", resp, err)
+	}
+}
+
+func TestMFASMSLoginExpiredCode(t *testing.T) {
+	e, o, tok := smsLogin(t)
+	ctx := context.Background()
+	code := o.lastCode(t)
+	// otpTTL lebih panjang dari mfaChallengeTTL, jadi kode dibuat kedaluwarsa
+	// langsung di repo supaya challenge-nya masih hidup
+	o.codes.mu.Lock()
+	for id, c := range o.codes.codes {
+		c.ExpiresAt = e.clock.Now()
+		o.codes.codes[id] = c
+	}
+	o.codes.mu.Unlock()
+
+	if resp, err := e.mfa.Verify(ctx, dto.MFAVerifyRequest{MFAToken: tok, Method: "sms", Code: code}); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("Verify(expired code) = %+v, %v; want ErrInvalidMFACode", resp, err)
+	}
+}
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+var ErrWeakPassword = apperr.Validation("weak_password", "password does not meet the password policy")
+
+// Kode pelanggaran yang stabil untuk client
+const (
+	ViolationTooShort     = "too_short"
+	ViolationTooLong      = "too_long"
+	ViolationTooManyBytes = "too_many_bytes"
+	ViolationCharClasses  = "insufficient_character_classes"
+	ViolationPersonalInfo = "contains_personal_info"
+	ViolationReused       = "recently_used"
+	ViolationBreached     = "breached"
+)
+
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError: semua pelanggaran sekaligus supaya client bisa menampilkan semuanya.
+type PasswordPolicyError struct {
+	Field      string // field input di request; kosong = "password"
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return ErrWeakPassword.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *PasswordPolicyError) Is(target error) bool { return target == ErrWeakPassword }
+
+func (e *PasswordPolicyError) AppError() *apperr.Error {
+	field := def(e.Field, "password")
+	fields := make([]apperr.FieldError, len(e.Violations))
+	for i, v := range e.Violations {
+		fields[i] = apperr.Field(field, v.Code, v.Message)
+	}
+	return apperr.Validation(ErrWeakPassword.Code, ErrWeakPassword.Message, fields...)
+}
+
+// onField: pelanggaran policy dilaporkan untuk field lain (ex: "newPassword").
+func onField(err error, field string) error {
+	if pe, ok := IsPasswordPolicyError(err); ok {
+		pe.Field = field
+	}
+	return err
+}
+
+func IsPasswordPolicyError(err error) (*PasswordPolicyError, bool) {
+	var pe *PasswordPolicyError
+	ok := errors.As(err, &pe)
+	return pe, ok
+}
+
+type PasswordPolicyConfig struct {
+	MinLength            int  // dalam karakter (rune)
+	MaxLength            int  // dalam karakter (rune)
+	MaxBytes             int  // 0 = tanpa batas; 72 untuk bcrypt
+	MinCharClasses       int  // dari: huruf kecil, huruf besar, angka, simbol
+	DisallowPersonalInfo bool // tolak password yang memuat email / nama user
+	HistorySize          int  // tolak N password terakhir; 0 = nonaktif
+}
+
+type passwordPolicy struct {
+	cfg      PasswordPolicyConfig
+	hasher   contract.PasswordHasher
+	history  contract.PasswordHistoryRepository // nil = tanpa riwayat
+	breached contract.BreachedPasswordChecker   // nil = tanpa cek kebocoran
+}
+
+var _ contract.PasswordPolicy = (*passwordPolicy)(nil)
+
+func NewPasswordPolicy(
+	cfg PasswordPolicyConfig,
+	hasher contract.PasswordHasher,
+	history contract.PasswordHistoryRepository, // opsional
+	breached contract.BreachedPasswordChecker, // opsional
+) contract.PasswordPolicy {
+	if hasher == nil {
+		panic("NewPasswordPolicy: hasher is nil")
+	}
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = 8
+	}
+	if cfg.MaxLength <= 0 {
+		cfg.MaxLength = 128
+	}
+	if cfg.MinCharClasses > 4 {
+		cfg.MinCharClasses = 4
+	}
+	return &passwordPolicy{cfg: cfg, hasher: hasher, history: history, breached: breached}
+}
+
+func (p *passwordPolicy) Check(ctx context.Context, plain string, u domain.User) error {
+	var out []PasswordViolation
+	add := func(code, format string, args ...any) {
+		out = append(out, PasswordViolation{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	n := utf8.RuneCountInString(plain)
+	if n < p.cfg.MinLength {
+		add(ViolationTooShort, "password must be at least %d characters", p.cfg.MinLength)
+	}
+	if n > p.cfg.MaxLength {
+		add(ViolationTooLong, "password must be at most %d characters", p.cfg.MaxLength)
+	}
+	if p.cfg.MaxBytes > 0 && len(plain) > p.cfg.MaxBytes {
+		add(ViolationTooManyBytes, "password must be at most %d bytes", p.cfg.MaxBytes)
+	}
+	if p.cfg.MinCharClasses > 0 && charClasses(plain) < p.cfg.MinCharClasses {
+		add(ViolationCharClasses, "password must mix at least %d of: lowercase, uppercase, digits, symbols", p.cfg.MinCharClasses)
+	}
+	if p.cfg.DisallowPersonalInfo && containsPersonalInfo(plain, u) {
+		add(ViolationPersonalInfo, "password must not contain your email or name")
+	}
+
+	// cek yang mahal hanya kalau aturan dasar lolos
+	if len(out) == 0 {
+		reused, err := p.reused(ctx, plain, u)
+		if err != nil {
+			return err
+		}
+		if reused {
+			add(ViolationReused, "password was used recently, choose a different one")
+		}
+		if p.breached != nil {
+			hit, err := p.breached.IsBreached(ctx, plain)
+			if err != nil {
+				return err
+			}
+			if hit {
+				add(ViolationBreached, "password appears in a known data breach, choose a different one")
+			}
+		}
+	}
+
+	if len(out) > 0 {
+		return &PasswordPolicyError{Violations: out}
+	}
+	return nil
+}
+
+// reused: bandingkan dengan hash saat ini dan N-1 hash sebelumnya
+func (p *passwordPolicy) reused(ctx context.Context, plain string, u domain.User) (bool, error) {
+	if p.history == nil || p.cfg.HistorySize <= 0 {
+		return false, nil
+	}
+	if u.PasswordHash != nil && p.hasher.Verify(u.PasswordAlg, plain, *u.PasswordHash) {
+		return true, nil
+	}
+	entries, err := p.history.Recent(ctx, u.UserID, p.cfg.HistorySize)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if p.hasher.Verify(e.PasswordAlg, plain, e.PasswordHash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *passwordPolicy) Remember(ctx context.Context, u domain.User) error {
+	if p.history == nil || p.cfg.HistorySize <= 0 || u.PasswordHash == nil {
+		return nil
+	}
+	at := u.UpdatedAt
+	if u.PasswordUpdatedAt != nil {
+		at = *u.PasswordUpdatedAt
+	}
+	return p.history.Add(ctx, domain.PasswordHistoryEntry{
+		UserID:       u.UserID,
+		PasswordHash: *u.PasswordHash,
+		PasswordAlg:  u.PasswordAlg,
+		CreatedAt:    at,
+	}, p.cfg.HistorySize)
+}
+
+func charClasses(s string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// containsPersonalInfo: local part email dan nama (utuh atau per kata) minimal 3 karakter
+func containsPersonalInfo(plain string, u domain.User) bool {
+	pw := strings.ToLower(plain)
+	var parts []string
+	if local, _, ok := strings.Cut(u.Email, "@"); ok {
+		parts = append(parts, local)
+	}
+	if u.DisplayName != nil {
+		parts = append(parts, *u.DisplayName)
+		parts = append(parts, strings.Fields(*u.DisplayName)...)
+	}
+	for _, s := range parts {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if utf8.RuneCountInString(s) >= 3 && strings.Contains(pw, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"xeed/apps/cp-api/internal/adapter/security"
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// violationCodes: kode pelanggaran dari err; nil kalau password lolos.
+func violationCodes(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	pe, ok := IsPasswordPolicyError(err)
+	if !ok {
+		t.Fatalf("err = %v; want *PasswordPolicyError", err)
+	}
+	codes := make([]string, len(pe.Violations))
+	for i, v := range pe.Violations {
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPasswordPolicyViolations(t *testing.T) {
+	e := newTestEnv(t)
+	name := "Budi Santoso"
+	u := domain.User{UserID: e.ids.New(), Email: "budi.s@xeed.test", DisplayName: &name}
+
+	cases := []struct {
+		name  string
+		cfg   PasswordPolicyConfig
+		plain string
+		want  []string
+	}{
+		{"ok", PasswordPolicyConfig{}, "lapangan hijau", nil},
+		{"default min length", PasswordPolicyConfig{}, "pendek1", []string{ViolationTooShort}},
+		{"min length counts runes", PasswordPolicyConfig{MinLength: 4}, "äöü", []string{ViolationTooShort}},
+		{"max length", PasswordPolicyConfig{MaxLength: 10}, "lapangan hijau", []string{ViolationTooLong}},
+		{"max bytes", PasswordPolicyConfig{MaxBytes: 10}, "äöüäöüäöü", []string{ViolationTooManyBytes}},
+		{"char classes", PasswordPolicyConfig{MinCharClasses: 3}, "lapanganhijau", []string{ViolationCharClasses}},
+		{"char classes met", PasswordPolicyConfig{MinCharClasses: 4}, "Lapangan-42", nil},
+		{"char classes capped at 4", PasswordPolicyConfig{MinCharClasses: 9}, "Lapangan-42", nil},
+		{"email local part", PasswordPolicyConfig{DisallowPersonalInfo: true}, "xx-BUDI.S-xx", []string{ViolationPersonalInfo}},
+		{"name word", PasswordPolicyConfig{DisallowPersonalInfo: true}, "santoso-lapangan", []string{ViolationPersonalInfo}},
+		{"personal info allowed", PasswordPolicyConfig{}, "santoso-lapangan", nil},
+		{"several at once", PasswordPolicyConfig{MinCharClasses: 2, DisallowPersonalInfo: true}, "budi",
+			[]string{ViolationTooShort, ViolationCharClasses, ViolationPersonalInfo}},
+	}
+	for _, tc := range cases {
+		p := NewPasswordPolicy(tc.cfg, e.hasher, nil, nil)
+		err := p.Check(context.Background(), tc.plain, u)
+		if got := violationCodes(t, err); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: violations = %v; want %v", tc.name, got, tc.want)
+		}
+		if tc.want != nil && !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("%s: err = %v; want ErrWeakPassword", tc.name, err)
+		}
+	}
+}
+
+// Pelanggaran dilaporkan per field; onField memindahkannya ke field lain.
+func TestPasswordPolicyErrorFields(t *testing.T) {
+	e := newTestEnv(t)
+	err := onField(e.policy.Check(context.Background(), "a", domain.User{}), "newPassword")
+	ae := apperr.From(err)
+	if ae.Kind != apperr.KindValidation || ae.Code != ErrWeakPassword.Code || len(ae.Fields) != 2 {
+		t.Fatalf("AppError = %+v; want validation with 2 fields", ae)
+	}
+	for _, f := range ae.Fields {
+		if f.Field != "newPassword" {
+			t.Errorf("field = %q; want newPassword", f.Field)
+		}
+	}
+}
+
+func TestPasswordPolicyHistory(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	history := &historyStub{entries: map[uuid.UUID][]domain.PasswordHistoryEntry{}}
+	u := domain.User{UserID: e.ids.New(), Email: "budi@xeed.test"}
+
+	// tiga password berturut-turut; riwayat hanya menyimpan 2 terbaru
+	remembering := NewPasswordPolicy(PasswordPolicyConfig{HistorySize: 2}, e.hasher, history, nil)
+	for _, plain := range []string{"password-satu", "password-dua", "password-tiga"} {
+		hash, alg, at, err := e.hasher.Hash(plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u.PasswordHash, u.PasswordAlg, u.PasswordUpdatedAt = &hash, alg, &at
+		if err := remembering.Remember(ctx, u); err != nil {
+			t.Fatalf("Remember: %v", err)
+		}
+	}
+	if n := len(history.entries[u.UserID]); n != 2 {
+		t.Fatalf("history size = %d; want 2", n)
+	}
+
+	cases := []struct {
+		name    string
+		history contract.PasswordHistoryRepository
+		size    int
+		plain   string
+		want    []string
+	}{
+		{"current password", history, 2, "password-tiga", []string{ViolationReused}},
+		{"previous password", history, 2, "password-dua", []string{ViolationReused}},
+		{"dropped from history", history, 2, "password-satu", nil},
+		{"outside checked size", history, 1, "password-dua", nil},
+		{"new password", history, 2, "password-empat", nil},
+		{"history disabled", history, 0, "password-tiga", nil},
+		{"no history repo", nil, 2, "password-tiga", nil},
+	}
+	for _, tc := range cases {
+		p := NewPasswordPolicy(PasswordPolicyConfig{HistorySize: tc.size}, e.hasher, tc.history, nil)
+		if got := violationCodes(t, p.Check(ctx, tc.plain, u)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: violations = %v; want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// writeCorpus menulis file corpus berformat Pwned Passwords (terurut hash).
+func writeCorpus(t *testing.T, counts map[string]int) string {
+	t.Helper()
+	var lines []string
+	for plain, n := range counts {
+		sum := sha1.Sum([]byte(plain))
+		lines = append(lines, strings.ToUpper(hex.EncodeToString(sum[:]))+":"+strconv.Itoa(n))
+	}
+	slices.Sort(lines)
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPasswordPolicyBreachedCorpus(t *testing.T) {
+	e := newTestEnv(t)
+	path := writeCorpus(t, map[string]int{"password1234": 250, "lapangan hijau": 1, "abc": 999})
+	u := domain.User{UserID: e.ids.New(), Email: "budi@xeed.test"}
+
+	cases := []struct {
+		name     string
+		minCount int
+		plain    string
+		want     []string
+	}{
+		{"common breach", 1, "password1234", []string{ViolationBreached}},
+		{"rare breach", 1, "lapangan hijau", []string{ViolationBreached}},
+		{"rare breach below min count", 2, "lapangan hijau", nil},
+		{"common breach at min count", 250, "password1234", []string{ViolationBreached}},
+		{"common breach below min count", 251, "password1234", nil},
+		{"not breached", 1, "kuda benar 42", nil},
+		// cek corpus hanya setelah aturan dasar lolos
+		{"basic rules first", 1, "abc", []string{ViolationTooShort}},
+	}
+	for _, tc := range cases {
+		corpus, err := security.LoadBreachedCorpus(path, tc.minCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := NewPasswordPolicy(PasswordPolicyConfig{}, e.hasher, nil, corpus)
+		if got := violationCodes(t, p.Check(context.Background(), tc.plain, u)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: violations = %v; want %v", tc.name, got, tc.want)
+		}
+		corpus.Close()
+	}
+}
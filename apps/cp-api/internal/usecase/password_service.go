@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+var (
+	ErrInvalidResetToken      = fieldError("token", "invalid_reset_token", "invalid or expired reset token")
+	ErrInvalidCurrentPassword = fieldError("currentPassword", "invalid_current_password", "current password is incorrect")
+	ErrPasswordUnchanged      = fieldError("newPassword", "password_unchanged", "new password must differ from the current one")
+)
+
+type passwordService struct {
+	users    contract.UserRepository
+	tokens   contract.ActionTokenRepository
+	mailer   contract.EmailSender
+	hasher   contract.PasswordHasher
+	policy   contract.PasswordPolicy
+	sessions contract.SessionService
+	clock    contract.Clock
+	idgen    contract.IDGen
+	opaque   contract.OpaqueTokenGen
+	resetTTL time.Duration
+	resetURL string // link di email, token ditambahkan sebagai ?token=
+}
+
+var _ contract.PasswordService = (*passwordService)(nil)
+
+func NewPasswordService(
+	users contract.UserRepository,
+	tokens contract.ActionTokenRepository,
+	mailer contract.EmailSender,
+	hasher contract.PasswordHasher,
+	policy contract.PasswordPolicy,
+	sessions contract.SessionService,
+	clk contract.Clock,
+	idg contract.IDGen,
+	opaque contract.OpaqueTokenGen,
+	resetTTL time.Duration,
+	resetURL string,
+) contract.PasswordService {
+	if users == nil {
+		panic("NewPasswordService: users is nil")
+	}
+	if tokens == nil {
+		panic("NewPasswordService: tokens is nil")
+	}
+	if mailer == nil {
+		panic("NewPasswordService: mailer is nil")
+	}
+	if hasher == nil {
+		panic("NewPasswordService: hasher is nil")
+	}
+	if policy == nil {
+		panic("NewPasswordService: policy is nil")
+	}
+	if sessions == nil {
+		panic("NewPasswordService: sessions is nil")
+	}
+	if clk == nil {
+		panic("NewPasswordService: clock is nil")
+	}
+	if idg == nil {
+		panic("NewPasswordService: idgen is nil")
+	}
+	if opaque == nil {
+		panic("NewPasswordService: opaque is nil")
+	}
+	if resetTTL <= 0 {
+		resetTTL = 30 * time.Minute
+	}
+	return &passwordService{
+		users: users, tokens: tokens, mailer: mailer, hasher: hasher, policy: policy, sessions: sessions,
+		clock: clk, idgen: idg, opaque: opaque, resetTTL: resetTTL, resetURL: resetURL,
+	}
+}
+
+// ForgotPassword tidak membocorkan apakah email terdaftar: email yang tidak
+// dikenal diperlakukan sukses tanpa mengirim apa pun, dan email dikirim di
+// background supaya error/latensi mailer tidak terlihat dari respons.
+func (s *passwordService) ForgotPassword(ctx context.Context, in dto.ForgotPasswordRequest) error {
+	email := strings.ToLower(strings.TrimSpace(in.Email))
+	if email == "" {
+		return nil
+	}
+	u, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if u == nil || u.IsServiceAccount {
+		return nil
+	}
+
+	now := s.clock.Now()
+	// hanya satu link reset yang berlaku
+	if err := s.tokens.InvalidateForUser(ctx, u.UserID, domain.PurposePasswordReset, now); err != nil {
+		return err
+	}
+	plain, hash, err := s.opaque.New()
+	if err != nil {
+		return err
+	}
+	t := domain.ActionToken{
+		TokenID:   s.idgen.New(),
+		UserID:    u.UserID,
+		Purpose:   domain.PurposePasswordReset,
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.resetTTL),
+		CreatedAt: now,
+	}
+	if err := s.tokens.Create(ctx, t); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Someone requested a password reset for your account.\n"+
+		"Open the link below to choose a new password (valid for %s):\n\n%s\n\n"+
+		"If this wasn't you, you can ignore this email.\n",
+		s.resetTTL, tokenLink(s.resetURL, plain))
+	sendEmailAsync(ctx, s.mailer, u.Email, "Reset your password", body)
+	return nil
+}
+
+const mailTimeout = 30 * time.Second
+
+// sendEmailAsync mengirim email tanpa menunggu hasilnya; error hanya di-log.
+func sendEmailAsync(ctx context.Context, mailer contract.EmailSender, to, subject, body string) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, mailTimeout)
+		defer cancel()
+		if err := mailer.SendEmail(ctx, to, subject, body); err != nil {
+			log.Printf("[mail] send %q to %s: %v", subject, to, err)
+		}
+	}()
+}
+
+// ResetPassword mengganti password dan mencabut semua sesi user.
+func (s *passwordService) ResetPassword(ctx context.Context, in dto.ResetPasswordRequest) error {
+	plain := strings.TrimSpace(in.Token)
+	if plain == "" {
+		return ErrInvalidResetToken
+	}
+	now := s.clock.Now()
+	hash := s.opaque.Hash(plain)
+	t, err := s.tokens.GetActive(ctx, domain.PurposePasswordReset, hash, now)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return ErrInvalidResetToken
+	}
+	u, err := s.users.GetByID(ctx, t.UserID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return ErrInvalidResetToken
+	}
+	// policy dicek sebelum Consume supaya token tidak hangus karena password ditolak
+	if err := s.policy.Check(ctx, in.NewPassword, *u); err != nil {
+		return onField(err, "newPassword")
+	}
+	if t, err = s.tokens.Consume(ctx, domain.PurposePasswordReset, hash, now); err != nil {
+		return err
+	}
+	if t == nil {
+		return ErrInvalidResetToken
+	}
+
+	pwHash, alg, pwdAt, err := s.hasher.Hash(in.NewPassword)
+	if err != nil {
+		return err
+	}
+	u.SetPasswordHash(pwHash, pwdAt, false)
+	u.PasswordAlg = alg
+	u.UpdatedAt = now
+	u.UpdatedBy = &u.UserID
+
+	updated, err := s.users.Update(ctx, *u)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return ErrInvalidResetToken
+	}
+	if err := s.policy.Remember(ctx, *updated); err != nil {
+		return err
+	}
+	return s.sessions.RevokeAll(ctx, u.UserID)
+}
+
+func (s *passwordService) ChangePassword(ctx context.Context, p domain.Principal, in dto.ChangePasswordRequest) (*dto.LoginResponse, error) {
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	if u.PasswordHash == nil || !s.hasher.Verify(u.PasswordAlg, in.CurrentPassword, *u.PasswordHash) {
+		return nil, ErrInvalidCurrentPassword
+	}
+	if in.NewPassword == in.CurrentPassword {
+		return nil, ErrPasswordUnchanged
+	}
+	if err := s.policy.Check(ctx, in.NewPassword, *u); err != nil {
+		return nil, onField(err, "newPassword")
+	}
+
+	hash, alg, pwdAt, err := s.hasher.Hash(in.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	u.SetPasswordHash(hash, pwdAt, false)
+	u.PasswordAlg = alg
+	u.UpdatedAt = now
+	u.UpdatedBy = &u.UserID
+
+	updated, err := s.users.Update(ctx, *u)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrUserNotFound
+	}
+	if err := s.policy.Remember(ctx, *updated); err != nil {
+		return nil, err
+	}
+	// sesi lama (termasuk token terbatas) dicabut, caller dapat sesi baru
+	if err := s.sessions.RevokeAll(ctx, u.UserID); err != nil {
+		return nil, err
+	}
+	return s.sessions.Issue(ctx, *updated)
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/adapter/security"
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// ChangePassword tidak memakai token reset maupun email.
+func (e *testEnv) passwordService() contract.PasswordService {
+	return NewPasswordService(e.users, struct{ contract.ActionTokenRepository }{}, struct{ contract.EmailSender }{},
+		e.hasher, e.policy, e.sessions, e.clock, e.ids, security.OpaqueTokens{}, time.Hour, "https://app.xeed.test/reset")
+}
+
+// Token dari ChangePassword diterbitkan pada detik yang sama dengan revokasi
+// sesi lama dan harus tetap bisa dipakai.
+func TestChangePasswordReturnsUsableSession(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	old, err := e.sessions.Issue(ctx, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := e.sessions.Authenticate(ctx, old.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.clock.Advance(time.Second)
+
+	resp, err := e.passwordService().ChangePassword(ctx, *p, dto.ChangePasswordRequest{
+		CurrentPassword: testPassword,
+		NewPassword:     "battery staple 77",
+	})
+	if err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if got, err := e.sessions.Authenticate(ctx, resp.AccessToken); err != nil || got.UserID != u.UserID {
+		t.Errorf("Authenticate(new token) = %v, %v; want principal", got, err)
+	}
+	if _, err := e.sessions.Authenticate(ctx, old.AccessToken); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("Authenticate(old token) err = %v; want ErrInvalidAccessToken", err)
+	}
+	if _, err := e.userService(nil).Login(ctx, dto.LoginRequest{Email: u.Email, Password: "battery staple 77"}); err != nil {
+		t.Errorf("Login with new password: %v", err)
+	}
+}
+
+// tokenSpy: hanya menyimpan token yang dibuat ForgotPassword.
+type tokenSpy struct {
+	contract.ActionTokenRepository
+	created []domain.ActionToken
+}
+
+func (s *tokenSpy) InvalidateForUser(context.Context, uuid.UUID, domain.TokenPurpose, time.Time) error {
+	return nil
+}
+
+func (s *tokenSpy) Create(_ context.Context, t domain.ActionToken) error {
+	s.created = append(s.created, t)
+	return nil
+}
+
+// failingMailer selalu gagal dan memberi tahu setiap kali dipanggil.
+type failingMailer struct{ calls chan string }
+
+func (m failingMailer) SendEmail(_ context.Context, to, _, _ string) error {
+	m.calls <- to
+	return errors.New("smtp: connection refused")
+}
+
+// Mailer yang gagal tidak boleh membedakan email terdaftar dari yang tidak.
+func TestForgotPasswordHidesMailerErrors(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	tokens := &tokenSpy{}
+	mailer := failingMailer{calls: make(chan string, 1)}
+	svc := NewPasswordService(e.users, tokens, mailer, e.hasher, e.policy, e.sessions, e.clock, e.ids,
+		security.OpaqueTokens{}, time.Hour, "https://app.xeed.test/reset")
+
+	for _, email := range []string{u.Email, "unknown@xeed.test"} {
+		if err := svc.ForgotPassword(ctx, dto.ForgotPasswordRequest{Email: email}); err != nil {
+			t.Errorf("ForgotPassword(%s) = %v; want nil", email, err)
+		}
+	}
+	select {
+	case to := <-mailer.calls:
+		if to != u.Email {
+			t.Errorf("mail sent to %s; want %s", to, u.Email)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reset email was never sent")
+	}
+	if len(tokens.created) != 1 {
+		t.Errorf("tokens created = %d; want 1", len(tokens.created))
+	}
+}
@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoleNotFound      = apperr.NotFound("role_not_found", "role not found")
+	ErrRoleNameTaken     = contract.ErrRoleNameTaken
+	ErrSystemRole        = apperr.Forbidden("system_role", "built-in roles cannot be changed or deleted")
+	ErrLastAdmin         = apperr.Conflict("last_admin", "at least one active admin must remain")
+	ErrInvalidRoleName   = fieldError("name", "invalid_role_name", "role name must be 2-64 chars of a-z, 0-9, '-' or '_' and start with a letter")
+	ErrUnknownPermission = fieldError("permissions", "unknown_permission", "unknown permission")
+)
+
+var rxRoleName = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,63}$`)
+
+type roleService struct {
+	roles    contract.RoleRepository
+	users    contract.UserRepository
+	clock    contract.Clock
+	idgen    contract.IDGen
+	sessions contract.SessionService
+}
+
+var _ contract.RoleService = (*roleService)(nil)
+
+func NewRoleService(
+	roles contract.RoleRepository,
+	users contract.UserRepository,
+	clk contract.Clock,
+	idg contract.IDGen,
+	sessions contract.SessionService,
+) contract.RoleService {
+	if roles == nil {
+		panic("NewRoleService: roles repo is nil")
+	}
+	if users == nil {
+		panic("NewRoleService: users repo is nil")
+	}
+	if clk == nil {
+		panic("NewRoleService: clock is nil")
+	}
+	if idg == nil {
+		panic("NewRoleService: idgen is nil")
+	}
+	if sessions == nil {
+		panic("NewRoleService: sessions is nil")
+	}
+	return &roleService{roles: roles, users: users, clock: clk, idgen: idg, sessions: sessions}
+}
+
+func (s *roleService) List(ctx context.Context) ([]domain.Role, error) {
+	return s.roles.List(ctx)
+}
+
+func (s *roleService) Get(ctx context.Context, roleID uuid.UUID) (*domain.Role, error) {
+	return s.get(ctx, roleID)
+}
+
+func (s *roleService) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	r, err := s.roles.GetByName(ctx, strings.ToLower(strings.TrimSpace(name)))
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ErrRoleNotFound
+	}
+	return r, nil
+}
+
+func (s *roleService) Create(ctx context.Context, actor *uuid.UUID, in dto.CreateRoleRequest) (*domain.Role, error) {
+	name, err := validateRoleName(in.Name)
+	if err != nil {
+		return nil, err
+	}
+	perms, err := validatePermissions(in.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	r := domain.Role{
+		RoleID:      s.idgen.New(),
+		Name:        name,
+		Permissions: perms,
+		CreatedAt:   now,
+		CreatedBy:   actor,
+		UpdatedAt:   now,
+		UpdatedBy:   actor,
+	}
+	if in.Description != nil {
+		r.Description = nilIfEmpty(strings.TrimSpace(*in.Description))
+	}
+	created, err := s.roles.Create(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[admin] role %s (%s) created by %s: permissions=%v", created.RoleID, created.Name, actorName(actor), created.Permissions)
+	return created, nil
+}
+
+// Update: kalau ada permission yang dicabut, sesi semua pemegang role dicabut.
+func (s *roleService) Update(ctx context.Context, actor *uuid.UUID, roleID uuid.UUID, in dto.UpdateRoleRequest) (*domain.Role, error) {
+	r, err := s.get(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if r.IsSystem {
+		return nil, ErrSystemRole
+	}
+	old := r.Permissions
+	if in.Name != nil {
+		if r.Name, err = validateRoleName(*in.Name); err != nil {
+			return nil, err
+		}
+	}
+	if in.Description != nil {
+		r.Description = nilIfEmpty(strings.TrimSpace(*in.Description))
+	}
+	if in.Permissions != nil {
+		if r.Permissions, err = validatePermissions(*in.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	r.UpdatedAt, r.UpdatedBy = s.clock.Now(), actor
+
+	var holders []uuid.UUID
+	if slices.ContainsFunc(old, func(p string) bool { return !r.Grants(p) }) {
+		// diambil sebelum update supaya tidak ada pemegang yang terlewat
+		if holders, err = s.roles.UserIDs(ctx, roleID); err != nil {
+			return nil, err
+		}
+	}
+	updated, err := s.roles.Update(ctx, *r)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrRoleNotFound
+	}
+	log.Printf("[admin] role %s (%s) updated by %s: permissions=%v", updated.RoleID, updated.Name, actorName(actor), updated.Permissions)
+	return updated, s.revokeAll(ctx, holders)
+}
+
+func (s *roleService) Delete(ctx context.Context, actor *uuid.UUID, roleID uuid.UUID) error {
+	r, err := s.get(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if r.IsSystem {
+		return ErrSystemRole
+	}
+	holders, err := s.roles.UserIDs(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.roles.Delete(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRoleNotFound
+	}
+	log.Printf("[admin] role %s (%s) deleted by %s", r.RoleID, r.Name, actorName(actor))
+	return s.revokeAll(ctx, holders)
+}
+
+func (s *roleService) UserRoles(ctx context.Context, userID uuid.UUID) ([]domain.Role, error) {
+	if err := s.requireUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.roles.ListByUser(ctx, userID)
+}
+
+// Assign: permission baru masuk ke token saat login/refresh berikutnya.
+func (s *roleService) Assign(ctx context.Context, actor *uuid.UUID, userID, roleID uuid.UUID) error {
+	if err := s.requireUser(ctx, userID); err != nil {
+		return err
+	}
+	r, err := s.get(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	added, err := s.roles.Assign(ctx, userID, roleID, actor, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	if added {
+		log.Printf("[admin] role %s assigned to user %s by %s", r.Name, userID, actorName(actor))
+	}
+	return nil
+}
+
+// Unassign: admin tidak bisa melepas role dirinya sendiri, dan role admin
+// tidak bisa dilepas dari admin ACTIVE terakhir (mencegah terkunci keluar).
+func (s *roleService) Unassign(ctx context.Context, actor *uuid.UUID, userID, roleID uuid.UUID) error {
+	if isSelf(actor, userID) {
+		return ErrSelfModification
+	}
+	r, err := s.get(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	isAdmin := r.IsSystem && r.Name == domain.RoleAdmin
+	if isAdmin {
+		unlock, err := s.roles.LockAdmins(ctx)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return ErrUserNotFound
+	}
+	if isAdmin {
+		if err := ensureOtherAdmin(ctx, s.roles, s.users, *u); err != nil {
+			return err
+		}
+	}
+	removed, err := s.roles.Unassign(ctx, userID, roleID)
+	if err != nil || !removed {
+		return err
+	}
+	log.Printf("[admin] role %s removed from user %s by %s", r.Name, userID, actorName(actor))
+	return s.sessions.RevokeAll(ctx, userID)
+}
+
+// ensureOtherAdmin: ErrLastAdmin kalau u adalah satu-satunya pemegang role
+// admin yang ACTIVE, jadi perubahan yang mencabut akses admin u ditolak.
+// User yang tidak ACTIVE atau bukan admin tidak mengurangi jumlah admin aktif.
+// Caller memegang roles.LockAdmins sampai perubahannya tersimpan.
+func ensureOtherAdmin(ctx context.Context, roles contract.RoleRepository, users contract.UserRepository, u domain.User) error {
+	if u.Status != domain.UserActive {
+		return nil
+	}
+	admin, err := roles.GetByName(ctx, domain.RoleAdmin)
+	if err != nil || admin == nil {
+		return err
+	}
+	holders, err := roles.UserIDs(ctx, admin.RoleID)
+	if err != nil || !slices.Contains(holders, u.UserID) {
+		return err
+	}
+	for _, id := range holders {
+		if id == u.UserID {
+			continue
+		}
+		other, err := users.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if other != nil && other.Status == domain.UserActive {
+			return nil
+		}
+	}
+	return ErrLastAdmin
+}
+
+func (s *roleService) get(ctx context.Context, roleID uuid.UUID) (*domain.Role, error) {
+	r, err := s.roles.GetByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ErrRoleNotFound
+	}
+	return r, nil
+}
+
+func (s *roleService) requireUser(ctx context.Context, userID uuid.UUID) error {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *roleService) revokeAll(ctx context.Context, userIDs []uuid.UUID) error {
+	for _, id := range userIDs {
+		if err := s.sessions.RevokeAll(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRoleName(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if !rxRoleName.MatchString(s) {
+		return "", ErrInvalidRoleName
+	}
+	return s, nil
+}
+
+// validatePermissions: hanya permission yang dikenal; hasil urut tanpa duplikat.
+func validatePermissions(perms []string) ([]string, error) {
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if !domain.IsPermission(p) {
+			return nil, apperr.Validation(ErrUnknownPermission.Code, ErrUnknownPermission.Message,
+				apperr.Field("permissions", ErrUnknownPermission.Code, "unknown permission "+p))
+		}
+		out = append(out, p)
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
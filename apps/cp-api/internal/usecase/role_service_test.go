@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+func (e *testEnv) roleService() contract.RoleService {
+	return NewRoleService(e.roles, e.users, e.clock, e.ids, e.sessions)
+}
+
+func (e *testEnv) seedRole(t *testing.T, name string, system bool, perms ...string) domain.Role {
+	t.Helper()
+	r, err := e.roles.Create(context.Background(), domain.Role{
+		RoleID: e.ids.New(), Name: name, Permissions: perms, IsSystem: system,
+		CreatedAt: e.clock.Now(), UpdatedAt: e.clock.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *r
+}
+
+func TestRoleCreate(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.roleService()
+	ctx := context.Background()
+	actor := uuid.MustParse("11111111-1111-4111-8111-111111111111")
+	desc := "  Tim support  "
+
+	r, err := svc.Create(ctx, &actor, dto.CreateRoleRequest{
+		Name:        " Support ",
+		Description: &desc,
+		Permissions: []string{domain.PermUsersWrite, domain.PermUsersRead, domain.PermUsersWrite},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if r.Name != "support" || r.Description == nil || *r.Description != "Tim support" || r.IsSystem {
+		t.Errorf("role = %q %v system=%t", r.Name, r.Description, r.IsSystem)
+	}
+	if want := []string{domain.PermUsersRead, domain.PermUsersWrite}; !reflect.DeepEqual(r.Permissions, want) {
+		t.Errorf("Permissions = %v; want %v", r.Permissions, want)
+	}
+	if r.CreatedBy == nil || *r.CreatedBy != actor {
+		t.Errorf("CreatedBy = %v; want %s", r.CreatedBy, actor)
+	}
+
+	cases := []struct {
+		name string
+		in   dto.CreateRoleRequest
+		err  error
+	}{
+		{"duplicate", dto.CreateRoleRequest{Name: "SUPPORT"}, ErrRoleNameTaken},
+		{"empty name", dto.CreateRoleRequest{Name: " "}, ErrInvalidRoleName},
+		{"bad name", dto.CreateRoleRequest{Name: "tim support"}, ErrInvalidRoleName},
+		{"unknown permission", dto.CreateRoleRequest{Name: "ops", Permissions: []string{"users:*"}}, ErrUnknownPermission},
+	}
+	for _, tc := range cases {
+		if _, err := svc.Create(ctx, nil, tc.in); !errors.Is(err, tc.err) {
+			t.Errorf("%s: err = %v; want %v", tc.name, err, tc.err)
+		}
+	}
+}
+
+// Permission role masuk ke scope access token dan dipakai Authorizer.
+func TestRolePermissionsInAccessToken(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.roleService()
+	ctx := context.Background()
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	support := e.seedRole(t, "support", false, domain.PermUsersRead, domain.PermUsersWrite)
+	auditor := e.seedRole(t, "auditor", false, domain.PermUsersRead, domain.PermRolesRead)
+
+	for _, r := range []domain.Role{support, auditor} {
+		if err := svc.Assign(ctx, nil, u.UserID, r.RoleID); err != nil {
+			t.Fatalf("Assign(%s): %v", r.Name, err)
+		}
+	}
+	resp, err := e.sessions.Issue(ctx, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued := e.signer.Issued()
+	want := []string{domain.PermRolesRead, domain.PermUsersRead, domain.PermUsersWrite}
+	if got := issued[len(issued)-1].Scopes; !reflect.DeepEqual(got, want) {
+		t.Errorf("access token scopes = %v; want %v", got, want)
+	}
+
+	p, err := e.sessions.Authenticate(ctx, resp.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authz := NewAuthorizer()
+	for perm, allowed := range map[string]bool{
+		domain.PermUsersWrite: true,
+		domain.PermRolesRead:  true,
+		domain.PermRolesWrite: false,
+		"unknown:perm":        false,
+	} {
+		if ok, err := authz.Can(ctx, *p, perm, ""); err != nil || ok != allowed {
+			t.Errorf("Can(%s) = %t, %v; want %t", perm, ok, err, allowed)
+		}
+	}
+	restricted := *p
+	restricted.Scopes = append([]string{domain.ScopePasswordChangeOnly}, p.Scopes...)
+	if ok, _ := authz.Can(ctx, restricted, domain.PermUsersRead, ""); ok {
+		t.Error("restricted token must not be authorized")
+	}
+}
+
+func TestRoleUnassignRevokesSessions(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.roleService()
+	ctx := context.Background()
+	admin := e.seedUser(t, "admin@xeed.test", testPassword)
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	role := e.seedRole(t, domain.RoleAdmin, true, domain.Permissions...)
+	for _, id := range []uuid.UUID{admin.UserID, u.UserID} {
+		if err := svc.Assign(ctx, nil, id, role.RoleID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	self := admin.UserID
+	if err := svc.Unassign(ctx, &self, self, role.RoleID); !errors.Is(err, ErrSelfModification) {
+		t.Errorf("Unassign(self) err = %v; want ErrSelfModification", err)
+	}
+	if err := svc.Unassign(ctx, &self, u.UserID, role.RoleID); err != nil {
+		t.Fatalf("Unassign: %v", err)
+	}
+	if !e.refresh.allRevoked(u.UserID) {
+		t.Error("sessions not revoked after Unassign")
+	}
+	if roles, _ := svc.UserRoles(ctx, u.UserID); len(roles) != 0 {
+		t.Errorf("UserRoles after Unassign = %v", roles)
+	}
+
+	if err := svc.Assign(ctx, nil, uuid.New(), role.RoleID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Assign(unknown user) err = %v; want ErrUserNotFound", err)
+	}
+	if err := svc.Assign(ctx, nil, u.UserID, uuid.New()); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("Assign(unknown role) err = %v; want ErrRoleNotFound", err)
+	}
+}
+
+func TestRoleUpdateRevokesOnlyWhenPermissionRemoved(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.roleService()
+	ctx := context.Background()
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	role := e.seedRole(t, "support", false, domain.PermUsersRead)
+	if err := svc.Assign(ctx, nil, u.UserID, role.RoleID); err != nil {
+		t.Fatal(err)
+	}
+
+	grow := []string{domain.PermUsersRead, domain.PermUsersWrite}
+	if _, err := svc.Update(ctx, nil, role.RoleID, dto.UpdateRoleRequest{Permissions: &grow}); err != nil {
+		t.Fatalf("Update(grow): %v", err)
+	}
+	if e.refresh.allRevoked(u.UserID) {
+		t.Error("adding a permission must not revoke sessions")
+	}
+
+	shrink := []string{domain.PermUsersWrite}
+	got, err := svc.Update(ctx, nil, role.RoleID, dto.UpdateRoleRequest{Permissions: &shrink})
+	if err != nil {
+		t.Fatalf("Update(shrink): %v", err)
+	}
+	if !reflect.DeepEqual(got.Permissions, shrink) {
+		t.Errorf("Permissions = %v; want %v", got.Permissions, shrink)
+	}
+	if !e.refresh.allRevoked(u.UserID) {
+		t.Error("removing a permission must revoke holders' sessions")
+	}
+}
+
+func TestRoleDeleteRevokesHolders(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.roleService()
+	ctx := context.Background()
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	role := e.seedRole(t, "support", false, domain.PermUsersRead)
+	if err := svc.Assign(ctx, nil, u.UserID, role.RoleID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.Delete(ctx, nil, role.RoleID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if !e.refresh.allRevoked(u.UserID) {
+		t.Error("sessions not revoked after Delete")
+	}
+	if _, err := svc.Get(ctx, role.RoleID); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("Get after Delete err = %v; want ErrRoleNotFound", err)
+	}
+}
+
+func TestRoleUnassignKeepsLastActiveAdmin(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.roleService()
+	ctx := context.Background()
+	role := e.seedRole(t, domain.RoleAdmin, true, domain.Permissions...)
+	support := e.seedRole(t, "support", false, domain.PermUsersRead)
+	a := e.seedUser(t, "admin@xeed.test", testPassword)
+	b := e.seedUser(t, "admin2@xeed.test", testPassword, func(u *domain.User) { u.Lock() })
+	for _, id := range []uuid.UUID{a.UserID, b.UserID} {
+		if err := svc.Assign(ctx, nil, id, role.RoleID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.Assign(ctx, nil, a.UserID, support.RoleID); err != nil {
+		t.Fatal(err)
+	}
+
+	// b terkunci, jadi a satu-satunya admin aktif
+	if err := svc.Unassign(ctx, nil, a.UserID, role.RoleID); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("Unassign(last admin) err = %v; want ErrLastAdmin", err)
+	}
+	if err := svc.Unassign(ctx, nil, a.UserID, support.RoleID); err != nil {
+		t.Errorf("Unassign(other role): %v", err)
+	}
+	if err := svc.Unassign(ctx, nil, b.UserID, role.RoleID); err != nil {
+		t.Errorf("Unassign(locked admin): %v", err)
+	}
+	if roles, _ := svc.UserRoles(ctx, a.UserID); len(roles) != 1 || roles[0].RoleID != role.RoleID {
+		t.Errorf("UserRoles(last admin) = %v; want [admin]", roles)
+	}
+}
+
+func TestSystemRoleIsProtected(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.roleService()
+	ctx := context.Background()
+	role := e.seedRole(t, domain.RoleAdmin, true, domain.Permissions...)
+	name := "superuser"
+
+	if _, err := svc.Update(ctx, nil, role.RoleID, dto.UpdateRoleRequest{Name: &name}); !errors.Is(err, ErrSystemRole) {
+		t.Errorf("Update(system) err = %v; want ErrSystemRole", err)
+	}
+	if err := svc.Delete(ctx, nil, role.RoleID); !errors.Is(err, ErrSystemRole) {
+		t.Errorf("Delete(system) err = %v; want ErrSystemRole", err)
+	}
+	if got, err := svc.GetByName(ctx, "ADMIN"); err != nil || got.RoleID != role.RoleID {
+		t.Errorf("GetByName(ADMIN) = %v, %v", got, err)
+	}
+}
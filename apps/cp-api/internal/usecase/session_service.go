@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = apperr.Unauthorized("invalid_refresh_token", "invalid refresh token")
+	ErrRefreshTokenReused  = apperr.Unauthorized("refresh_token_reused", "refresh token reuse detected")
+	ErrInvalidAccessToken  = apperr.Unauthorized("invalid_token", "invalid or revoked access token")
+)
+
+type sessionService struct {
+	users      contract.UserRepository
+	refresh    contract.RefreshTokenRepository
+	revoked    contract.RevocationStore
+	clock      contract.Clock
+	idgen      contract.IDGen
+	signer     contract.TokenSigner
+	verifier   contract.TokenVerifier
+	tokens     contract.OpaqueTokenGen
+	refreshTTL time.Duration
+	perms      contract.PermissionResolver // nil = token tanpa permission scope
+}
+
+var _ contract.SessionService = (*sessionService)(nil)
+
+func NewSessionService(
+	users contract.UserRepository,
+	refresh contract.RefreshTokenRepository,
+	revoked contract.RevocationStore,
+	clk contract.Clock,
+	idg contract.IDGen,
+	signer contract.TokenSigner,
+	verifier contract.TokenVerifier,
+	tokens contract.OpaqueTokenGen,
+	refreshTTL time.Duration,
+	perms contract.PermissionResolver, // opsional
+) contract.SessionService {
+	if users == nil {
+		panic("NewSessionService: users is nil")
+	}
+	if refresh == nil {
+		panic("NewSessionService: refresh repo is nil")
+	}
+	if revoked == nil {
+		panic("NewSessionService: revocation store is nil")
+	}
+	if clk == nil {
+		panic("NewSessionService: clock is nil")
+	}
+	if idg == nil {
+		panic("NewSessionService: idgen is nil")
+	}
+	if signer == nil {
+		panic("NewSessionService: signer is nil")
+	}
+	if verifier == nil {
+		panic("NewSessionService: verifier is nil")
+	}
+	if tokens == nil {
+		panic("NewSessionService: tokens is nil")
+	}
+	if refreshTTL <= 0 {
+		refreshTTL = 30 * 24 * time.Hour
+	}
+	return &sessionService{
+		users: users, refresh: refresh, revoked: revoked, clock: clk, idgen: idg,
+		signer: signer, verifier: verifier, tokens: tokens, refreshTTL: refreshTTL, perms: perms,
+	}
+}
+
+// Issue membuat sesi baru (family baru) untuk user yang sudah terautentikasi.
+func (s *sessionService) Issue(ctx context.Context, u domain.User) (*dto.LoginResponse, error) {
+	now := s.clock.Now()
+	plain, hash, err := s.tokens.New()
+	if err != nil {
+		return nil, err
+	}
+	familyID := s.idgen.New()
+	rt := domain.RefreshToken{
+		TokenID:   s.idgen.New(),
+		FamilyID:  familyID,
+		UserID:    u.UserID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	}
+	if err := s.refresh.Create(ctx, rt); err != nil {
+		return nil, err
+	}
+	return s.respond(ctx, u, familyID, plain, now)
+}
+
+func (s *sessionService) IssueRestricted(_ context.Context, u domain.User) (*dto.LoginResponse, error) {
+	now := s.clock.Now()
+	tok, err := s.signer.Sign(contract.AccessClaims{
+		ID:        s.idgen.New(),
+		UserID:    u.UserID,
+		Email:     u.Email,
+		SessionID: s.idgen.New(), // tidak ada refresh token untuk sesi ini
+		Scopes:    []string{domain.ScopePasswordChangeOnly},
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{
+		AccessToken:        tok,
+		MustChangePassword: true,
+		User:               ptr(dto.ToUserResponse(u)),
+	}, nil
+}
+
+// Refresh merotasi refresh token. Token yang sudah pernah dipakai dianggap
+// bocor: seluruh family di-revoke.
+func (s *sessionService) Refresh(ctx context.Context, in dto.RefreshRequest) (*dto.LoginResponse, error) {
+	plain := strings.TrimSpace(in.RefreshToken)
+	if plain == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	now := s.clock.Now()
+	cur, err := s.refresh.GetByHash(ctx, s.tokens.Hash(plain))
+	if err != nil {
+		return nil, err
+	}
+	if cur == nil || cur.IsRevoked() || cur.IsExpired(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if cur.IsUsed() {
+		return nil, s.reuseDetected(ctx, *cur, now)
+	}
+
+	u, err := s.users.GetByID(ctx, cur.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		_ = s.refresh.RevokeFamily(ctx, cur.FamilyID, now)
+		return nil, ErrInvalidRefreshToken
+	}
+	if err := checkAccountStatus(*u); err != nil {
+		if rerr := s.refresh.RevokeFamily(ctx, cur.FamilyID, now); rerr != nil {
+			return nil, rerr
+		}
+		return nil, err
+	}
+	if u.MustChangePassword {
+		// dipaksa ganti password setelah sesi dibuat: sesi penuh diakhiri
+		if err := s.refresh.RevokeFamily(ctx, cur.FamilyID, now); err != nil {
+			return nil, err
+		}
+		return s.IssueRestricted(ctx, *u)
+	}
+
+	nextPlain, nextHash, err := s.tokens.New()
+	if err != nil {
+		return nil, err
+	}
+	next := domain.RefreshToken{
+		TokenID:   s.idgen.New(),
+		FamilyID:  cur.FamilyID,
+		UserID:    cur.UserID,
+		TokenHash: nextHash,
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	}
+	ok, err := s.refresh.Rotate(ctx, cur.TokenID, next, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// kalah race dengan request lain yang memakai token yang sama
+		return nil, s.reuseDetected(ctx, *cur, now)
+	}
+
+	return s.respond(ctx, *u, cur.FamilyID, nextPlain, now)
+}
+
+func (s *sessionService) Authenticate(ctx context.Context, accessToken string) (*domain.Principal, error) {
+	if accessToken == "" {
+		return nil, ErrInvalidAccessToken
+	}
+	c, err := s.verifier.Verify(accessToken, s.clock.Now())
+	if err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+	revoked, err := s.revoked.IsRevoked(ctx, *c)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidAccessToken
+	}
+	return &domain.Principal{
+		UserID:    c.UserID,
+		Email:     c.Email,
+		Scopes:    c.Scopes,
+		SessionID: c.SessionID,
+		TokenID:   c.ID,
+		IssuedAt:  c.IssuedAt,
+		ExpiresAt: c.ExpiresAt,
+	}, nil
+}
+
+// Logout mencabut access token saat ini dan seluruh refresh token di sesinya.
+func (s *sessionService) Logout(ctx context.Context, p domain.Principal) error {
+	now := s.clock.Now()
+	if err := s.refresh.RevokeFamily(ctx, p.SessionID, now); err != nil {
+		return err
+	}
+	return s.revoked.RevokeToken(ctx, p.TokenID, p.UserID, p.ExpiresAt)
+}
+
+// LogoutAll mencabut semua sesi user, termasuk access token yang masih berlaku.
+func (s *sessionService) LogoutAll(ctx context.Context, p domain.Principal) error {
+	return s.RevokeAll(ctx, p.UserID)
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	now := s.clock.Now()
+	if err := s.refresh.RevokeAllForUser(ctx, userID, now); err != nil {
+		return err
+	}
+	return s.revoked.RevokeUser(ctx, userID, now)
+}
+
+func (s *sessionService) reuseDetected(ctx context.Context, t domain.RefreshToken, now time.Time) error {
+	if err := s.refresh.RevokeFamily(ctx, t.FamilyID, now); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func (s *sessionService) respond(ctx context.Context, u domain.User, sessionID uuid.UUID, refreshPlain string, now time.Time) (*dto.LoginResponse, error) {
+	scopes, err := s.scopesFor(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := s.signer.Sign(contract.AccessClaims{
+		ID:        s.idgen.New(),
+		UserID:    u.UserID,
+		Email:     u.Email,
+		SessionID: sessionID,
+		Scopes:    scopes,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{
+		AccessToken:  tok,
+		RefreshToken: refreshPlain,
+		User:         ptr(dto.ToUserResponse(u)),
+	}, nil
+}
+
+// scopesFor: permission dari role user, dihitung ulang setiap kali token
+// diterbitkan. Pencabutan role mencabut sesi, jadi scope lama tidak bertahan.
+func (s *sessionService) scopesFor(ctx context.Context, u domain.User) ([]string, error) {
+	if s.perms == nil {
+		return nil, nil
+	}
+	return s.perms.PermissionsFor(ctx, u.UserID)
+}
+
+func ptr[T any](v T) *T { return &v }
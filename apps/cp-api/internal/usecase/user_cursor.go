@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = fieldError("cursor", "invalid_cursor", "invalid or expired cursor")
+
+// userCursor: isi nextCursor (base64url JSON). Sort ikut disimpan supaya
+// cursor tidak dipakai dengan arah urutan yang lain.
+type userCursor struct {
+	Sort      contract.UserSort `json:"s"`
+	CreatedAt time.Time         `json:"t"`
+	UserID    uuid.UUID         `json:"id"`
+}
+
+func encodeUserCursor(sort contract.UserSort, c contract.UserCursor) string {
+	b, _ := json.Marshal(userCursor{Sort: sort, CreatedAt: c.CreatedAt, UserID: c.UserID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeUserCursor(s string, sort contract.UserSort) (*contract.UserCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c userCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort || c.UserID == uuid.Nil || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &contract.UserCursor{CreatedAt: c.CreatedAt, UserID: c.UserID}, nil
+}
@@ -2,20 +2,32 @@ package usecase
 
 import (
 	"context"
-	"errors"
+	"log"
 	"strings"
+	"sync"
 
 	"xeed/apps/cp-api/internal/domain"
 	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/apperr"
 	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
 )
 
 type userService struct {
-	repo   contract.UserRepository
-	clock  contract.Clock
-	idgen  contract.IDGen
-	hasher contract.PasswordHasher
-	signer contract.TokenSigner // ← baru (untuk JWT)
+	repo     contract.UserRepository
+	clock    contract.Clock
+	idgen    contract.IDGen
+	hasher   contract.PasswordHasher
+	policy   contract.PasswordPolicy
+	sessions contract.SessionService      // access + refresh token
+	verify   contract.VerificationService // nil = user langsung ACTIVE
+	guard    contract.LoginGuard          // nil = tanpa proteksi brute-force
+	mfa      contract.MFAService          // nil = MFA tidak tersedia
+
+	dummyOnce sync.Once // hash dummy untuk login email yang tidak dikenal
+	dummyHash string
+	dummyAlg  domain.PasswordAlg
 }
 
 var _ contract.UserService = (*userService)(nil)
@@ -25,7 +37,11 @@ func NewUserService(
 	clk contract.Clock,
 	idg contract.IDGen,
 	hasher contract.PasswordHasher,
-	signer contract.TokenSigner, // ← baru
+	policy contract.PasswordPolicy,
+	sessions contract.SessionService,
+	verify contract.VerificationService, // opsional
+	guard contract.LoginGuard, // opsional
+	mfa contract.MFAService, // opsional
 ) contract.UserService {
 	if repo == nil {
 		panic("NewUserService: repo is nil")
@@ -39,27 +55,33 @@ func NewUserService(
 	if hasher == nil {
 		panic("NewUserService: hasher is nil")
 	}
-	if signer == nil {
-		panic("NewUserService: signer is nil")
+	if policy == nil {
+		panic("NewUserService: policy is nil")
+	}
+	if sessions == nil {
+		panic("NewUserService: sessions is nil")
 	}
-	return &userService{repo: repo, clock: clk, idgen: idg, hasher: hasher, signer: signer}
+	return &userService{repo: repo, clock: clk, idgen: idg, hasher: hasher, policy: policy, sessions: sessions, verify: verify, guard: guard, mfa: mfa}
 }
 
+var ErrEmailTaken = contract.ErrEmailTaken
+
 func (s *userService) RegisterUser(ctx context.Context, in dto.RegisterUserRequest) (*domain.User, error) {
 	email := strings.ToLower(strings.TrimSpace(in.Email))
 	if email == "" || !strings.Contains(email, "@") {
-		return nil, errors.New("invalid email")
+		return nil, ErrInvalidEmail
 	}
-	if len(in.Password) < 8 {
-		return nil, errors.New("password min 8 chars")
+	if err := s.policy.Check(ctx, in.Password, domain.User{Email: email, DisplayName: in.DisplayName}); err != nil {
+		return nil, err
 	}
 
+	// cek awal supaya tidak perlu hashing; race antar registrasi dijaga unique index (repo -> ErrEmailTaken)
 	exist, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 	if exist != nil {
-		return nil, errors.New("email already registered")
+		return nil, ErrEmailTaken
 	}
 
 	hash, alg, pwdAt, err := s.hasher.Hash(in.Password)
@@ -67,6 +89,11 @@ func (s *userService) RegisterUser(ctx context.Context, in dto.RegisterUserReque
 		return nil, err
 	}
 
+	status := domain.UserActive
+	if s.verify != nil {
+		status = domain.UserPending // aktif setelah email diverifikasi
+	}
+
 	now := s.clock.Now()
 	u := domain.User{
 		UserID:             s.idgen.New(),
@@ -75,7 +102,7 @@ func (s *userService) RegisterUser(ctx context.Context, in dto.RegisterUserReque
 		PhoneE164:          in.PhoneE164,
 		Locale:             def(in.Locale, "en"),
 		Timezone:           def(in.Timezone, "UTC"),
-		Status:             domain.UserStatus("ACTIVE"),
+		Status:             status,
 		IsServiceAccount:   in.IsServiceAcct,
 		PasswordAlg:        alg,
 		PasswordHash:       &hash,
@@ -88,7 +115,20 @@ func (s *userService) RegisterUser(ctx context.Context, in dto.RegisterUserReque
 		UpdatedBy:          in.CreatedBy,
 	}
 
-	return s.repo.Create(ctx, u)
+	created, err := s.repo.Create(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policy.Remember(ctx, *created); err != nil {
+		return nil, err
+	}
+	if s.verify != nil {
+		// gagal kirim tidak menggagalkan registrasi; user bisa minta kirim ulang
+		if err := s.verify.SendEmailVerification(ctx, *created); err != nil {
+			log.Printf("[cp-api] send verification email to %s: %v", created.Email, err)
+		}
+	}
+	return created, nil
 }
 
 func def(s, fallback string) string {
@@ -98,13 +138,12 @@ func def(s, fallback string) string {
 	return s
 }
 
-var ErrInvalidCredential = errors.New("invalid email or password")
+var (
+	ErrInvalidCredential = apperr.Unauthorized("invalid_credentials", "invalid email or password")
+	ErrMFAUnavailable    = apperr.New(apperr.KindUnavailable, "mfa_unavailable", "mfa is not configured on this server")
+)
 
 func (s *userService) Login(ctx context.Context, in dto.LoginRequest) (*dto.LoginResponse, error) {
-	if s.signer == nil {
-		return nil, errors.New("internal: token signer not configured")
-	}
-
 	email := strings.ToLower(strings.TrimSpace(in.Email))
 	if email == "" || !strings.Contains(email, "@") {
 		return nil, ErrInvalidCredential
@@ -113,39 +152,199 @@ func (s *userService) Login(ctx context.Context, in dto.LoginRequest) (*dto.Logi
 		return nil, ErrInvalidCredential
 	}
 
+	if s.guard != nil {
+		if err := s.guard.Check(ctx, email, in.ClientIP); err != nil {
+			return nil, err
+		}
+	}
+
 	u, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
-	if u == nil || u.PasswordHash == nil {
+	if !s.verifyPassword(u, in.Password) {
+		if s.guard != nil {
+			if err := s.guard.Failure(ctx, email, in.ClientIP, u); err != nil {
+				return nil, err
+			}
+		}
 		return nil, ErrInvalidCredential
 	}
 
-	// verify password (bcrypt)
-	if !s.hasher.Verify(in.Password, *u.PasswordHash) {
-		return nil, ErrInvalidCredential
+	if s.guard != nil {
+		if err := s.guard.AutoUnlock(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+
+	// status dicek setelah password valid supaya tidak membocorkan keberadaan email
+	if err := checkAccountStatus(*u); err != nil {
+		return nil, err
 	}
 
-	// optional: cek status BLOCKED, dsb
-	// if u.Status == domain.UserBlocked { return nil, errors.New("user blocked") }
+	s.rehashIfNeeded(ctx, u, in.Password)
 
-	now := s.clock.Now()
-	tok, err := s.signer.Sign(u.UserID, u.Email, now)
+	if u.MFAEnrolled {
+		if s.mfa == nil {
+			return nil, ErrMFAUnavailable
+		}
+		// counter gagal baru di-reset setelah faktor kedua lolos (MFAService.Verify);
+		// kalau di-reset di sini, password yang bocor = percobaan TOTP tanpa batas
+		return s.mfa.Challenge(ctx, *u)
+	}
+	if s.guard != nil {
+		if err := s.guard.Success(ctx, email); err != nil {
+			return nil, err
+		}
+	}
+	if u.MustChangePassword {
+		return s.sessions.IssueRestricted(ctx, *u)
+	}
+	return s.sessions.Issue(ctx, *u)
+}
+
+// verifyPassword: email tidak dikenal / user tanpa password tetap menjalankan
+// satu Verify terhadap hash dummy supaya waktu respons tidak membocorkan
+// keberadaan akun.
+func (s *userService) verifyPassword(u *domain.User, plain string) bool {
+	if u != nil && u.PasswordHash != nil {
+		return s.hasher.Verify(u.PasswordAlg, plain, *u.PasswordHash)
+	}
+	s.dummyOnce.Do(func() {
+		var err error
+		if s.dummyHash, s.dummyAlg, _, err = s.hasher.Hash("xeed dummy password"); err != nil {
+			log.Printf("[user] dummy hash: %v", err)
+		}
+	})
+	s.hasher.Verify(s.dummyAlg, plain, s.dummyHash)
+	return false
+}
+
+// rehashIfNeeded meng-upgrade hash lama (algoritma/parameter) memakai password
+// yang baru saja terverifikasi. Gagal upgrade tidak menggagalkan login.
+func (s *userService) rehashIfNeeded(ctx context.Context, u *domain.User, plain string) {
+	if !s.hasher.NeedsRehash(u.PasswordAlg, *u.PasswordHash) {
+		return
+	}
+	hash, alg, _, err := s.hasher.Hash(plain)
+	if err != nil {
+		log.Printf("[user] rehash %s: %v", u.UserID, err)
+		return
+	}
+	next := *u
+	next.RehashPassword(hash, alg)
+	next.UpdatedAt = s.clock.Now()
+	updated, err := s.repo.Update(ctx, next)
+	if err != nil || updated == nil {
+		log.Printf("[user] rehash %s: update failed: %v", u.UserID, err)
+		return
+	}
+	*u = *updated
+}
+
+var ErrUserNotFound = apperr.NotFound("user_not_found", "user not found")
+
+func (s *userService) GetProfile(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	u, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	return u, nil
+}
 
-	resp := dto.LoginResponse{
-		AccessToken: tok,
-		User: dto.UserResponse{
-			UserID:      u.UserID,
-			Email:       u.Email,
-			DisplayName: u.DisplayName,
-			PhoneE164:   u.PhoneE164,
-			Locale:      u.Locale,
-			Timezone:    u.Timezone,
-			Status:      string(u.Status),
-		},
+// GetMe: profil + ringkasan keamanan akun untuk endpoint /me.
+func (s *userService) GetMe(ctx context.Context, userID uuid.UUID) (*dto.MeResponse, error) {
+	u, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	me := &dto.MeResponse{
+		UserResponse:  dto.ToUserResponse(*u),
+		EmailVerified: u.EmailVerifiedAt != nil,
+		MFAEnrolled:   u.MFAEnrolled,
+	}
+	if u.MFADefaultMethod != nil {
+		m := string(*u.MFADefaultMethod)
+		me.MFADefaultMethod = &m
+	}
+	if u.MFAEnrolled && s.mfa != nil {
+		n, err := s.mfa.RecoveryCodesRemaining(ctx, u.UserID)
+		if err != nil {
+			return nil, err
+		}
+		me.RecoveryCodesRemaining = &n
+	}
+	return me, nil
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, userID uuid.UUID, in dto.UpdateProfileRequest) (*domain.User, error) {
+	u, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProfile(u, in); err != nil {
+		return nil, err
+	}
+	u.UpdatedAt = s.clock.Now()
+	u.UpdatedBy = &userID
+
+	updated, err := s.repo.Update(ctx, *u)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrUserNotFound
+	}
+	return updated, nil
+}
+
+// applyProfile memvalidasi & menerapkan field profil yang diisi (nil = tidak diubah).
+// Dipakai juga oleh AdminUserService.Update.
+func applyProfile(u *domain.User, in dto.UpdateProfileRequest) error {
+	var err error
+	displayName, avatarURL := u.DisplayName, u.AvatarURL
+	if in.DisplayName != nil {
+		v, err := validateDisplayName(*in.DisplayName)
+		if err != nil {
+			return err
+		}
+		displayName = nilIfEmpty(v)
+	}
+	if in.AvatarURL != nil {
+		avatarURL = nil
+		if v := strings.TrimSpace(*in.AvatarURL); v != "" {
+			if v, err = validateAvatarURL(v); err != nil {
+				return err
+			}
+			avatarURL = &v
+		}
+	}
+	u.SetProfile(displayName, avatarURL)
+
+	if in.PhoneE164 != nil {
+		if v := strings.TrimSpace(*in.PhoneE164); v == "" {
+			u.PhoneE164, u.PhoneVerifiedAt = nil, nil
+		} else if u.PhoneE164 == nil || *u.PhoneE164 != v {
+			if err := u.SetPhoneE164(v); err != nil {
+				return ErrInvalidPhone
+			}
+		}
+	}
+
+	var locale, tz string
+	if in.Locale != nil {
+		if locale, err = normalizeLocale(*in.Locale); err != nil {
+			return err
+		}
+	}
+	if in.Timezone != nil {
+		if tz, err = validateTimezone(*in.Timezone); err != nil {
+			return err
+		}
 	}
-	return &resp, nil
+	u.SetLocaleTimezone(locale, tz)
+	return nil
 }
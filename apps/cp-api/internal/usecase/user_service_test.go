@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/adapter/fake"
+	"xeed/apps/cp-api/internal/adapter/security"
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+const testPassword = "correct horse 42"
+
+type verifySpy struct {
+	contract.VerificationService
+	sent []domain.User
+}
+
+func (s *verifySpy) SendEmailVerification(_ context.Context, u domain.User) error {
+	s.sent = append(s.sent, u)
+	return nil
+}
+
+func (e *testEnv) userService(verify contract.VerificationService) contract.UserService {
+	return NewUserService(e.users, e.clock, e.ids, e.hasher, e.policy, e.sessions, verify, nil, nil)
+}
+
+func TestRegisterUser(t *testing.T) {
+	e := newTestEnv(t)
+	svc := e.userService(nil)
+
+	u, err := svc.RegisterUser(context.Background(), dto.RegisterUserRequest{
+		Email:    "  Budi@Xeed.TEST ",
+		Password: testPassword,
+	})
+	if err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	if u.UserID != fake.SeqID(1) {
+		t.Errorf("UserID = %s; want first fake id %s", u.UserID, fake.SeqID(1))
+	}
+	if u.Email != "budi@xeed.test" || u.Status != domain.UserActive || u.Locale != "en" || u.Timezone != "UTC" {
+		t.Errorf("user = %q %s %s %s; want normalized email, ACTIVE, en, UTC", u.Email, u.Status, u.Locale, u.Timezone)
+	}
+	if !u.CreatedAt.Equal(testNow) || !u.UpdatedAt.Equal(testNow) {
+		t.Errorf("CreatedAt/UpdatedAt = %s/%s; want %s", u.CreatedAt, u.UpdatedAt, testNow)
+	}
+	if u.PasswordHash == nil || !e.hasher.Verify(u.PasswordAlg, testPassword, *u.PasswordHash) {
+		t.Error("stored password hash does not verify")
+	}
+	stored := e.mustGet(t, u.UserID)
+	if stored.Email != u.Email {
+		t.Errorf("stored email = %q", stored.Email)
+	}
+}
+
+func TestRegisterUserWithVerification(t *testing.T) {
+	e := newTestEnv(t)
+	spy := &verifySpy{}
+
+	u, err := e.userService(spy).RegisterUser(context.Background(), dto.RegisterUserRequest{Email: "siti@xeed.test", Password: testPassword})
+	if err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	if u.Status != domain.UserPending {
+		t.Errorf("Status = %s; want PENDING", u.Status)
+	}
+	if len(spy.sent) != 1 || spy.sent[0].UserID != u.UserID {
+		t.Errorf("verification emails = %d; want 1 for %s", len(spy.sent), u.UserID)
+	}
+}
+
+func TestRegisterUserRejects(t *testing.T) {
+	e := newTestEnv(t)
+	e.seedUser(t, "ada@xeed.test", testPassword)
+	svc := e.userService(nil)
+
+	cases := []struct {
+		name string
+		in   dto.RegisterUserRequest
+		want error
+	}{
+		{"invalid email", dto.RegisterUserRequest{Email: "bukan-email", Password: testPassword}, ErrInvalidEmail},
+		{"weak password", dto.RegisterUserRequest{Email: "baru@xeed.test", Password: "pendek"}, ErrWeakPassword},
+		{"email taken", dto.RegisterUserRequest{Email: "ADA@xeed.test", Password: testPassword}, ErrEmailTaken},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := svc.RegisterUser(context.Background(), tc.in)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("RegisterUser = %v, %v; want %v", u, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	e := newTestEnv(t)
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	svc := e.userService(nil)
+
+	resp, err := svc.Login(context.Background(), dto.LoginRequest{Email: "BUDI@xeed.test", Password: testPassword})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if resp.AccessToken == "" || resp.RefreshToken == "" || resp.User == nil || resp.User.UserID != u.UserID {
+		t.Fatalf("Login response = %+v", resp)
+	}
+
+	issued := e.signer.Issued()
+	if len(issued) != 1 {
+		t.Fatalf("signed %d tokens; want 1", len(issued))
+	}
+	c := issued[0]
+	if c.UserID != u.UserID || c.Email != u.Email || !c.IssuedAt.Equal(testNow) || len(c.Scopes) != 0 {
+		t.Errorf("claims = %+v", c)
+	}
+
+	p, err := e.sessions.Authenticate(context.Background(), resp.AccessToken)
+	if err != nil || p.UserID != u.UserID {
+		t.Fatalf("Authenticate = %v, %v", p, err)
+	}
+	e.clock.Advance(16 * time.Minute)
+	if _, err := e.sessions.Authenticate(context.Background(), resp.AccessToken); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("Authenticate(expired) err = %v; want ErrInvalidAccessToken", err)
+	}
+}
+
+// Login dengan hash lama (bcrypt) meng-upgrade hash ke algoritma primary.
+func TestLoginRehashesLegacyHash(t *testing.T) {
+	e := newTestEnv(t)
+	u := e.seedUser(t, "budi@xeed.test", testPassword) // bcrypt
+	argon := security.Argon2idHasher{Params: security.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}}
+	hasher := security.NewMultiHasher(domain.AlgArgon2id, map[domain.PasswordAlg]contract.PasswordHasher{
+		domain.AlgArgon2id: argon,
+		domain.AlgBcrypt:   e.hasher,
+	})
+	svc := NewUserService(e.users, e.clock, e.ids, hasher, e.policy, e.sessions, nil, nil, nil)
+
+	if _, err := svc.Login(context.Background(), dto.LoginRequest{Email: u.Email, Password: testPassword}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	got := e.mustGet(t, u.UserID)
+	if got.PasswordAlg != domain.AlgArgon2id || got.PasswordHash == nil || !argon.Verify(got.PasswordAlg, testPassword, *got.PasswordHash) {
+		t.Fatalf("after login: alg=%s hash=%v; want argon2id", got.PasswordAlg, got.PasswordHash)
+	}
+	if !got.PasswordUpdatedAt.Equal(*u.PasswordUpdatedAt) {
+		t.Errorf("PasswordUpdatedAt = %v; want unchanged %v", got.PasswordUpdatedAt, u.PasswordUpdatedAt)
+	}
+	// login berikutnya memakai hash baru
+	if _, err := svc.Login(context.Background(), dto.LoginRequest{Email: u.Email, Password: testPassword}); err != nil {
+		t.Fatalf("Login after rehash: %v", err)
+	}
+	if again := e.mustGet(t, u.UserID); *again.PasswordHash != *got.PasswordHash {
+		t.Error("hash changed again although params are current")
+	}
+}
+
+func TestLoginRejects(t *testing.T) {
+	e := newTestEnv(t)
+	e.seedUser(t, "aktif@xeed.test", testPassword)
+	e.seedUser(t, "terkunci@xeed.test", testPassword, (*domain.User).Lock)
+	e.seedUser(t, "pending@xeed.test", testPassword, func(u *domain.User) { u.Status = domain.UserPending })
+	e.seedUser(t, "mfa@xeed.test", testPassword, func(u *domain.User) { u.EnableMFA(domain.MFATOTP) })
+	svc := e.userService(nil)
+
+	cases := []struct {
+		name, email, password string
+		want                  error
+	}{
+		{"wrong password", "aktif@xeed.test", "salah sekali 1", ErrInvalidCredential},
+		{"unknown email", "siapa@xeed.test", testPassword, ErrInvalidCredential},
+		{"empty password", "aktif@xeed.test", "", ErrInvalidCredential},
+		{"locked", "terkunci@xeed.test", testPassword, ErrAccountLocked},
+		{"pending", "pending@xeed.test", testPassword, ErrAccountPending},
+		{"locked with wrong password", "terkunci@xeed.test", "salah sekali 1", ErrInvalidCredential},
+		{"mfa without mfa service", "mfa@xeed.test", testPassword, ErrMFAUnavailable},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := svc.Login(context.Background(), dto.LoginRequest{Email: tc.email, Password: tc.password})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("Login = %+v, %v; want %v", resp, err, tc.want)
+			}
+		})
+	}
+	if n := len(e.signer.Issued()); n != 0 {
+		t.Errorf("signed %d tokens for rejected logins", n)
+	}
+}
+
+// verifyCounter menghitung panggilan Verify ke hasher asli.
+type verifyCounter struct {
+	contract.PasswordHasher
+	calls int
+}
+
+func (h *verifyCounter) Verify(alg domain.PasswordAlg, plain, hash string) bool {
+	h.calls++
+	return h.PasswordHasher.Verify(alg, plain, hash)
+}
+
+// Email tidak dikenal dan user tanpa password tetap membayar satu Verify,
+// supaya waktu respons sama dengan password salah.
+func TestLoginVerifiesDummyHash(t *testing.T) {
+	e := newTestEnv(t)
+	e.seedUser(t, "tanpa@xeed.test", testPassword, func(u *domain.User) { u.PasswordHash = nil })
+	hasher := &verifyCounter{PasswordHasher: e.hasher}
+	svc := NewUserService(e.users, e.clock, e.ids, hasher, e.policy, e.sessions, nil, nil, nil)
+
+	for _, email := range []string{"siapa@xeed.test", "tanpa@xeed.test", "siapa@xeed.test"} {
+		hasher.calls = 0
+		_, err := svc.Login(context.Background(), dto.LoginRequest{Email: email, Password: testPassword})
+		if !errors.Is(err, ErrInvalidCredential) {
+			t.Fatalf("Login(%s) = %v; want ErrInvalidCredential", email, err)
+		}
+		if hasher.calls != 1 {
+			t.Errorf("Login(%s): Verify called %d times; want 1", email, hasher.calls)
+		}
+	}
+}
+
+func TestLoginMustChangePassword(t *testing.T) {
+	e := newTestEnv(t)
+	e.seedUser(t, "ganti@xeed.test", testPassword, (*domain.User).RequirePasswordChange)
+
+	resp, err := e.userService(nil).Login(context.Background(), dto.LoginRequest{Email: "ganti@xeed.test", Password: testPassword})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !resp.MustChangePassword || resp.RefreshToken != "" {
+		t.Errorf("Login response = %+v; want restricted token without refresh token", resp)
+	}
+	issued := e.signer.Issued()
+	if len(issued) != 1 || !slices.Equal(issued[0].Scopes, []string{domain.ScopePasswordChangeOnly}) {
+		t.Errorf("claims = %+v; want only scope %q", issued, domain.ScopePasswordChangeOnly)
+	}
+}
+
+func TestGetProfileNotFound(t *testing.T) {
+	e := newTestEnv(t)
+	if _, err := e.userService(nil).GetProfile(context.Background(), fake.SeqID(99)); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("GetProfile err = %v; want ErrUserNotFound", err)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	e := newTestEnv(t)
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	svc := e.userService(nil)
+	e.clock.Advance(time.Hour)
+
+	str := func(s string) *string { return &s }
+	updated, err := svc.UpdateProfile(context.Background(), u.UserID, dto.UpdateProfileRequest{
+		DisplayName: str("  Budi  "),
+		Locale:      str("id-id"),
+		Timezone:    str("Asia/Jakarta"),
+		PhoneE164:   str("+6281234567890"),
+	})
+	if err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	if updated.DisplayName == nil || *updated.DisplayName != "Budi" || updated.Locale != "id-ID" || updated.Timezone != "Asia/Jakarta" {
+		t.Errorf("profile = %v %s %s", updated.DisplayName, updated.Locale, updated.Timezone)
+	}
+	if updated.PhoneE164 == nil || *updated.PhoneE164 != "+6281234567890" || updated.PhoneVerifiedAt != nil {
+		t.Errorf("phone = %v verifiedAt=%v", updated.PhoneE164, updated.PhoneVerifiedAt)
+	}
+	if !updated.UpdatedAt.Equal(testNow.Add(time.Hour)) || updated.UpdatedBy == nil || *updated.UpdatedBy != u.UserID {
+		t.Errorf("UpdatedAt/UpdatedBy = %s/%v", updated.UpdatedAt, updated.UpdatedBy)
+	}
+	if !updated.CreatedAt.Equal(testNow) {
+		t.Errorf("CreatedAt changed to %s", updated.CreatedAt)
+	}
+
+	cases := []struct {
+		name string
+		in   dto.UpdateProfileRequest
+		want error
+	}{
+		{"timezone", dto.UpdateProfileRequest{Timezone: str("Mars/Olympus")}, ErrInvalidTimezone},
+		{"locale", dto.UpdateProfileRequest{Locale: str("!!")}, ErrInvalidLocale},
+		{"phone", dto.UpdateProfileRequest{PhoneE164: str("0812")}, ErrInvalidPhone},
+	}
+	for _, tc := range cases {
+		if _, err := svc.UpdateProfile(context.Background(), u.UserID, tc.in); !errors.Is(err, tc.want) {
+			t.Errorf("UpdateProfile(invalid %s) err = %v; want %v", tc.name, err, tc.want)
+		}
+	}
+}
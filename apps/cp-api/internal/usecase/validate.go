@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"xeed/apps/cp-api/internal/usecase/apperr"
+
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrInvalidEmail       = fieldError("email", "invalid_email", "invalid email")
+	ErrInvalidPhone       = fieldError("phoneE164", "invalid_phone", "invalid phone (E.164, ex: +6281234567890)")
+	ErrInvalidLocale      = fieldError("locale", "invalid_locale", "invalid locale (BCP 47 tag, ex: id-ID)")
+	ErrInvalidTimezone    = fieldError("timezone", "invalid_timezone", "invalid timezone (IANA name, ex: Asia/Jakarta)")
+	ErrInvalidDisplayName = fieldError("displayName", "invalid_display_name", "display name max 100 chars")
+	ErrInvalidAvatarURL   = fieldError("avatarUrl", "invalid_avatar_url", "avatar url must be an absolute http(s) url")
+)
+
+// fieldError: error validasi untuk satu field input.
+func fieldError(field, code, msg string) *apperr.Error {
+	return apperr.Validation(code, msg, apperr.Field(field, code, msg))
+}
+
+// normalizeLocale memvalidasi BCP 47 tag dan mengembalikan bentuk kanonik (ex: "id-id" -> "id-ID").
+func normalizeLocale(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ErrInvalidLocale
+	}
+	tag, err := language.Parse(s)
+	if err != nil || tag == language.Und {
+		return "", ErrInvalidLocale
+	}
+	return tag.String(), nil
+}
+
+func validateTimezone(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	// "Local" valid untuk LoadLocation tapi tergantung host
+	if s == "" || s == "Local" {
+		return "", ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(s); err != nil {
+		return "", ErrInvalidTimezone
+	}
+	return s, nil
+}
+
+func validateDisplayName(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) > 100 {
+		return "", ErrInvalidDisplayName
+	}
+	return s, nil
+}
+
+func validateAvatarURL(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 2048 {
+		return "", ErrInvalidAvatarURL
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", ErrInvalidAvatarURL
+	}
+	return s, nil
+}
+
+// nilIfEmpty: string kosong berarti field nullable dihapus
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/contract"
+)
+
+var ErrInvalidVerificationToken = fieldError("token", "invalid_verification_token", "invalid or expired verification token")
+
+type verificationService struct {
+	users   contract.UserRepository
+	tokens  contract.ActionTokenRepository
+	mailer  contract.EmailSender
+	clock   contract.Clock
+	idgen   contract.IDGen
+	opaque  contract.OpaqueTokenGen
+	ttl     time.Duration
+	linkURL string // ex: https://app.xeed.id/verify-email (token ditambahkan sebagai ?token=)
+}
+
+var _ contract.VerificationService = (*verificationService)(nil)
+
+func NewVerificationService(
+	users contract.UserRepository,
+	tokens contract.ActionTokenRepository,
+	mailer contract.EmailSender,
+	clk contract.Clock,
+	idg contract.IDGen,
+	opaque contract.OpaqueTokenGen,
+	ttl time.Duration,
+	linkURL string,
+) contract.VerificationService {
+	if users == nil {
+		panic("NewVerificationService: users is nil")
+	}
+	if tokens == nil {
+		panic("NewVerificationService: tokens is nil")
+	}
+	if mailer == nil {
+		panic("NewVerificationService: mailer is nil")
+	}
+	if clk == nil {
+		panic("NewVerificationService: clock is nil")
+	}
+	if idg == nil {
+		panic("NewVerificationService: idgen is nil")
+	}
+	if opaque == nil {
+		panic("NewVerificationService: opaque is nil")
+	}
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &verificationService{
+		users: users, tokens: tokens, mailer: mailer, clock: clk, idgen: idg,
+		opaque: opaque, ttl: ttl, linkURL: linkURL,
+	}
+}
+
+// SendEmailVerification menerbitkan token baru (token lama dibatalkan) dan mengirimnya via email.
+func (s *verificationService) SendEmailVerification(ctx context.Context, u domain.User) error {
+	body, err := s.issue(ctx, u)
+	if err != nil {
+		return err
+	}
+	return s.mailer.SendEmail(ctx, u.Email, verifySubject, body)
+}
+
+const verifySubject = "Verify your email"
+
+// issue menyimpan token verifikasi baru dan mengembalikan isi emailnya.
+func (s *verificationService) issue(ctx context.Context, u domain.User) (string, error) {
+	now := s.clock.Now()
+	if err := s.tokens.InvalidateForUser(ctx, u.UserID, domain.PurposeEmailVerify, now); err != nil {
+		return "", err
+	}
+	plain, hash, err := s.opaque.New()
+	if err != nil {
+		return "", err
+	}
+	t := domain.ActionToken{
+		TokenID:   s.idgen.New(),
+		UserID:    u.UserID,
+		Purpose:   domain.PurposeEmailVerify,
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	}
+	if err := s.tokens.Create(ctx, t); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Verify your email address by opening the link below (valid for %s):\n\n%s\n",
+		s.ttl, tokenLink(s.linkURL, plain)), nil
+}
+
+// ResendEmailVerification tidak membocorkan apakah email terdaftar: selalu sukses
+// kecuali ada error database, dan email dikirim di background supaya error/latensi
+// mailer tidak terlihat dari respons.
+func (s *verificationService) ResendEmailVerification(ctx context.Context, in dto.ResendVerificationRequest) error {
+	email := strings.ToLower(strings.TrimSpace(in.Email))
+	if email == "" {
+		return nil
+	}
+	u, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if u == nil || u.EmailVerifiedAt != nil {
+		return nil
+	}
+	body, err := s.issue(ctx, *u)
+	if err != nil {
+		return err
+	}
+	sendEmailAsync(ctx, s.mailer, u.Email, verifySubject, body)
+	return nil
+}
+
+func (s *verificationService) VerifyEmail(ctx context.Context, in dto.VerifyEmailRequest) (*domain.User, error) {
+	plain := strings.TrimSpace(in.Token)
+	if plain == "" {
+		return nil, ErrInvalidVerificationToken
+	}
+	now := s.clock.Now()
+	t, err := s.tokens.Consume(ctx, domain.PurposeEmailVerify, s.opaque.Hash(plain), now)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	u, err := s.users.GetByID(ctx, t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	if u.EmailVerifiedAt != nil {
+		return u, nil
+	}
+
+	u.VerifyEmail(now)
+	if u.Status == domain.UserPending {
+		u.Activate()
+	}
+	u.UpdatedAt = now
+	u.UpdatedBy = &u.UserID
+
+	updated, err := s.users.Update(ctx, *u)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	return updated, nil
+}
+
+func tokenLink(base, token string) string {
+	if base == "" {
+		return token
+	}
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	return base + sep + "token=" + token
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"xeed/apps/cp-api/internal/adapter/security"
+	"xeed/apps/cp-api/internal/dto"
+)
+
+// Mailer yang gagal tidak boleh membedakan email terdaftar dari yang tidak.
+func TestResendVerificationHidesMailerErrors(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	u := e.seedUser(t, "budi@xeed.test", testPassword)
+	tokens := &tokenSpy{}
+	mailer := failingMailer{calls: make(chan string, 1)}
+	svc := NewVerificationService(e.users, tokens, mailer, e.clock, e.ids, security.OpaqueTokens{}, time.Hour, "")
+
+	for _, email := range []string{u.Email, "unknown@xeed.test"} {
+		if err := svc.ResendEmailVerification(ctx, dto.ResendVerificationRequest{Email: email}); err != nil {
+			t.Errorf("ResendEmailVerification(%s) = %v; want nil", email, err)
+		}
+	}
+	select {
+	case to := <-mailer.calls:
+		if to != u.Email {
+			t.Errorf("mail sent to %s; want %s", to, u.Email)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("verification email was never sent")
+	}
+	if len(tokens.created) != 1 {
+		t.Errorf("tokens created = %d; want 1", len(tokens.created))
+	}
+}
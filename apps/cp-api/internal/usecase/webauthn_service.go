@@ -0,0 +1,453 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/dto"
+	"xeed/apps/cp-api/internal/usecase/apperr"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidWebAuthnResponse    = apperr.Unauthorized("invalid_webauthn_response", "invalid webauthn response")
+	ErrWebAuthnCredentialExists   = apperr.Conflict("webauthn_credential_exists", "webauthn credential already registered")
+	ErrWebAuthnCredentialNotFound = apperr.NotFound("webauthn_credential_not_found", "webauthn credential not found")
+	ErrWebAuthnCloned             = apperr.Unauthorized("webauthn_clone_detected", "webauthn authenticator may be cloned")
+)
+
+var webauthnAlgs = []int64{-8, -7, -257} // EdDSA, ES256, RS256
+
+const (
+	webauthnTimeout = 5 * time.Minute
+	webauthnNameMax = 64
+)
+
+type webauthnService struct {
+	users      contract.UserRepository
+	creds      contract.WebAuthnCredentialRepository
+	challenges contract.WebAuthnChallengeRepository
+	verifier   contract.WebAuthnVerifier
+	sessions   contract.SessionService
+	clock      contract.Clock
+	idgen      contract.IDGen
+	rpName     string                  // nama yang ditampilkan browser, ex: "Xeed"
+	totp       contract.TOTPRepository // opsional: fallback saat passkey terakhir dihapus
+	otp        contract.OTPService     // opsional: idem
+}
+
+var _ contract.WebAuthnService = (*webauthnService)(nil)
+
+func NewWebAuthnService(
+	users contract.UserRepository,
+	creds contract.WebAuthnCredentialRepository,
+	challenges contract.WebAuthnChallengeRepository,
+	verifier contract.WebAuthnVerifier,
+	sessions contract.SessionService,
+	clk contract.Clock,
+	idg contract.IDGen,
+	rpName string,
+	totp contract.TOTPRepository, // opsional
+	otp contract.OTPService, // opsional
+) contract.WebAuthnService {
+	if users == nil {
+		panic("NewWebAuthnService: users is nil")
+	}
+	if creds == nil {
+		panic("NewWebAuthnService: creds is nil")
+	}
+	if challenges == nil {
+		panic("NewWebAuthnService: challenges is nil")
+	}
+	if verifier == nil {
+		panic("NewWebAuthnService: verifier is nil")
+	}
+	if sessions == nil {
+		panic("NewWebAuthnService: sessions is nil")
+	}
+	if clk == nil {
+		panic("NewWebAuthnService: clock is nil")
+	}
+	if idg == nil {
+		panic("NewWebAuthnService: idgen is nil")
+	}
+	return &webauthnService{
+		users: users, creds: creds, challenges: challenges, verifier: verifier,
+		sessions: sessions, clock: clk, idgen: idg, rpName: def(rpName, "Xeed"),
+		totp: totp, otp: otp,
+	}
+}
+
+func (s *webauthnService) BeginRegistration(ctx context.Context, p domain.Principal) (*dto.WebAuthnCreationOptions, error) {
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+	existing, err := s.creds.ListByUser(ctx, u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := s.newChallenge(ctx, &u.UserID, domain.WebAuthnRegister)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &dto.WebAuthnCreationOptions{ChallengeID: ch.ChallengeID.String()}
+	pk := &opts.PublicKey
+	pk.Challenge = b64url(ch.Challenge)
+	pk.RP = dto.WebAuthnRP{ID: s.verifier.RPID(), Name: s.rpName}
+	pk.User = dto.WebAuthnUser{ID: b64url(u.UserID[:]), Name: u.Email, DisplayName: def(deref(u.DisplayName), u.Email)}
+	for _, alg := range webauthnAlgs {
+		pk.PubKeyCredParams = append(pk.PubKeyCredParams, dto.WebAuthnCredParam{Type: "public-key", Alg: alg})
+	}
+	pk.Timeout = webauthnTimeout.Milliseconds()
+	pk.ExcludeCredentials = descriptors(existing)
+	pk.AuthenticatorSelection = dto.WebAuthnAuthenticatorSelection{ResidentKey: "preferred", UserVerification: "preferred"}
+	pk.Attestation = "none"
+	return opts, nil
+}
+
+// FinishRegistration menyimpan credential baru. Kalau user belum punya MFA,
+// security key ini langsung jadi faktor kedua default.
+func (s *webauthnService) FinishRegistration(ctx context.Context, p domain.Principal, in dto.WebAuthnRegistrationRequest) (*dto.WebAuthnCredentialResponse, error) {
+	ch, err := s.consume(ctx, in.ChallengeID, domain.WebAuthnRegister)
+	if err != nil {
+		return nil, err
+	}
+	if ch.UserID == nil || *ch.UserID != p.UserID {
+		return nil, ErrInvalidWebAuthnResponse
+	}
+	clientData, err1 := decodeB64URL(in.ClientDataJSON)
+	attObj, err2 := decodeB64URL(in.AttestationObject)
+	if err1 != nil || err2 != nil {
+		return nil, ErrInvalidWebAuthnResponse
+	}
+	att, err := s.verifier.VerifyRegistration(ch.Challenge, clientData, attObj, false)
+	if err != nil {
+		log.Printf("[webauthn] registration rejected for %s: %v", p.UserID, err)
+		return nil, ErrInvalidWebAuthnResponse
+	}
+
+	exist, err := s.creds.GetByCredentialID(ctx, att.CredentialID)
+	if err != nil {
+		return nil, err
+	}
+	if exist != nil {
+		return nil, ErrWebAuthnCredentialExists
+	}
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrUserNotFound
+	}
+
+	now := s.clock.Now()
+	name := strings.TrimSpace(in.Name)
+	if r := []rune(name); len(r) > webauthnNameMax {
+		name = string(r[:webauthnNameMax])
+	}
+	c := domain.WebAuthnCredential{
+		CredentialID: att.CredentialID,
+		UserID:       u.UserID,
+		Name:         def(name, "Passkey"),
+		PublicKey:    att.PublicKey,
+		SignCount:    att.SignCount,
+		AAGUID:       att.AAGUID,
+		Transports:   in.Transports,
+		CreatedAt:    now,
+	}
+	if err := s.creds.Create(ctx, c); err != nil {
+		return nil, err
+	}
+
+	if !u.MFAEnrolled {
+		u.EnableMFA(domain.MFAWebAuthn)
+		u.UpdatedAt = now
+		u.UpdatedBy = &u.UserID
+		if _, err := s.users.Update(ctx, *u); err != nil {
+			return nil, err
+		}
+	}
+	resp := toCredentialResponse(c)
+	return &resp, nil
+}
+
+func (s *webauthnService) ListCredentials(ctx context.Context, p domain.Principal) ([]dto.WebAuthnCredentialResponse, error) {
+	list, err := s.creds.ListByUser(ctx, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.WebAuthnCredentialResponse, 0, len(list))
+	for _, c := range list {
+		out = append(out, toCredentialResponse(c))
+	}
+	return out, nil
+}
+
+// DeleteCredential: kalau credential terakhir dihapus dan WebAuthn adalah
+// default, faktor lain yang masih aktif (TOTP, lalu email/SMS OTP) jadi
+// default; MFA baru dinonaktifkan kalau tidak ada faktor tersisa.
+func (s *webauthnService) DeleteCredential(ctx context.Context, p domain.Principal, credentialID string) error {
+	id, err := decodeB64URL(credentialID)
+	if err != nil {
+		return ErrWebAuthnCredentialNotFound
+	}
+	ok, err := s.creds.Delete(ctx, p.UserID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrWebAuthnCredentialNotFound
+	}
+
+	left, err := s.HasCredentials(ctx, p.UserID)
+	if err != nil || left {
+		return err
+	}
+	u, err := s.users.GetByID(ctx, p.UserID)
+	if err != nil || u == nil {
+		return err
+	}
+	if u.MFADefaultMethod == nil || *u.MFADefaultMethod != domain.MFAWebAuthn {
+		return nil
+	}
+	next, err := s.fallbackMethod(ctx, *u)
+	if err != nil {
+		return err
+	}
+	if next != "" {
+		u.EnableMFA(next)
+	} else {
+		u.DisableMFA()
+	}
+	u.UpdatedAt = s.clock.Now()
+	u.UpdatedBy = &u.UserID
+	_, err = s.users.Update(ctx, *u)
+	return err
+}
+
+// fallbackMethod: faktor kedua lain yang masih terpasang; "" kalau tidak ada.
+func (s *webauthnService) fallbackMethod(ctx context.Context, u domain.User) (domain.MFAMethod, error) {
+	if s.totp != nil {
+		cred, err := s.totp.Get(ctx, u.UserID)
+		if err != nil {
+			return "", err
+		}
+		if cred != nil && cred.IsConfirmed() {
+			return domain.MFATOTP, nil
+		}
+	}
+	if s.otp != nil {
+		list, err := s.otp.EnabledMethods(ctx, u)
+		if err != nil {
+			return "", err
+		}
+		if len(list) > 0 {
+			return list[0], nil
+		}
+	}
+	return "", nil
+}
+
+// BeginLogin: tanpa allowCredentials, browser menawarkan passkey yang tersimpan untuk RP ini.
+func (s *webauthnService) BeginLogin(ctx context.Context) (*dto.WebAuthnRequestOptions, error) {
+	ch, err := s.newChallenge(ctx, nil, domain.WebAuthnLogin)
+	if err != nil {
+		return nil, err
+	}
+	return s.requestOptions(ch, nil, "required"), nil
+}
+
+// FinishLogin: passkey dengan user verification sudah memenuhi dua faktor,
+// jadi tidak ada langkah MFA tambahan.
+func (s *webauthnService) FinishLogin(ctx context.Context, in dto.WebAuthnAssertion) (*dto.LoginResponse, error) {
+	c, err := s.assert(ctx, nil, domain.WebAuthnLogin, in, true)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.GetByID(ctx, c.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrInvalidWebAuthnResponse
+	}
+	if err := checkAccountStatus(*u); err != nil {
+		return nil, err
+	}
+	if u.MustChangePassword {
+		return s.sessions.IssueRestricted(ctx, *u)
+	}
+	return s.sessions.Issue(ctx, *u)
+}
+
+func (s *webauthnService) HasCredentials(ctx context.Context, userID uuid.UUID) (bool, error) {
+	list, err := s.creds.ListByUser(ctx, userID)
+	return len(list) > 0, err
+}
+
+func (s *webauthnService) BeginMFA(ctx context.Context, userID uuid.UUID) (*dto.WebAuthnRequestOptions, error) {
+	list, err := s.creds.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrWebAuthnCredentialNotFound
+	}
+	ch, err := s.newChallenge(ctx, &userID, domain.WebAuthnMFA)
+	if err != nil {
+		return nil, err
+	}
+	return s.requestOptions(ch, list, "discouraged"), nil
+}
+
+// VerifyMFA: false kalau assertion tidak valid (dihitung sebagai kode salah oleh MFAService).
+func (s *webauthnService) VerifyMFA(ctx context.Context, userID uuid.UUID, in dto.WebAuthnAssertion) (bool, error) {
+	_, err := s.assert(ctx, &userID, domain.WebAuthnMFA, in, false)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrInvalidWebAuthnResponse), errors.Is(err, ErrWebAuthnCloned):
+		return false, nil
+	}
+	return false, err
+}
+
+// assert memverifikasi assertion, melakukan clone check lewat sign counter,
+// lalu menyimpan counter baru. userID nil = login passkey (user dari credential).
+func (s *webauthnService) assert(ctx context.Context, userID *uuid.UUID, purpose domain.WebAuthnPurpose, in dto.WebAuthnAssertion, requireUV bool) (*domain.WebAuthnCredential, error) {
+	ch, err := s.consume(ctx, in.ChallengeID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if userID != nil && (ch.UserID == nil || *ch.UserID != *userID) {
+		return nil, ErrInvalidWebAuthnResponse
+	}
+
+	credID, err1 := decodeB64URL(in.CredentialID)
+	clientData, err2 := decodeB64URL(in.ClientDataJSON)
+	authData, err3 := decodeB64URL(in.AuthenticatorData)
+	sig, err4 := decodeB64URL(in.Signature)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return nil, ErrInvalidWebAuthnResponse
+	}
+	c, err := s.creds.GetByCredentialID(ctx, credID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || (userID != nil && c.UserID != *userID) {
+		return nil, ErrInvalidWebAuthnResponse
+	}
+	if in.UserHandle != "" {
+		handle, err := decodeB64URL(in.UserHandle)
+		if err != nil || !bytes.Equal(handle, c.UserID[:]) {
+			return nil, ErrInvalidWebAuthnResponse
+		}
+	}
+
+	count, err := s.verifier.VerifyAssertion(ch.Challenge, clientData, authData, sig, c.PublicKey, requireUV)
+	if err != nil {
+		log.Printf("[webauthn] assertion rejected for %s: %v", c.UserID, err)
+		return nil, ErrInvalidWebAuthnResponse
+	}
+	if !c.SignCountOK(count) {
+		log.Printf("[webauthn] sign counter did not increase for user %s (stored %d, got %d): possible cloned authenticator",
+			c.UserID, c.SignCount, count)
+		return nil, ErrWebAuthnCloned
+	}
+	if err := s.creds.UpdateSignCount(ctx, c.CredentialID, count, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	c.SignCount = count
+	return c, nil
+}
+
+func (s *webauthnService) newChallenge(ctx context.Context, userID *uuid.UUID, purpose domain.WebAuthnPurpose) (*domain.WebAuthnChallenge, error) {
+	raw, err := s.verifier.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	ch := domain.WebAuthnChallenge{
+		ChallengeID: s.idgen.New(),
+		UserID:      userID,
+		Purpose:     purpose,
+		Challenge:   raw,
+		ExpiresAt:   now.Add(webauthnTimeout),
+		CreatedAt:   now,
+	}
+	if err := s.challenges.Create(ctx, ch); err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+func (s *webauthnService) consume(ctx context.Context, challengeID string, purpose domain.WebAuthnPurpose) (*domain.WebAuthnChallenge, error) {
+	id, err := uuid.Parse(challengeID)
+	if err != nil {
+		return nil, ErrInvalidWebAuthnResponse
+	}
+	ch, err := s.challenges.Consume(ctx, id, purpose, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if ch == nil {
+		return nil, ErrInvalidWebAuthnResponse
+	}
+	return ch, nil
+}
+
+func (s *webauthnService) requestOptions(ch *domain.WebAuthnChallenge, allow []domain.WebAuthnCredential, uv string) *dto.WebAuthnRequestOptions {
+	opts := &dto.WebAuthnRequestOptions{ChallengeID: ch.ChallengeID.String()}
+	opts.PublicKey.Challenge = b64url(ch.Challenge)
+	opts.PublicKey.RPID = s.verifier.RPID()
+	opts.PublicKey.Timeout = webauthnTimeout.Milliseconds()
+	opts.PublicKey.AllowCredentials = descriptors(allow)
+	opts.PublicKey.UserVerification = uv
+	return opts
+}
+
+func descriptors(list []domain.WebAuthnCredential) []dto.WebAuthnCredentialDescriptor {
+	out := make([]dto.WebAuthnCredentialDescriptor, 0, len(list))
+	for _, c := range list {
+		out = append(out, dto.WebAuthnCredentialDescriptor{Type: "public-key", ID: b64url(c.CredentialID), Transports: c.Transports})
+	}
+	return out
+}
+
+func toCredentialResponse(c domain.WebAuthnCredential) dto.WebAuthnCredentialResponse {
+	return dto.WebAuthnCredentialResponse{
+		ID:         b64url(c.CredentialID),
+		Name:       c.Name,
+		Transports: c.Transports,
+		CreatedAt:  c.CreatedAt,
+		LastUsedAt: c.LastUsedAt,
+	}
+}
+
+func b64url(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+// decodeB64URL menerima base64url dengan atau tanpa padding
+func decodeB64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(s), "="))
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
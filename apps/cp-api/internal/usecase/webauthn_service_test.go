@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"xeed/apps/cp-api/internal/domain"
+	"xeed/apps/cp-api/internal/usecase/contract"
+
+	"github.com/google/uuid"
+)
+
+// credStub: hanya ListByUser & Delete yang dipakai DeleteCredential.
+type credStub struct {
+	contract.WebAuthnCredentialRepository
+	creds []domain.WebAuthnCredential
+}
+
+func (s *credStub) ListByUser(_ context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	var out []domain.WebAuthnCredential
+	for _, c := range s.creds {
+		if c.UserID == userID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (s *credStub) Delete(_ context.Context, userID uuid.UUID, id []byte) (bool, error) {
+	for i, c := range s.creds {
+		if c.UserID == userID && bytes.Equal(c.CredentialID, id) {
+			s.creds = append(s.creds[:i], s.creds[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Menghapus passkey terakhir tidak boleh mematikan faktor kedua lain yang masih terpasang.
+func TestDeleteLastPasskeyFallsBack(t *testing.T) {
+	ctx := context.Background()
+	credID := []byte("passkey-1")
+
+	cases := []struct {
+		name  string
+		setup func(e *mfaEnv, o *otpEnv, u domain.User)
+		want  *domain.MFAMethod
+	}{
+		{"totp", func(e *mfaEnv, _ *otpEnv, u domain.User) {
+			now := e.clock.Now()
+			e.totpRepo.creds[u.UserID] = domain.TOTPCredential{UserID: u.UserID, ConfirmedAt: &now}
+		}, ptr(domain.MFATOTP)},
+		{"unconfirmed totp, sms", func(e *mfaEnv, o *otpEnv, u domain.User) {
+			e.totpRepo.creds[u.UserID] = domain.TOTPCredential{UserID: u.UserID}
+			o.methods.methods[u.UserID] = []domain.MFAMethod{domain.MFASMS}
+		}, ptr(domain.MFASMS)},
+		{"nothing left", func(*mfaEnv, *otpEnv, domain.User) {}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newMFAEnv(t, nil)
+			o := e.otpEnv(t)
+			u := e.seedUser(t, "budi@xeed.test", testPassword, withVerifiedPhone, func(u *domain.User) { u.EnableMFA(domain.MFAWebAuthn) })
+			tc.setup(e, o, u)
+			creds := &credStub{creds: []domain.WebAuthnCredential{{CredentialID: credID, UserID: u.UserID}}}
+			svc := NewWebAuthnService(e.users, creds, nopWebAuthnChallenges{}, nopWebAuthnVerifier{}, e.sessions, e.clock, e.ids, "",
+				e.totpRepo, o.svc)
+
+			if err := svc.DeleteCredential(ctx, domain.Principal{UserID: u.UserID}, base64.RawURLEncoding.EncodeToString(credID)); err != nil {
+				t.Fatalf("DeleteCredential: %v", err)
+			}
+			got := e.mustGet(t, u.UserID)
+			switch {
+			case tc.want == nil && (got.MFAEnrolled || got.MFADefaultMethod != nil):
+				t.Errorf("mfa = %v/%v; want disabled", got.MFAEnrolled, got.MFADefaultMethod)
+			case tc.want != nil && (!got.MFAEnrolled || got.MFADefaultMethod == nil || *got.MFADefaultMethod != *tc.want):
+				t.Errorf("mfa = %v/%v; want default %s", got.MFAEnrolled, got.MFADefaultMethod, *tc.want)
+			}
+		})
+	}
+}
+
+type nopWebAuthnChallenges struct {
+	contract.WebAuthnChallengeRepository
+}
+
+type nopWebAuthnVerifier struct {
+	contract.WebAuthnVerifier
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
+	_ "time/tzdata" // validasi timezone IANA tidak bergantung pada host
 	"xeed/apps/cp-api/internal/app"
 
 	"github.com/joho/godotenv"
@@ -9,7 +12,25 @@ import (
 
 func main() {
 	_ = godotenv.Load()
-	if err := app.Run(); err != nil {
+
+	// tanpa argumen = jalankan server (perilaku lama)
+	cmd := "serve"
+	if len(os.Args) > 1 {
+		cmd = os.Args[1]
+	}
+
+	var err error
+	switch cmd {
+	case "serve":
+		err = app.Run()
+	case "migrate":
+		err = app.Migrate(os.Args[2:])
+	case "user":
+		err = app.User(os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown command %q (serve | migrate | user)", cmd)
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }